@@ -0,0 +1,79 @@
+package client
+
+import (
+	"strings"
+	"time"
+)
+
+// Cache stores API responses by key. Get returns data stored less than ttl
+// ago.
+type Cache interface {
+	Get(key string, ttl time.Duration) ([]byte, bool)
+	Set(key string, ttl time.Duration, data []byte) error
+	Delete(key string) error
+}
+
+// CacheTTLs are the cacheable list responses, by cache key. The key is the
+// endpoint without the leading slash; filtered lists are never cached.
+var CacheTTLs = map[string]time.Duration{
+	"instances": 1 * time.Minute, // Instances change frequently
+	"vpcs":      1 * time.Minute, // VPCs change frequently
+	"plans":     1 * time.Hour,   // Plans rarely change
+	"regions":   1 * time.Hour,   // Regions rarely change
+}
+
+// invalidatedBy lists the cache keys that a mutating request to an endpoint
+// under the prefix makes stale. Creating an instance can create a VPC, and
+// deleting a VPC removes its instances, so both lists go together.
+var invalidatedBy = map[string][]string{
+	"/instances": {"instances", "vpcs"},
+	"/vpcs":      {"instances", "vpcs"},
+}
+
+// SetCache sets the response cache. Mutating requests invalidate the affected
+// entries whether or not the call was made through Cached.
+func (c *Client) SetCache(cache Cache) {
+	c.cache = cache
+}
+
+// DisableCacheReads makes Cached return c, so that every call hits the API.
+// Mutating requests still invalidate the cache, keeping it correct for later
+// runs that do read it.
+func (c *Client) DisableCacheReads() {
+	c.cacheReadsDisabled = true
+}
+
+// Cached returns a client that serves the list calls in CacheTTLs from the
+// cache when fresh, and stores their responses otherwise. Without a cache, or
+// with cache reads disabled, it returns c.
+func (c *Client) Cached() *Client {
+	if c.cache == nil || c.cacheReadsDisabled {
+		return c
+	}
+	cached := *c
+	cached.useCache = true
+	return &cached
+}
+
+// cacheKey returns the cache key and TTL of a GET endpoint
+func cacheKey(endpoint string) (string, time.Duration, bool) {
+	key := strings.TrimPrefix(endpoint, "/")
+	ttl, ok := CacheTTLs[key]
+	return key, ttl, ok
+}
+
+// invalidate deletes the cache entries made stale by a mutating request to
+// endpoint
+func (c *Client) invalidate(endpoint string) {
+	if c.cache == nil {
+		return
+	}
+	for prefix, keys := range invalidatedBy {
+		if endpoint != prefix && !strings.HasPrefix(endpoint, prefix+"/") {
+			continue
+		}
+		for _, key := range keys {
+			c.cache.Delete(key)
+		}
+	}
+}
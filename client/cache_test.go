@@ -0,0 +1,150 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryCache map[string][]byte
+
+func (m memoryCache) Get(key string, ttl time.Duration) ([]byte, bool) {
+	data, ok := m[key]
+	return data, ok
+}
+
+func (m memoryCache) Set(key string, ttl time.Duration, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+func (m memoryCache) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func newCacheTestServer(t *testing.T, requests *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/instances":
+			w.Write([]byte(`[{"id":1,"name":"first"}]`))
+		case r.Method == "GET" && r.URL.Path == "/plans":
+			w.Write([]byte(`[{"name":"bunny-1"}]`))
+		case r.Method == "POST" && r.URL.Path == "/instances":
+			w.Write([]byte(`{"id":2}`))
+		case r.Method == "DELETE" && r.URL.Path == "/vpcs/5":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCached_ServesListsFromCache(t *testing.T) {
+	var requests int
+	server := newCacheTestServer(t, &requests)
+	cache := memoryCache{}
+	client := NewWithBaseURL("test-api-key", server.URL, "test")
+	client.SetCache(cache)
+
+	instances, err := client.Cached().ListInstances()
+	require.NoError(t, err)
+	assert.Len(t, instances, 1)
+	assert.Contains(t, cache, "instances")
+
+	instances, err = client.Cached().ListInstances()
+	require.NoError(t, err)
+	assert.Len(t, instances, 1)
+	assert.Equal(t, 1, requests)
+
+	// Calls not made through Cached always hit the API
+	_, err = client.ListInstances()
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func TestCached_FilteredListsNotCached(t *testing.T) {
+	var requests int
+	server := newCacheTestServer(t, &requests)
+	cache := memoryCache{}
+	client := NewWithBaseURL("test-api-key", server.URL, "test")
+	client.SetCache(cache)
+
+	_, err := client.Cached().ListPlans("rabbitmq")
+	require.NoError(t, err)
+	assert.Empty(t, cache)
+}
+
+func TestCached_WithoutCache(t *testing.T) {
+	client := NewWithBaseURL("test-api-key", "http://localhost", "test")
+	assert.Same(t, client, client.Cached())
+}
+
+func TestMutationsInvalidateCache(t *testing.T) {
+	var requests int
+	server := newCacheTestServer(t, &requests)
+	cache := memoryCache{}
+	client := NewWithBaseURL("test-api-key", server.URL, "test")
+	client.SetCache(cache)
+
+	fill := func() {
+		cache["instances"] = []byte(`[]`)
+		cache["vpcs"] = []byte(`[]`)
+		cache["plans"] = []byte(`[]`)
+	}
+
+	fill()
+	_, err := client.CreateInstance(&InstanceCreateRequest{Name: "second", Plan: "bunny-1", Region: "amazon-web-services::us-east-1"})
+	require.NoError(t, err)
+	assert.NotContains(t, cache, "instances")
+	assert.NotContains(t, cache, "vpcs")
+	assert.Contains(t, cache, "plans")
+
+	fill()
+	require.NoError(t, client.DeleteVPC(5))
+	assert.NotContains(t, cache, "instances")
+	assert.NotContains(t, cache, "vpcs")
+
+	// Failed requests may still have been applied
+	fill()
+	assert.Error(t, client.DeleteInstance(9))
+	assert.NotContains(t, cache, "instances")
+}
+
+func TestDisableCacheReads(t *testing.T) {
+	var requests int
+	server := newCacheTestServer(t, &requests)
+	cache := memoryCache{"instances": []byte(`[]`), "vpcs": []byte(`[]`)}
+	client := NewWithBaseURL("test-api-key", server.URL, "test")
+	client.SetCache(cache)
+	client.DisableCacheReads()
+
+	assert.Same(t, client, client.Cached())
+	instances, err := client.Cached().ListInstances()
+	require.NoError(t, err)
+	assert.Len(t, instances, 1)
+	assert.Equal(t, `[]`, string(cache["instances"]))
+
+	// Writes still invalidate the cache read by later clients
+	require.NoError(t, client.DeleteVPC(5))
+	assert.NotContains(t, cache, "instances")
+	assert.NotContains(t, cache, "vpcs")
+}
+
+func TestMutationsInDryRunKeepCache(t *testing.T) {
+	cache := memoryCache{"instances": []byte(`[]`)}
+	client := NewWithBaseURL("test-api-key", "http://localhost", "test")
+	client.SetCache(cache)
+	client.SetDryRun(io.Discard)
+
+	assert.ErrorIs(t, client.DeleteInstance(1), ErrDryRun)
+	assert.Contains(t, cache, "instances")
+}
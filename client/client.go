@@ -3,21 +3,35 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"strings"
 )
 
 var BaseURL = "https://customer.cloudamqp.com/api"
 
+// ErrDryRun is returned for mutating requests when dry-run mode is enabled
+var ErrDryRun = errors.New("dry run: request not sent")
+
 type Client struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
 	version    string
+	dryRun     io.Writer
+	cache      Cache
+	useCache   bool
+
+	cacheReadsDisabled bool
+}
+
+// SetDryRun enables dry-run mode. Mutating requests are written to w instead
+// of being sent and return ErrDryRun; GET requests are still sent.
+func (c *Client) SetDryRun(w io.Writer) {
+	c.dryRun = w
 }
 
 func New(apiKey, version string) *Client {
@@ -53,23 +67,67 @@ func NewWithHTTPClient(apiKey, baseURL, version string, httpClient *http.Client)
 	}
 }
 
+// APIError is returned when the API responds with an error status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an API error with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
 func (c *Client) makeRequest(method, endpoint string, body any) ([]byte, error) {
+	if method == "GET" && c.useCache {
+		if key, ttl, ok := cacheKey(endpoint); ok {
+			if data, ok := c.cache.Get(key, ttl); ok {
+				return data, nil
+			}
+			respBody, err := c.sendRequest(method, endpoint, body)
+			if err != nil {
+				return nil, err
+			}
+			c.cache.Set(key, ttl, respBody)
+			return respBody, nil
+		}
+	}
+	return c.sendRequest(method, endpoint, body)
+}
+
+func (c *Client) sendRequest(method, endpoint string, body any) ([]byte, error) {
 	var reqBody io.Reader
+	var bodyData []byte
 	var contentType string
 
 	if body != nil {
 		switch v := body.(type) {
 		case url.Values:
 			contentType = "application/x-www-form-urlencoded"
-			reqBody = strings.NewReader(v.Encode())
+			bodyData = []byte(v.Encode())
 		default:
 			contentType = "application/json"
 			jsonData, err := json.Marshal(body)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal request body: %w", err)
 			}
-			reqBody = bytes.NewReader(jsonData)
+			bodyData = jsonData
 		}
+		reqBody = bytes.NewReader(bodyData)
+	}
+
+	if c.dryRun != nil && method != "GET" {
+		writeDryRun(c.dryRun, method, c.baseURL+endpoint, contentType, bodyData)
+		return nil, ErrDryRun
+	}
+	if method != "GET" {
+		// Invalidate even when the request fails, it may have been applied
+		defer c.invalidate(endpoint)
 	}
 
 	req, err := http.NewRequest(method, c.baseURL+endpoint, reqBody)
@@ -99,14 +157,22 @@ func (c *Client) makeRequest(method, endpoint string, body any) ([]byte, error)
 			Error string `json:"error"`
 		}
 		if err := json.Unmarshal(respBody, &errorResp); err == nil && errorResp.Error != "" {
-			return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, errorResp.Error)
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: errorResp.Error}
 		}
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
 	}
 
 	return respBody, nil
 }
 
+// writeDryRun prints a request the way it would have been sent
+func writeDryRun(w io.Writer, method, url, contentType string, body []byte) {
+	fmt.Fprintf(w, "DRY RUN: %s %s\n", method, url)
+	if contentType != "" {
+		fmt.Fprintf(w, "Content-Type: %s\n\n%s\n", contentType, body)
+	}
+}
+
 // Instance-specific operations using /instances/{id}/ endpoints
 
 // Node management
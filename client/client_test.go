@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -168,6 +169,27 @@ func TestMakeRequest_APIError_Plain(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "API error (401): Not authorized")
+	assert.False(t, IsNotFound(err))
+}
+
+func TestMakeRequest_APIError_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Not found"}`))
+	}))
+	defer server.Close()
+
+	client := NewWithBaseURL("test-api-key", server.URL, "test")
+
+	_, err := client.makeRequest("GET", "/test", nil)
+
+	assert.Error(t, err)
+	assert.True(t, IsNotFound(err))
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "Not found", apiErr.Message)
 }
 
 func TestMakeRequest_NetworkError(t *testing.T) {
@@ -192,3 +214,43 @@ func TestMakeRequest_InvalidJSON(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to marshal request body")
 }
+
+func TestMakeRequest_DryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client := NewWithBaseURL("test-api-key", server.URL, "test")
+	client.SetDryRun(&out)
+
+	// GET requests are still sent
+	_, err := client.ListInstances()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// Form encoded body
+	err = client.UpdateInstance(1234, &InstanceUpdateRequest{Name: "new-name"})
+	assert.ErrorIs(t, err, ErrDryRun)
+	assert.Contains(t, out.String(), "DRY RUN: PUT "+server.URL+"/instances/1234\n")
+	assert.Contains(t, out.String(), "Content-Type: application/x-www-form-urlencoded\n\nname=new-name\n")
+
+	// JSON body
+	out.Reset()
+	err = client.RestartRabbitMQ("1234", []string{"node-01"})
+	assert.ErrorIs(t, err, ErrDryRun)
+	assert.Contains(t, out.String(), "DRY RUN: POST "+server.URL+"/instances/1234/actions/restart\n")
+	assert.Contains(t, out.String(), "Content-Type: application/json\n\n{\"nodes\":[\"node-01\"]}\n")
+
+	// No body
+	out.Reset()
+	err = client.DeleteInstance(1234)
+	assert.ErrorIs(t, err, ErrDryRun)
+	assert.Equal(t, "DRY RUN: DELETE "+server.URL+"/instances/1234\n", out.String())
+
+	assert.Equal(t, 1, requests, "mutating requests must not be sent")
+}
@@ -74,6 +74,7 @@ func TestGetInstanceVCR(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, instance)
 	assert.Equal(t, instanceID, instance.ID)
+	assert.Equal(t, "rabbitmq", instance.Backend)
 	t.Logf("✓ Got instance %d: %s (plan: %s)", instance.ID, instance.Name, instance.Plan)
 }
 
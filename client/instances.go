@@ -12,6 +12,7 @@ type Instance struct {
 	Region           string   `json:"region"`
 	Name             string   `json:"name"`
 	Tags             []string `json:"tags"`
+	Backend          string   `json:"backend"`
 	ProviderID       string   `json:"providerid"`
 	VPCID            *int     `json:"vpc_id"`
 	URL              string   `json:"url"`
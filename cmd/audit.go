@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
@@ -12,7 +11,9 @@ var auditTimestamp string
 var auditCmd = &cobra.Command{
 	Use:   "audit",
 	Short: "Get audit log in CSV format",
-	Long:  `Returns audit log in CSV format for latest month or for month specified in params.`,
+	Long: `Returns audit log in CSV format for latest month or for month specified in params.
+
+Use "audit query" to filter the audit log of one or more months.`,
 	Example: `  cloudamqp audit
   cloudamqp audit --timestamp=2022-12`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -22,12 +23,11 @@ var auditCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		csv, err := c.GetAuditLogCSV(auditTimestamp)
 		if err != nil {
-			fmt.Printf("Error getting audit log: %v\n", err)
-			return err
+			return commandError("getting audit log", err)
 		}
 
 		fmt.Print(csv)
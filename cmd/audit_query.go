@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/audit"
+	"cloudamqp-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var auditFormats = []string{"table", "json", "csv"}
+
+var auditQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Search the audit log",
+	Long: `Fetches the audit log for every month between --since and --until, and prints
+the events matching the filters.
+
+--since and --until take a month (2024-01), a date (2024-01-15) or an RFC 3339
+time. Months and dates given to --until are inclusive. The range defaults to
+the current month.
+
+The user, action, resource type and resource ID filters are exact matches,
+ignoring case. With --group-by the number of matching events per user,
+action, resource type or resource ID is printed instead of the events.`,
+	Example: `  cloudamqp audit query --since=2024-01 --until=2024-03
+  cloudamqp audit query --since=2024-01 --until=2024-03 --group-by=user
+  cloudamqp audit query --user=alice@example.com --format=json
+  cloudamqp audit query --resource-type=instance --resource-id=1234 --format=csv > audit.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		untilFlag, _ := cmd.Flags().GetString("until")
+		format, _ := cmd.Flags().GetString("format")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+
+		filter := audit.Filter{}
+		filter.User, _ = cmd.Flags().GetString("user")
+		filter.Action, _ = cmd.Flags().GetString("action")
+		filter.ResourceType, _ = cmd.Flags().GetString("resource-type")
+		filter.ResourceID, _ = cmd.Flags().GetString("resource-id")
+
+		if !containsString(auditFormats, format) {
+			return fmt.Errorf("invalid format %q, must be one of: %s", format, strings.Join(auditFormats, ", "))
+		}
+
+		now := time.Now().UTC()
+		var err error
+		filter.Since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if sinceFlag != "" {
+			if filter.Since, err = parseAuditTime(sinceFlag, false); err != nil {
+				return fmt.Errorf("invalid since value: %v", err)
+			}
+		}
+		filter.Until = now
+		if untilFlag != "" {
+			if filter.Until, err = parseAuditTime(untilFlag, true); err != nil {
+				return fmt.Errorf("invalid until value: %v", err)
+			}
+		}
+		if !filter.Since.Before(filter.Until) {
+			return fmt.Errorf("--since must be before --until")
+		}
+
+		apiKey, err = getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		events, err := fetchAuditEvents(c, audit.Months(filter.Since, filter.Until))
+		if err != nil {
+			return commandError("getting audit log", err)
+		}
+		events = filter.Apply(events)
+
+		if groupBy != "" {
+			groups, err := audit.GroupBy(events, groupBy)
+			if err != nil {
+				return err
+			}
+			return printAuditGroups(groups, groupBy, format)
+		}
+		return printAuditEvents(events, format)
+	},
+}
+
+// fetchAuditEvents fetches and parses the audit log of each month
+func fetchAuditEvents(c *client.Client, months []string) ([]audit.Event, error) {
+	var events []audit.Event
+	for _, month := range months {
+		data, err := c.GetAuditLogCSV(month)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", month, err)
+		}
+		monthEvents, err := audit.Parse(strings.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", month, err)
+		}
+		events = append(events, monthEvents...)
+	}
+	return events, nil
+}
+
+// parseAuditTime parses a month, date or RFC 3339 time. Months and dates are
+// rounded up to the start of the next month or day when end is set, so that
+// they include the whole period.
+func parseAuditTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse("2006-01", value); err == nil {
+		if end {
+			t = t.AddDate(0, 1, 0)
+		}
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a month (YYYY-MM), date (YYYY-MM-DD) or RFC 3339 time", value)
+	}
+	return t.UTC(), nil
+}
+
+func printAuditEvents(events []audit.Event, format string) error {
+	switch format {
+	case "json":
+		if events == nil {
+			events = []audit.Event{}
+		}
+		output, err := json.MarshalIndent(events, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format audit log: %w", err)
+		}
+		fmt.Println(string(output))
+	case "csv":
+		return audit.WriteCSV(os.Stdout, events)
+	default:
+		if len(events) == 0 {
+			fmt.Println("No audit events found.")
+			return nil
+		}
+		t := table.New(os.Stdout, "TIME", "USER", "ACTION", "RESOURCE_TYPE", "RESOURCE_ID", "DETAILS")
+		for _, e := range events {
+			t.AddRow(e.Time.Format(time.RFC3339), e.User, e.Action, e.ResourceType, e.ResourceID, e.Details)
+		}
+		t.Print()
+	}
+	return nil
+}
+
+func printAuditGroups(groups []audit.Group, field, format string) error {
+	switch format {
+	case "json":
+		if groups == nil {
+			groups = []audit.Group{}
+		}
+		output, err := json.MarshalIndent(groups, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format audit log: %w", err)
+		}
+		fmt.Println(string(output))
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{field, "count", "first", "last"})
+		for _, g := range groups {
+			writer.Write([]string{g.Key, strconv.Itoa(g.Count), g.First.Format(time.RFC3339), g.Last.Format(time.RFC3339)})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		if len(groups) == 0 {
+			fmt.Println("No audit events found.")
+			return nil
+		}
+		t := table.New(os.Stdout, strings.ToUpper(field), "COUNT", "FIRST", "LAST")
+		for _, g := range groups {
+			t.AddRow(g.Key, strconv.Itoa(g.Count), g.First.Format(time.RFC3339), g.Last.Format(time.RFC3339))
+		}
+		t.Print()
+	}
+	return nil
+}
+
+func init() {
+	auditQueryCmd.Flags().String("user", "", "Only events by this user")
+	auditQueryCmd.Flags().String("action", "", "Only events with this action")
+	auditQueryCmd.Flags().String("resource-type", "", "Only events on this resource type")
+	auditQueryCmd.Flags().String("resource-id", "", "Only events on this resource ID")
+	auditQueryCmd.Flags().String("since", "", "Start of the range: YYYY-MM, YYYY-MM-DD or RFC 3339 (default: start of this month)")
+	auditQueryCmd.Flags().String("until", "", "End of the range, inclusive for months and dates (default: now)")
+	auditQueryCmd.Flags().String("format", "table", "Output format: table, json or csv")
+	auditQueryCmd.Flags().String("group-by", "", "Count events per user, action, resource_type or resource_id")
+	auditQueryCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return auditFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	auditQueryCmd.RegisterFlagCompletionFunc("group-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return audit.GroupFields, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	auditCmd.AddCommand(auditQueryCmd)
+}
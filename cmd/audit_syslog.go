@@ -0,0 +1,41 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+)
+
+// syslogForwarder writes each event to syslog
+type syslogForwarder struct {
+	writer *syslog.Writer
+}
+
+// newSyslogForwarder connects to the local syslog, or to address given as
+// network://host:port
+func newSyslogForwarder(address, tag string) (auditForwarder, error) {
+	network, raddr := "", ""
+	if address != "" {
+		u, err := url.Parse(address)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog address %q, expected e.g. udp://host:514", address)
+		}
+		network, raddr = u.Scheme, u.Host
+	}
+
+	writer, err := syslog.Dial(network, raddr, syslog.LOG_NOTICE|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogForwarder{writer: writer}, nil
+}
+
+func (s *syslogForwarder) Forward(line []byte) error {
+	return s.writer.Notice(string(line))
+}
+
+func (s *syslogForwarder) Close() error {
+	return s.writer.Close()
+}
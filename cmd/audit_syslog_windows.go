@@ -0,0 +1,7 @@
+package cmd
+
+import "fmt"
+
+func newSyslogForwarder(address, tag string) (auditForwarder, error) {
+	return nil, fmt.Errorf("syslog is not supported on Windows")
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+// auditForwarder sends audit events, as JSON lines, somewhere besides stdout
+type auditForwarder interface {
+	Forward(line []byte) error
+	Close() error
+}
+
+var auditTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Print new audit log events as JSON lines",
+	Long: `Prints audit log events that have not been printed before as JSON lines.
+
+A cursor of the events already seen is kept per API key in the cache
+directory ($XDG_CACHE_HOME/cloudamqp or ~/.cache/cloudamqp). The first run,
+without a cursor, prints the last --lines events of the current month. With --follow the audit log is polled every
+--interval until interrupted.
+
+Events can also be forwarded to a webhook, which receives each event as a JSON
+POST, and to syslog. Each event goes to the webhook, then syslog, then stdout.
+When forwarding fails the cursor is not moved past the failed event, so it is
+sent again on the next poll or run, also to the destinations that already
+received it. Delivery is at least once per destination: receivers should
+tolerate duplicates.`,
+	Example: `  cloudamqp audit tail
+  cloudamqp audit tail --follow --interval=5m
+  cloudamqp audit tail --follow --webhook=https://siem.example.com/ingest
+  cloudamqp audit tail --follow --syslog --syslog-address=udp://logs.example.com:514`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		follow, _ := cmd.Flags().GetBool("follow")
+		intervalFlag, _ := cmd.Flags().GetString("interval")
+		lines, _ := cmd.Flags().GetInt("lines")
+		reset, _ := cmd.Flags().GetBool("reset")
+		webhook, _ := cmd.Flags().GetString("webhook")
+		useSyslog, _ := cmd.Flags().GetBool("syslog")
+		syslogAddress, _ := cmd.Flags().GetString("syslog-address")
+		syslogTag, _ := cmd.Flags().GetString("syslog-tag")
+
+		interval, err := time.ParseDuration(intervalFlag)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %v", err)
+		}
+		if interval < time.Second {
+			return fmt.Errorf("interval must be at least 1s")
+		}
+		if lines < 0 {
+			return fmt.Errorf("lines must not be negative")
+		}
+
+		apiKey, err = getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		var forwarders []auditForwarder
+		if webhook != "" {
+			forwarders = append(forwarders, &webhookForwarder{url: webhook, client: &http.Client{Timeout: 30 * time.Second}})
+		}
+		if useSyslog || syslogAddress != "" {
+			forwarder, err := newSyslogForwarder(syslogAddress, syslogTag)
+			if err != nil {
+				return fmt.Errorf("failed to connect to syslog: %w", err)
+			}
+			forwarders = append(forwarders, forwarder)
+		}
+		defer func() {
+			for _, f := range forwarders {
+				f.Close()
+			}
+		}()
+
+		cursorPath, err := auditCursorPath(apiKey)
+		if err != nil {
+			return err
+		}
+		if reset {
+			if err := os.Remove(cursorPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to reset audit cursor: %w", err)
+			}
+		}
+
+		tail := &auditTail{client: c, cursorPath: cursorPath, lines: lines, forwarders: forwarders}
+		if err := tail.poll(); err != nil || !follow {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			// Keep following on errors, the next poll retries
+			if err := tail.poll(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}
+	},
+}
+
+// auditTail prints and forwards audit events not seen before
+type auditTail struct {
+	client     *client.Client
+	cursorPath string
+	lines      int
+	forwarders []auditForwarder
+}
+
+// poll fetches the audit log from the month of the cursor up to now and emits
+// the new events, saving the cursor after every emitted event
+func (t *auditTail) poll() error {
+	cursor, err := audit.LoadCursor(t.cursorPath)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	first := cursor == nil
+	if first {
+		cursor = &audit.Cursor{}
+	} else if cursor.Time.Before(since) {
+		since = cursor.Time
+	}
+
+	events, err := fetchAuditEvents(t.client, audit.Months(since, now))
+	if err != nil {
+		return fmt.Errorf("failed to get audit log: %w", err)
+	}
+	events = cursor.Unseen(events)
+
+	// Without a cursor, only the last lines events are printed
+	if first && len(events) > t.lines {
+		for _, e := range events[:len(events)-t.lines] {
+			cursor.Advance(e)
+		}
+		events = events[len(events)-t.lines:]
+	}
+
+	for _, e := range events {
+		if err := t.emit(e); err != nil {
+			return errors.Join(err, cursor.Save(t.cursorPath))
+		}
+		cursor.Advance(e)
+	}
+	return cursor.Save(t.cursorPath)
+}
+
+func (t *auditTail) emit(e audit.Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	for _, f := range t.forwarders {
+		if err := f.Forward(line); err != nil {
+			return fmt.Errorf("failed to forward audit event: %w", err)
+		}
+	}
+	fmt.Println(string(line))
+	return nil
+}
+
+// auditCursorPath returns the cursor file for the account of apiKey
+func auditCursorPath(apiKey string) (string, error) {
+	cacheDir, err := getCacheDir(apiKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "audit_cursor.json"), nil
+}
+
+// webhookForwarder POSTs each event as JSON to a URL
+type webhookForwarder struct {
+	url    string
+	client *http.Client
+}
+
+func (w *webhookForwarder) Forward(line []byte) error {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(line))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *webhookForwarder) Close() error {
+	return nil
+}
+
+func init() {
+	auditTailCmd.Flags().BoolP("follow", "f", false, "Keep polling for new events")
+	auditTailCmd.Flags().String("interval", "1m", "Delay between polls with --follow")
+	auditTailCmd.Flags().IntP("lines", "n", 10, "Number of existing events to print when there is no cursor yet")
+	auditTailCmd.Flags().Bool("reset", false, "Forget the events already seen")
+	auditTailCmd.Flags().String("webhook", "", "POST each event as JSON to this URL")
+	auditTailCmd.Flags().Bool("syslog", false, "Write each event to the local syslog")
+	auditTailCmd.Flags().String("syslog-address", "", "Write each event to a remote syslog, e.g. udp://logs.example.com:514")
+	auditTailCmd.Flags().String("syslog-tag", "cloudamqp-audit", "Syslog tag")
+
+	auditCmd.AddCommand(auditTailCmd)
+}
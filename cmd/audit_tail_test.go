@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cloudamqp-cli/client"
+)
+
+// failingForwarder fails every event, after running hook
+type failingForwarder struct {
+	hook func()
+}
+
+func (f failingForwarder) Forward(line []byte) error {
+	f.hook()
+	return errors.New("syslog down")
+}
+
+func (f failingForwarder) Close() error { return nil }
+
+func TestWebhookForwarder(t *testing.T) {
+	var received string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	forwarder := &webhookForwarder{url: server.URL, client: server.Client()}
+	if err := forwarder.Forward([]byte(`{"action":"create"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received != `{"action":"create"}` {
+		t.Errorf("unexpected body %q", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := forwarder.Forward([]byte(`{}`)); err == nil {
+		t.Error("expected error for failed delivery")
+	}
+}
+
+func TestAuditTailPollReportsCursorSaveError(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Time,User,Action\n" + now + ",alice@example.com,create\n"))
+	}))
+	defer server.Close()
+
+	// Replacing the cursor directory by a file makes saving the cursor fail
+	cursorDir := filepath.Join(t.TempDir(), "cursor")
+	block := func() {
+		if err := os.WriteFile(cursorDir, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tail := &auditTail{
+		client:     client.NewWithBaseURL("test-api-key", server.URL, "test"),
+		cursorPath: filepath.Join(cursorDir, "audit_cursor.json"),
+		lines:      10,
+		forwarders: []auditForwarder{failingForwarder{hook: block}},
+	}
+	err := tail.poll()
+	if err == nil || !strings.Contains(err.Error(), "syslog down") || !strings.Contains(err.Error(), "failed to save audit cursor") {
+		t.Errorf("expected forwarding and cursor errors, got %v", err)
+	}
+}
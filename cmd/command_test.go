@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/rabbitmq"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
@@ -88,6 +94,18 @@ func TestInstanceResizeCommand_Validation(t *testing.T) {
 	assert.NotNil(t, downtimeFlag)
 }
 
+func TestResizeTargetDiskSize(t *testing.T) {
+	nodes := []client.Node{
+		{Name: "node-01", DiskSize: 20, AdditionalDiskSize: 25},
+		{Name: "node-02", DiskSize: 20, AdditionalDiskSize: 25},
+	}
+	// Nodes that already have additional disk only meet --wait after the resize
+	assert.Equal(t, 95, resizeTargetDiskSize(nodes, 50))
+
+	nodes = append(nodes, client.Node{Name: "node-03", DiskSize: 20})
+	assert.Equal(t, 70, resizeTargetDiskSize(nodes, 50))
+}
+
 func TestVPCCreateCommand_Validation(t *testing.T) {
 	cmd := vpcCreateCmd
 
@@ -222,6 +240,7 @@ func TestInstanceActionsCommand(t *testing.T) {
 
 	expectedActions := []string{
 		"restart-rabbitmq --id <instance_id>",
+		"rolling-restart --id <instance_id>",
 		"restart-cluster --id <instance_id>",
 		"restart-management --id <instance_id>",
 		"stop --id <instance_id>",
@@ -233,6 +252,7 @@ func TestInstanceActionsCommand(t *testing.T) {
 		"upgrade-rabbitmq --id <instance_id>",
 		"upgrade-all --id <instance_id>",
 		"upgrade-versions --id <instance_id>",
+		"upgrade --id <instance_id> --to <version>",
 	}
 
 	for _, action := range expectedActions {
@@ -240,6 +260,140 @@ func TestInstanceActionsCommand(t *testing.T) {
 	}
 }
 
+func TestWaitFlagsOnObservableActions(t *testing.T) {
+	for _, cmd := range []*cobra.Command{restartRabbitMQCmd, restartClusterCmd, rebootCmd, stopCmd} {
+		assert.NotNil(t, cmd.Flags().Lookup("wait"), cmd.Name())
+	}
+	// A management restart has no completion to observe
+	assert.Nil(t, restartManagementCmd.Flags().Lookup("wait"))
+}
+
+// executeDryRun runs args through rootCmd with --dry-run against server
+func executeDryRun(t *testing.T, server *httptest.Server, args ...string) error {
+	t.Setenv("CLOUDAMQP_APIKEY", "test-api-key")
+	t.Setenv("CLOUDAMQP_API_URL", server.URL)
+	t.Setenv("CLOUDAMQP_POLICY_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Cleanup(func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.PersistentFlags().Set("dry-run", "false")
+	})
+
+	rootCmd.SetArgs(append(args, "--dry-run"))
+	return Execute()
+}
+
+func TestActionDryRunThroughRoot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s %s in dry-run", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(client.Instance{ID: 1234, Name: "dev"})
+	}))
+	defer server.Close()
+	defer stopClusterCmd.Flags().Set("force", "false")
+
+	assert.NoError(t, executeDryRun(t, server, "instance", "stop-cluster", "--id", "1234", "--force"))
+}
+
+func TestDryRunKeepsRealErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"Not found"}`))
+	}))
+	defer server.Close()
+
+	err := executeDryRun(t, server, "instance", "get", "--id", "9999")
+	assert.True(t, client.IsNotFound(err))
+}
+
+func TestRollingRestartOrder(t *testing.T) {
+	nodes := []client.Node{{Name: "node-01"}, {Name: "node-02"}, {Name: "node-03"}}
+
+	order, err := rollingRestartOrder(nodes, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node-01", "node-02", "node-03"}, order)
+
+	order, err = rollingRestartOrder(nodes, "node-03, node-01")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node-03", "node-01"}, order)
+
+	_, err = rollingRestartOrder(nodes, "node-04")
+	assert.Error(t, err)
+
+	_, err = rollingRestartOrder(nil, "")
+	assert.Error(t, err)
+}
+
+func TestNodeRestartsCheck(t *testing.T) {
+	// The platform API reports node-01 running, then down, then running again
+	polls := 0
+	states := []bool{true, false, true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/instances/1234/nodes":
+			running := states[min(polls, len(states)-1)]
+			polls++
+			json.NewEncoder(w).Encode([]client.Node{
+				{Name: "node-01", Running: running, Configured: true},
+				{Name: "node-02", Running: true, Configured: true},
+			})
+		case "/api/nodes":
+			// node-02 restarted between polls, its uptime dropped
+			json.NewEncoder(w).Encode([]rabbitmq.NodeStats{
+				{Name: "rabbit@node-01", Running: true, Uptime: 900000},
+				{Name: "rabbit@node-02", Running: true, Uptime: 2000},
+			})
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL("test-api-key", server.URL, "test")
+	restarts := &nodeRestarts{
+		c:          c,
+		mgmt:       rabbitmq.New(server.URL, "user", "secret", "test"),
+		instanceID: "1234",
+		uptimes:    map[string]int64{"node-01": 800000, "node-02": 500000},
+		restarted:  map[string]bool{},
+	}
+
+	check := restarts.Check([]string{"node-01"})
+	done, _, err := check()
+	assert.NoError(t, err)
+	assert.False(t, done, "a node still running with a growing uptime has not restarted")
+
+	done, _, err = check()
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// A restart seen once is remembered after the node is back
+	done, _, err = check()
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	done, _, err = restarts.Check([]string{"node-02"})()
+	assert.NoError(t, err)
+	assert.True(t, done, "a lower uptime counts as a restart")
+}
+
+func TestUpgradeHelpers(t *testing.T) {
+	planned := map[string]string{
+		"new_erlang_version":   "26.2.5",
+		"new_rabbitmq_version": "3.13.7",
+	}
+	assert.Equal(t, "3.13.7", plannedVersion(planned, "rabbitmq"))
+	assert.Equal(t, "26.2.5", plannedVersion(planned, "erlang"))
+	assert.Equal(t, "", plannedVersion(planned, "lavinmq"))
+
+	nodes := []client.Node{
+		{Name: "node-01", RabbitMQVersion: "3.13.7", ErlangVersion: "26.2.5"},
+		{Name: "node-02", RabbitMQVersion: "3.13.7", ErlangVersion: "26.1"},
+		{Name: "node-03", RabbitMQVersion: "3.12.0", ErlangVersion: "26.1"},
+	}
+	assert.Equal(t, 2, nodesUpgraded(nodes, "3.13.7", ""))
+	assert.Equal(t, 1, nodesUpgraded(nodes, "3.13.7", "26.2.5"))
+}
+
 func TestUpgradeRabbitMQCommand_RequiredFlag(t *testing.T) {
 	cmd := upgradeRabbitMQCmd
 
@@ -268,3 +422,53 @@ func TestCommandExamples(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrateDryRunStopsAfterCreate(t *testing.T) {
+	t.Setenv("CLOUDAMQP_MIGRATION_DIR", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/instances/1234" {
+			t.Errorf("unexpected %s %s in dry-run", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(client.Instance{ID: 1234, Name: "dev", Plan: "lemur", Region: "amazon-web-services::us-east-1"})
+	}))
+	defer server.Close()
+	defer func() {
+		instanceMigrateCmd.Flags().Set("force", "false")
+		instanceMigrateCmd.Flags().Set("to-plan", "")
+	}()
+
+	assert.NoError(t, executeDryRun(t, server, "instance", "migrate", "--from-id", "1234", "--to-plan", "bunny-1", "--force"))
+
+	dir := os.Getenv("CLOUDAMQP_MIGRATION_DIR")
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "no migration state is saved in dry-run mode")
+}
+
+func TestCompleteWaitID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/instances":
+			json.NewEncoder(w).Encode([]client.Instance{{ID: 1234, Name: "dev"}})
+		case "/vpcs":
+			json.NewEncoder(w).Encode([]client.VPC{{ID: 5678, Name: "net", Region: "amazon-web-services::us-east-1"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("CLOUDAMQP_APIKEY", "test-api-key")
+	t.Setenv("CLOUDAMQP_API_URL", server.URL)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer func() { waitConditions = nil }()
+
+	waitConditions = []string{"instance-ready"}
+	suggestions, _ := completeWaitID(waitCmd, nil, "")
+	assert.Equal(t, []string{"1234\tdev"}, suggestions)
+
+	waitConditions = []string{"vpc-ready"}
+	suggestions, _ = completeWaitID(waitCmd, nil, "")
+	assert.Equal(t, []string{"5678\tnet (amazon-web-services::us-east-1)"}, suggestions)
+}
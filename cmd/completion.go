@@ -1,18 +1,33 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"time"
 
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/table"
 	"github.com/spf13/cobra"
 )
 
 var completionCmd = &cobra.Command{
-	Use:   "completion [zsh|fish]",
+	Use:   "completion [bash|zsh|fish|powershell]",
 	Short: "Generate shell completion script",
 	Long: `Generate shell completion script for cloudamqp CLI.
 
 To load completions:
 
+Bash:
+
+  # Requires the bash-completion package. Add to ~/.bashrc:
+  source <(cloudamqp completion bash)
+
+  # Or install for all sessions:
+  cloudamqp completion bash > /etc/bash_completion.d/cloudamqp
+
 Zsh:
 
   # Add to ~/.zshrc:
@@ -22,20 +37,226 @@ Zsh:
   cloudamqp completion zsh > "${fpath[1]}/_cloudamqp"
 
 Fish:
-  cloudamqp completion zsh > "$XDG_CONFIG_HOME/fish/completions/cloudamqp.fish"
+  cloudamqp completion fish > "$XDG_CONFIG_HOME/fish/completions/cloudamqp.fish"
+
+PowerShell:
+
+  # Add to your PowerShell profile:
+  cloudamqp completion powershell | Out-String | Invoke-Expression
 
 # You may need to restart your shell for completions to take effect.
 `,
 	DisableFlagsInUseLine: true,
-	ValidArgs:             []string{"zsh", "fish"},
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		switch args[0] {
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(os.Stdout, true)
 		case "zsh":
 			return cmd.Root().GenZshCompletion(os.Stdout)
 		case "fish":
 			return cmd.Root().GenFishCompletion(os.Stdout, false)
+		case "powershell":
+			return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		return nil
+	},
+}
+
+// cacheFilePattern matches the files written by setCachedData
+var cacheFilePattern = regexp.MustCompile(`^cache_.+_ttl_(.+)\.json$`)
+
+var completionCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the completion cache",
+	Long: `Show, clear or fill the cache of API responses used for dynamic completions
+and by the list commands. Creating, updating or deleting an instance or VPC
+clears the cached instance and VPC lists.
+
+The cache is kept in $XDG_CACHE_HOME/cloudamqp, or ~/.cache/cloudamqp when
+XDG_CACHE_HOME is not set, with a separate directory per API key so that
+switching accounts never completes another account's instances.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var completionCacheStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Show the cached entries of the current API key",
+	Example: `  cloudamqp completion cache status`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKey, err := completionAPIKey()
+		if err != nil {
+			return err
+		}
+		cacheDir, err := getCacheDir(apiKey)
+		if err != nil {
+			return fmt.Errorf("failed to get cache directory: %w", err)
+		}
+
+		fmt.Printf("Cache directory = %s\n", cacheDir)
+		files, err := filepath.Glob(filepath.Join(cacheDir, "cache_*.json"))
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			fmt.Println("No cached entries.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "KEY", "AGE", "TTL", "STATE", "SIZE")
+		for _, file := range files {
+			match := cacheFilePattern.FindStringSubmatch(filepath.Base(file))
+			if match == nil {
+				continue
+			}
+			key := match[1]
+			age, size, err := cacheEntryAge(file)
+			if err != nil {
+				t.AddRow(key, "-", "-", "invalid", "-")
+				continue
+			}
+
+			ttl, state := "-", "unknown"
+			if keyTTL, ok := client.CacheTTLs[key]; ok {
+				ttl = keyTTL.String()
+				state = "fresh"
+				if age > keyTTL {
+					state = "expired"
+				}
+			}
+			t.AddRow(key, age.Round(time.Second).String(), ttl, state, formatBytes(size))
+		}
+		t.Print()
+		return nil
+	},
+}
+
+var completionCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached entries",
+	Long: `Removes the cached API responses of the current API key, or of all API keys
+with --all. Other state kept in the cache directory, such as the audit tail
+cursor, is not removed.`,
+	Example: `  cloudamqp completion cache clear
+  cloudamqp completion cache clear --all`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+
+		root, err := cacheRoot()
+		if err != nil {
+			return fmt.Errorf("failed to get cache directory: %w", err)
+		}
+
+		var patterns []string
+		if all {
+			// Entries written before the cache was split per API key are
+			// directly in the cache root
+			patterns = []string{filepath.Join(root, "cache_*.json"), filepath.Join(root, "*", "cache_*.json")}
+		} else {
+			apiKey, err := completionAPIKey()
+			if err != nil {
+				return err
+			}
+			patterns = []string{filepath.Join(root, cacheAccount(apiKey), "cache_*.json")}
 		}
+
+		removed := 0
+		for _, pattern := range patterns {
+			files, err := filepath.Glob(pattern)
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				if err := os.Remove(file); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", file, err)
+				}
+				removed++
+			}
+		}
+
+		fmt.Printf("Removed %d cached entries.\n", removed)
 		return nil
 	},
 }
+
+var completionCacheWarmCmd = &cobra.Command{
+	Use:     "warm",
+	Short:   "Fetch and cache the completion data of the current API key",
+	Long:    `Fetches instances, VPCs, plans and regions and stores them in the cache, so that the next completions are instant.`,
+	Example: `  cloudamqp completion cache warm`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		apiKey, err = getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		instances, err := c.ListInstances()
+		if err != nil {
+			return commandError("listing instances", err)
+		}
+		vpcs, err := c.ListVPCs()
+		if err != nil {
+			return commandError("listing VPCs", err)
+		}
+		plans, err := c.ListPlans("")
+		if err != nil {
+			return commandError("listing plans", err)
+		}
+		regions, err := c.ListRegions("")
+		if err != nil {
+			return commandError("listing regions", err)
+		}
+
+		entries := []struct {
+			key  string
+			data any
+		}{
+			{"instances", instances},
+			{"vpcs", vpcs},
+			{"plans", plans},
+			{"regions", regions},
+		}
+		for _, entry := range entries {
+			if err := setCachedData(apiKey, entry.key, client.CacheTTLs[entry.key], entry.data); err != nil {
+				return fmt.Errorf("failed to cache %s: %w", entry.key, err)
+			}
+		}
+
+		fmt.Printf("Cached %d instances, %d VPCs, %d plans and %d regions.\n", len(instances), len(vpcs), len(plans), len(regions))
+		return nil
+	},
+}
+
+// cacheEntryAge returns the age and file size of a cache entry
+func cacheEntryAge(path string) (time.Duration, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return 0, 0, err
+	}
+	return time.Since(time.Unix(entry.Timestamp, 0)), int64(len(data)), nil
+}
+
+func init() {
+	completionCacheClearCmd.Flags().Bool("all", false, "Remove the cached entries of all API keys")
+
+	completionCacheCmd.AddCommand(completionCacheStatusCmd)
+	completionCacheCmd.AddCommand(completionCacheClearCmd)
+	completionCacheCmd.AddCommand(completionCacheWarmCmd)
+	completionCmd.AddCommand(completionCacheCmd)
+}
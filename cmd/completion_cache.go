@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,14 +15,37 @@ type cacheEntry struct {
 	Timestamp int64           `json:"timestamp"`
 }
 
-// getCacheDir returns the cache directory path, creating it if needed
-func getCacheDir() (string, error) {
+// cacheRoot returns the cache directory path, $XDG_CACHE_HOME/cloudamqp or
+// ~/.cache/cloudamqp
+func cacheRoot() (string, error) {
+	if xdgCache := os.Getenv("XDG_CACHE_HOME"); xdgCache != "" {
+		return filepath.Join(xdgCache, "cloudamqp"), nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
+	return filepath.Join(homeDir, ".cache", "cloudamqp"), nil
+}
 
-	cacheDir := filepath.Join(homeDir, ".cache", "cloudamqp")
+// cacheAccount identifies the account of apiKey in cache paths without
+// revealing the key
+func cacheAccount(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:6])
+}
+
+// getCacheDir returns the cache directory of the account of apiKey, creating
+// it if needed. Each account has its own directory so that switching API keys
+// doesn't show another account's instances.
+func getCacheDir(apiKey string) (string, error) {
+	root, err := cacheRoot()
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir := filepath.Join(root, cacheAccount(apiKey))
 	if err := os.MkdirAll(cacheDir, 0700); err != nil {
 		return "", err
 	}
@@ -51,8 +76,8 @@ func getCacheFilename(key string, ttl time.Duration) string {
 }
 
 // getCachedData retrieves cached data if it exists and is not expired
-func getCachedData(key string, ttl time.Duration) (json.RawMessage, bool) {
-	cacheDir, err := getCacheDir()
+func getCachedData(apiKey, key string, ttl time.Duration) (json.RawMessage, bool) {
+	cacheDir, err := getCacheDir(apiKey)
 	if err != nil {
 		return nil, false
 	}
@@ -77,8 +102,8 @@ func getCachedData(key string, ttl time.Duration) (json.RawMessage, bool) {
 }
 
 // setCachedData stores data in the cache with current timestamp
-func setCachedData(key string, ttl time.Duration, data interface{}) error {
-	cacheDir, err := getCacheDir()
+func setCachedData(apiKey, key string, ttl time.Duration, data interface{}) error {
+	cacheDir, err := getCacheDir(apiKey)
 	if err != nil {
 		return err
 	}
@@ -102,10 +127,35 @@ func setCachedData(key string, ttl time.Duration, data interface{}) error {
 	return os.WriteFile(cachePath, entryData, 0600)
 }
 
-// Cache TTL settings
-const (
-	plansCacheTTL     = 1 * time.Hour   // Plans rarely change
-	regionsCacheTTL   = 1 * time.Hour   // Regions rarely change
-	instancesCacheTTL = 1 * time.Minute // Instances change frequently
-	vpcsCacheTTL      = 1 * time.Minute // VPCs change frequently
-)
+// fileCache is the client response cache of the account of apiKey, stored
+// in the same files as the completion data
+type fileCache struct {
+	apiKey string
+}
+
+func (f fileCache) Get(key string, ttl time.Duration) ([]byte, bool) {
+	return getCachedData(f.apiKey, key, ttl)
+}
+
+func (f fileCache) Set(key string, ttl time.Duration, data []byte) error {
+	return setCachedData(f.apiKey, key, ttl, json.RawMessage(data))
+}
+
+// Delete removes the entries of key for every TTL
+func (f fileCache) Delete(key string) error {
+	cacheDir, err := getCacheDir(f.apiKey)
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(cacheDir, "cache_*_ttl_"+key+".json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
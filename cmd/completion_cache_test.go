@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudamqp-cli/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheIsKeyedPerAPIKey(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", root)
+
+	require.NoError(t, setCachedData("key-a", "instances", client.CacheTTLs["instances"], []int{1, 2}))
+
+	data, ok := getCachedData("key-a", "instances", client.CacheTTLs["instances"])
+	require.True(t, ok)
+	var ids []int
+	require.NoError(t, json.Unmarshal(data, &ids))
+	assert.Equal(t, []int{1, 2}, ids)
+
+	_, ok = getCachedData("key-b", "instances", client.CacheTTLs["instances"])
+	assert.False(t, ok, "another API key must not see the cached entry")
+
+	dir, err := getCacheDir("key-a")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "cloudamqp", cacheAccount("key-a")), dir)
+	assert.NotContains(t, dir, "key-a")
+}
+
+func TestCompletionCacheClear(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", root)
+	t.Setenv("CLOUDAMQP_APIKEY", "key-a")
+
+	require.NoError(t, setCachedData("key-a", "plans", client.CacheTTLs["plans"], []string{"bunny-1"}))
+	require.NoError(t, setCachedData("key-b", "plans", client.CacheTTLs["plans"], []string{"bunny-1"}))
+	cursor := filepath.Join(root, "cloudamqp", cacheAccount("key-a"), "audit_cursor.json")
+	require.NoError(t, os.WriteFile(cursor, []byte("{}"), 0600))
+
+	cmd := completionCacheClearCmd
+	require.NoError(t, cmd.Flags().Set("all", "false"))
+	require.NoError(t, cmd.RunE(cmd, nil))
+
+	_, ok := getCachedData("key-a", "plans", client.CacheTTLs["plans"])
+	assert.False(t, ok)
+	_, ok = getCachedData("key-b", "plans", client.CacheTTLs["plans"])
+	assert.True(t, ok, "other API keys are kept without --all")
+	assert.FileExists(t, cursor)
+
+	require.NoError(t, cmd.Flags().Set("all", "true"))
+	defer cmd.Flags().Set("all", "false")
+	require.NoError(t, cmd.RunE(cmd, nil))
+
+	_, ok = getCachedData("key-b", "plans", client.CacheTTLs["plans"])
+	assert.False(t, ok)
+	assert.FileExists(t, cursor)
+}
+
+func TestFileCacheSharedWithCompletions(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cache := fileCache{apiKey: "key-a"}
+	ttl := client.CacheTTLs["instances"]
+	require.NoError(t, cache.Set("instances", ttl, []byte(`[{"id":1,"name":"first"}]`)))
+
+	var instances []client.Instance
+	data, ok := getCachedData("key-a", "instances", ttl)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal(data, &instances))
+	assert.Equal(t, "first", instances[0].Name)
+
+	// Entries written with another TTL are removed too
+	require.NoError(t, setCachedData("key-a", "instances", time.Hour, instances))
+	require.NoError(t, cache.Delete("instances"))
+	_, ok = cache.Get("instances", ttl)
+	assert.False(t, ok)
+	_, ok = getCachedData("key-a", "instances", time.Hour)
+	assert.False(t, ok)
+}
+
+func TestNoCacheMutationInvalidatesCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"id":2,"name":"second"}]`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("CLOUDAMQP_API_URL", server.URL)
+
+	stale := []client.Instance{{ID: 1, Name: "deleted"}}
+	require.NoError(t, setCachedData("key-a", "instances", client.CacheTTLs["instances"], stale))
+
+	noCache = true
+	defer func() { noCache = false }()
+	require.NoError(t, newClient("key-a").DeleteInstance(1))
+
+	noCache = false
+	instances, err := newClient("key-a").Cached().ListInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "second", instances[0].Name)
+}
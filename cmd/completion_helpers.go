@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
@@ -32,26 +30,11 @@ func completeInstances(cmd *cobra.Command, args []string, toComplete string) ([]
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var instances []client.Instance
-	if cachedData, ok := getCachedData("instances", instancesCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &instances); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	instances, err = c.ListInstances()
+	instances, err := newClient(apiKey).Cached().ListInstances()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("instances", instancesCacheTTL, instances)
-
-formatOutput:
 	var suggestions []string
 	for _, instance := range instances {
 		suggestions = append(suggestions, fmt.Sprintf("%d\t%s", instance.ID, instance.Name))
@@ -67,26 +50,11 @@ func completePlans(cmd *cobra.Command, args []string, toComplete string) ([]stri
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var plans []client.Plan
-	if cachedData, ok := getCachedData("plans", plansCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &plans); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	plans, err = c.ListPlans("")
+	plans, err := newClient(apiKey).Cached().ListPlans("")
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("plans", plansCacheTTL, plans)
-
-formatOutput:
 	var suggestions []string
 	for _, plan := range plans {
 		suggestions = append(suggestions, fmt.Sprintf("%s\t%s", plan.Name, plan.Backend))
@@ -102,26 +70,11 @@ func completeRegions(cmd *cobra.Command, args []string, toComplete string) ([]st
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var regions []client.Region
-	if cachedData, ok := getCachedData("regions", regionsCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &regions); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	regions, err = c.ListRegions("")
+	regions, err := newClient(apiKey).Cached().ListRegions("")
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("regions", regionsCacheTTL, regions)
-
-formatOutput:
 	var suggestions []string
 	for _, region := range regions {
 		fullRegion := fmt.Sprintf("%s::%s", region.Provider, region.Region)
@@ -138,26 +91,11 @@ func completeVPCs(cmd *cobra.Command, args []string, toComplete string) ([]strin
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var vpcs []client.VPC
-	if cachedData, ok := getCachedData("vpcs", vpcsCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &vpcs); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	vpcs, err = c.ListVPCs()
+	vpcs, err := newClient(apiKey).Cached().ListVPCs()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("vpcs", vpcsCacheTTL, vpcs)
-
-formatOutput:
 	var suggestions []string
 	for _, vpc := range vpcs {
 		suggestions = append(suggestions, fmt.Sprintf("%d\t%s (%s)", vpc.ID, vpc.Name, vpc.Region))
@@ -190,26 +128,11 @@ func completeVPCIDFlag(cmd *cobra.Command, args []string, toComplete string) ([]
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var vpcs []client.VPC
-	if cachedData, ok := getCachedData("vpcs", vpcsCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &vpcs); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	vpcs, err = c.ListVPCs()
+	vpcs, err := newClient(apiKey).Cached().ListVPCs()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("vpcs", vpcsCacheTTL, vpcs)
-
-formatOutput:
 	var suggestions []string
 	for _, vpc := range vpcs {
 		suggestions = append(suggestions, fmt.Sprintf("%d\t%s", vpc.ID, vpc.Name))
@@ -225,26 +148,11 @@ func completeCopyFromIDFlag(cmd *cobra.Command, args []string, toComplete string
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var instances []client.Instance
-	if cachedData, ok := getCachedData("instances", instancesCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &instances); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	instances, err = c.ListInstances()
+	instances, err := newClient(apiKey).Cached().ListInstances()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("instances", instancesCacheTTL, instances)
-
-formatOutput:
 	var suggestions []string
 	for _, instance := range instances {
 		suggestions = append(suggestions, fmt.Sprintf("%s\t%s", strconv.Itoa(instance.ID), instance.Name))
@@ -260,26 +168,11 @@ func completeVPCArgs(cmd *cobra.Command, args []string, toComplete string) ([]st
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	c := client.New(apiKey, Version)
-
-	// Try to get from cache
-	var vpcs []client.VPC
-	if cachedData, ok := getCachedData("vpcs", vpcsCacheTTL); ok {
-		if err := json.Unmarshal(cachedData, &vpcs); err == nil {
-			goto formatOutput
-		}
-	}
-
-	// Cache miss or error, fetch from API
-	vpcs, err = c.ListVPCs()
+	vpcs, err := newClient(apiKey).Cached().ListVPCs()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	// Store in cache
-	setCachedData("vpcs", vpcsCacheTTL, vpcs)
-
-formatOutput:
 	var suggestions []string
 	for _, vpc := range vpcs {
 		suggestions = append(suggestions, fmt.Sprintf("%d\t%s (%s)", vpc.ID, vpc.Name, vpc.Region))
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"cloudamqp-cli/client"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// stdinIsTerminal reports whether confirmations can be read interactively
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// addConfirmFlags adds the --force and --yes flags checked by confirmAction
+func addConfirmFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("force", false, "Skip confirmation prompt")
+	cmd.Flags().Bool("yes", false, "Skip confirmation prompt (alias for --force)")
+}
+
+// confirmAction asks the user to confirm a destructive action by typing the
+// name of the resource it affects. It returns true without prompting when
+// --force or --yes is set or in dry-run mode, and refuses when stdin is not a
+// terminal so that piped input cannot confirm by accident.
+func confirmAction(cmd *cobra.Command, action, kind, name string) (bool, error) {
+	if skipConfirmation(cmd) {
+		return true, nil
+	}
+
+	if !stdinIsTerminal() {
+		return false, fmt.Errorf("refusing to %s without confirmation: stdin is not a terminal, use --force or --yes", action)
+	}
+
+	fmt.Printf("This will %s.\n", action)
+	return readTypedName(kind, name)
+}
+
+func skipConfirmation(cmd *cobra.Command) bool {
+	force, _ := cmd.Flags().GetBool("force")
+	yes, _ := cmd.Flags().GetBool("yes")
+	return force || yes || dryRun
+}
+
+// confirmTypedName requires the user to type the name of the resource, even
+// when --force is set. It is used to override deletion protection.
+func confirmTypedName(kind, name string) error {
+	if !stdinIsTerminal() {
+		return fmt.Errorf("typed confirmation of the %s name requires a terminal", kind)
+	}
+
+	confirmed, err := readTypedName(kind, name)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return fmt.Errorf("confirmation did not match the %s name, operation cancelled", kind)
+	}
+	return nil
+}
+
+func readTypedName(kind, name string) (bool, error) {
+	fmt.Printf("Type the %s name (%s) to confirm: ", kind, name)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %v", err)
+	}
+
+	return strings.TrimSpace(response) == name, nil
+}
+
+// confirmInstanceAction confirms a destructive action on an instance by
+// asking for the instance name
+func confirmInstanceAction(cmd *cobra.Command, c *client.Client, instanceID, action string) (bool, error) {
+	if skipConfirmation(cmd) {
+		return true, nil
+	}
+
+	id, err := strconv.Atoi(instanceID)
+	if err != nil {
+		return false, fmt.Errorf("invalid instance ID: %v", err)
+	}
+
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	return confirmAction(cmd, fmt.Sprintf("%s instance %d (%s)", action, id, instance.Name), "instance", instance.Name)
+}
+
+// confirmVPCAction confirms a destructive action on a VPC by asking for the
+// VPC name
+func confirmVPCAction(cmd *cobra.Command, c *client.Client, vpcID int, action string) (bool, error) {
+	if skipConfirmation(cmd) {
+		return true, nil
+	}
+
+	vpc, err := c.GetVPC(vpcID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get VPC: %w", err)
+	}
+
+	return confirmAction(cmd, fmt.Sprintf("%s VPC %d (%s)", action, vpcID, vpc.Name), "VPC", vpc.Name)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmAction_NonInteractive(t *testing.T) {
+	original := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	defer func() { stdinIsTerminal = original }()
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		addConfirmFlags(cmd)
+		return cmd
+	}
+
+	// Without --force or --yes a non-terminal stdin is refused
+	confirmed, err := confirmAction(newCmd(), "delete instance 1234 (prod)", "instance", "prod")
+	assert.False(t, confirmed)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "stdin is not a terminal")
+
+	for _, flag := range []string{"force", "yes"} {
+		cmd := newCmd()
+		cmd.Flags().Set(flag, "true")
+		confirmed, err := confirmAction(cmd, "delete instance 1234 (prod)", "instance", "prod")
+		assert.NoError(t, err)
+		assert.True(t, confirmed, flag)
+	}
+
+	// Typed confirmation for protection overrides cannot be skipped
+	err = confirmTypedName("instance", "prod")
+	assert.Error(t, err)
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	costGroupFields = []string{"tag", "region", "vpc", "backend"}
+	costFormats     = []string{"table", "json", "csv"}
+)
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "Estimate the cost of instances",
+	Long:  `Compute the monthly cost of your instances from the plan prices.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var costReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show the monthly cost of all instances",
+	Long: `Lists the monthly cost of every instance, based on the list price of its plan,
+with the total for the account.
+
+With --group-by the cost is summed per tag, region, VPC or backend. Instances
+with several tags are counted in the group of each tag, so tag groups can add
+up to more than the total.
+
+Prices come from the plan list, which is cached for an hour. Discounts and
+usage based charges are not included.`,
+	Example: `  cloudamqp cost report
+  cloudamqp cost report --group-by=tag
+  cloudamqp cost report --group-by=region --format=csv > cost.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		format, _ := cmd.Flags().GetString("format")
+
+		if groupBy != "" && !containsString(costGroupFields, groupBy) {
+			return fmt.Errorf("invalid group-by value %q, must be one of: %s", groupBy, strings.Join(costGroupFields, ", "))
+		}
+		if !containsString(costFormats, format) {
+			return fmt.Errorf("invalid format %q, must be one of: %s", format, strings.Join(costFormats, ", "))
+		}
+
+		var err error
+		apiKey, err = getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		instances, err := c.Cached().ListInstances()
+		if err != nil {
+			return commandError("listing instances", err)
+		}
+		plans, err := c.Cached().ListPlans("")
+		if err != nil {
+			return commandError("listing plans", err)
+		}
+
+		costs := instanceCosts(instances, plans)
+		for _, cost := range costs {
+			if !cost.PriceKnown {
+				fmt.Fprintf(os.Stderr, "Warning: no price found for plan %s of instance %d, counted as $0.00\n", cost.Plan, cost.ID)
+			}
+		}
+
+		report := costReport{GroupBy: groupBy, Count: len(costs), Total: totalCost(costs)}
+		if groupBy == "" {
+			report.Instances = costs
+			return printCostReport(report, format)
+		}
+
+		vpcNames := map[int]string{}
+		if groupBy == "vpc" {
+			vpcs, err := c.Cached().ListVPCs()
+			if err != nil {
+				return commandError("listing VPCs", err)
+			}
+			for _, vpc := range vpcs {
+				vpcNames[vpc.ID] = vpc.Name
+			}
+		}
+		report.Groups = groupCosts(costs, groupBy, vpcNames)
+		return printCostReport(report, format)
+	},
+}
+
+var costEstimateCmd = &cobra.Command{
+	Use:   "estimate --plan <plan> [--count <n>]",
+	Short: "Estimate the cost of new instances",
+	Long:  `Prints the monthly and yearly list price of a number of instances on a plan.`,
+	Example: `  cloudamqp cost estimate --plan=bunny-1
+  cloudamqp cost estimate --plan=rabbit-3 --count=4`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		planName, _ := cmd.Flags().GetString("plan")
+		count, _ := cmd.Flags().GetInt("count")
+		if count < 1 {
+			return fmt.Errorf("count must be at least 1")
+		}
+
+		var err error
+		apiKey, err = getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		plans, err := c.Cached().ListPlans("")
+		if err != nil {
+			return commandError("listing plans", err)
+		}
+		plan, ok := findPlan(plans, planName)
+		if !ok {
+			return fmt.Errorf("unknown plan %q, see 'cloudamqp plans' for available plans", planName)
+		}
+
+		kind := "dedicated"
+		if plan.Shared {
+			kind = "shared"
+		}
+		monthly := plan.Price * float64(count)
+		fmt.Printf("Plan = %s (%s, %s)\n", plan.Name, plan.Backend, kind)
+		fmt.Printf("Price = %s per instance and month\n", formatPrice(plan.Price))
+		fmt.Printf("Instances = %d\n", count)
+		fmt.Printf("Monthly = $%.2f\n", monthly)
+		fmt.Printf("Yearly = $%.2f\n", monthly*12)
+		return nil
+	},
+}
+
+// instanceCost is the monthly list price of one instance
+type instanceCost struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Plan       string   `json:"plan"`
+	Region     string   `json:"region"`
+	Backend    string   `json:"backend"`
+	VPCID      *int     `json:"vpc_id,omitempty"`
+	Tags       []string `json:"tags"`
+	Monthly    float64  `json:"monthly"`
+	PriceKnown bool     `json:"price_known"`
+}
+
+// costGroup is the summed cost of the instances sharing a tag, region, VPC or
+// backend
+type costGroup struct {
+	Key       string  `json:"key"`
+	Instances int     `json:"instances"`
+	Monthly   float64 `json:"monthly"`
+}
+
+type costReport struct {
+	GroupBy   string         `json:"group_by,omitempty"`
+	Instances []instanceCost `json:"instances,omitempty"`
+	Groups    []costGroup    `json:"groups,omitempty"`
+	Count     int            `json:"instance_count"`
+	Total     float64        `json:"total"`
+}
+
+// findPlan returns the plan named name
+func findPlan(plans []client.Plan, name string) (client.Plan, bool) {
+	for _, plan := range plans {
+		if plan.Name == name {
+			return plan, true
+		}
+	}
+	return client.Plan{}, false
+}
+
+// instanceCosts prices each instance by its plan. Instances on plans missing
+// from plans cost 0 and are marked as not known.
+func instanceCosts(instances []client.Instance, plans []client.Plan) []instanceCost {
+	costs := make([]instanceCost, 0, len(instances))
+	for _, instance := range instances {
+		plan, ok := findPlan(plans, instance.Plan)
+		costs = append(costs, instanceCost{
+			ID:         instance.ID,
+			Name:       instance.Name,
+			Plan:       instance.Plan,
+			Region:     instance.Region,
+			Backend:    plan.Backend,
+			VPCID:      instance.VPCID,
+			Tags:       instance.Tags,
+			Monthly:    plan.Price,
+			PriceKnown: ok,
+		})
+	}
+	return costs
+}
+
+func totalCost(costs []instanceCost) float64 {
+	var total float64
+	for _, cost := range costs {
+		total += cost.Monthly
+	}
+	return total
+}
+
+// groupCosts sums costs per value of field, most expensive groups first. VPCs
+// are shown by name when vpcNames has it.
+func groupCosts(costs []instanceCost, field string, vpcNames map[int]string) []costGroup {
+	index := map[string]int{}
+	var groups []costGroup
+	add := func(key string, cost instanceCost) {
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, costGroup{Key: key})
+		}
+		groups[i].Instances++
+		groups[i].Monthly += cost.Monthly
+	}
+
+	for _, cost := range costs {
+		switch field {
+		case "tag":
+			if len(cost.Tags) == 0 {
+				add("(untagged)", cost)
+			}
+			for _, tag := range cost.Tags {
+				add(tag, cost)
+			}
+		case "region":
+			add(cost.Region, cost)
+		case "vpc":
+			switch {
+			case cost.VPCID == nil:
+				add("(no vpc)", cost)
+			case vpcNames[*cost.VPCID] != "":
+				add(vpcNames[*cost.VPCID], cost)
+			default:
+				add("vpc-"+strconv.Itoa(*cost.VPCID), cost)
+			}
+		case "backend":
+			if cost.Backend == "" {
+				add("(unknown)", cost)
+			} else {
+				add(cost.Backend, cost)
+			}
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Monthly != groups[j].Monthly {
+			return groups[i].Monthly > groups[j].Monthly
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func printCostReport(report costReport, format string) error {
+	switch format {
+	case "json":
+		output, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format cost report: %w", err)
+		}
+		fmt.Println(string(output))
+		return nil
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		for _, row := range costReportRows(report, func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }) {
+			writer.Write(row)
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		rows := costReportRows(report, func(v float64) string { return fmt.Sprintf("$%.2f", v) })
+		t := table.New(os.Stdout, rows[0]...)
+		for _, row := range rows[1:] {
+			t.AddRow(row...)
+		}
+		t.Print()
+		return nil
+	}
+}
+
+// costReportRows returns the header, a row per instance or group and the total
+func costReportRows(report costReport, money func(float64) string) [][]string {
+	if report.GroupBy != "" {
+		rows := [][]string{{strings.ToUpper(report.GroupBy), "INSTANCES", "MONTHLY"}}
+		for _, g := range report.Groups {
+			rows = append(rows, []string{g.Key, strconv.Itoa(g.Instances), money(g.Monthly)})
+		}
+		return append(rows, []string{"TOTAL", strconv.Itoa(report.Count), money(report.Total)})
+	}
+
+	rows := [][]string{{"ID", "NAME", "PLAN", "REGION", "MONTHLY"}}
+	for _, cost := range report.Instances {
+		rows = append(rows, []string{strconv.Itoa(cost.ID), cost.Name, cost.Plan, cost.Region, money(cost.Monthly)})
+	}
+	return append(rows, []string{"", "TOTAL", "", "", money(report.Total)})
+}
+
+func init() {
+	costReportCmd.Flags().String("group-by", "", "Sum the cost per tag, region, vpc or backend")
+	costReportCmd.Flags().String("format", "table", "Output format: table, json or csv")
+	costReportCmd.RegisterFlagCompletionFunc("group-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return costGroupFields, cobra.ShellCompDirectiveNoFileComp
+	})
+	costReportCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return costFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	costEstimateCmd.Flags().String("plan", "", "Plan name (required)")
+	costEstimateCmd.Flags().Int("count", 1, "Number of instances")
+	costEstimateCmd.MarkFlagRequired("plan")
+	costEstimateCmd.RegisterFlagCompletionFunc("plan", completePlans)
+
+	costCmd.AddCommand(costReportCmd)
+	costCmd.AddCommand(costEstimateCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"cloudamqp-cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceCostsAndGroups(t *testing.T) {
+	vpcID := 7
+	instances := []client.Instance{
+		{ID: 1, Name: "orders", Plan: "rabbit-1", Region: "amazon-web-services::us-east-1", Tags: []string{"prod", "team-a"}, VPCID: &vpcID},
+		{ID: 2, Name: "events", Plan: "rabbit-1", Region: "amazon-web-services::eu-west-1", Tags: []string{"prod"}},
+		{ID: 3, Name: "dev", Plan: "lemming", Region: "amazon-web-services::us-east-1"},
+		{ID: 4, Name: "old", Plan: "retired-plan", Region: "amazon-web-services::us-east-1"},
+	}
+	plans := []client.Plan{
+		{Name: "rabbit-1", Price: 299, Backend: "rabbitmq"},
+		{Name: "lemming", Price: 0, Backend: "rabbitmq", Shared: true},
+	}
+
+	costs := instanceCosts(instances, plans)
+	assert.Len(t, costs, 4)
+	assert.Equal(t, 299.0, costs[0].Monthly)
+	assert.True(t, costs[2].PriceKnown)
+	assert.False(t, costs[3].PriceKnown)
+	assert.Equal(t, 598.0, totalCost(costs))
+
+	tags := groupCosts(costs, "tag", nil)
+	assert.Equal(t, []costGroup{
+		{Key: "prod", Instances: 2, Monthly: 598},
+		{Key: "team-a", Instances: 1, Monthly: 299},
+		{Key: "(untagged)", Instances: 2, Monthly: 0},
+	}, tags)
+
+	vpcs := groupCosts(costs, "vpc", map[int]string{7: "main"})
+	assert.Equal(t, []costGroup{
+		{Key: "(no vpc)", Instances: 3, Monthly: 299},
+		{Key: "main", Instances: 1, Monthly: 299},
+	}, vpcs)
+
+	backends := groupCosts(costs, "backend", nil)
+	assert.Equal(t, []costGroup{
+		{Key: "rabbitmq", Instances: 3, Monthly: 598},
+		{Key: "(unknown)", Instances: 1, Monthly: 0},
+	}, backends)
+}
+
+func TestCostReportRows(t *testing.T) {
+	report := costReport{
+		GroupBy: "region",
+		Groups:  []costGroup{{Key: "us-east-1", Instances: 2, Monthly: 299}},
+		Count:   2,
+		Total:   299,
+	}
+	rows := costReportRows(report, func(v float64) string { return formatPrice(v) })
+	assert.Equal(t, [][]string{
+		{"REGION", "INSTANCES", "MONTHLY"},
+		{"us-east-1", "2", "$299.00"},
+		{"TOTAL", "2", "$299.00"},
+	}, rows)
+}
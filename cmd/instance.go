@@ -20,8 +20,26 @@ func init() {
 	instanceCmd.AddCommand(instanceConfigCmd)
 	instanceCmd.AddCommand(instanceNodesCmd)
 	instanceCmd.AddCommand(instancePluginsCmd)
+	instanceCmd.AddCommand(instanceQueuesCmd)
+	instanceCmd.AddCommand(instanceExchangesCmd)
+	instanceCmd.AddCommand(instanceBindingsCmd)
+	instanceCmd.AddCommand(instanceDefinitionsCmd)
+	instanceCmd.AddCommand(instancePublishCmd)
+	instanceCmd.AddCommand(instanceConsumeCmd)
+	instanceCmd.AddCommand(instanceDoctorCmd)
+	instanceCmd.AddCommand(instanceVhostsCmd)
+	instanceCmd.AddCommand(instanceUsersCmd)
+	instanceCmd.AddCommand(instancePermissionsCmd)
+	instanceCmd.AddCommand(instancePoliciesCmd)
+	instanceCmd.AddCommand(instanceOperatorPoliciesCmd)
+	instanceCmd.AddCommand(instanceShovelCmd)
+	instanceCmd.AddCommand(instanceFederationCmd)
+	instanceCmd.AddCommand(instanceMigrateCmd)
+	instanceCmd.AddCommand(instanceTopCmd)
+	instanceCmd.AddCommand(instanceRightsizeCmd)
 	// Action commands (flattened from actions subcommand)
 	instanceCmd.AddCommand(restartRabbitMQCmd)
+	instanceCmd.AddCommand(instanceRollingRestartCmd)
 	instanceCmd.AddCommand(restartClusterCmd)
 	instanceCmd.AddCommand(restartManagementCmd)
 	instanceCmd.AddCommand(stopCmd)
@@ -33,4 +51,7 @@ func init() {
 	instanceCmd.AddCommand(upgradeRabbitMQCmd)
 	instanceCmd.AddCommand(upgradeRabbitMQErlangCmd)
 	instanceCmd.AddCommand(upgradeVersionsCmd)
+	instanceCmd.AddCommand(toggleHiPECmd)
+	instanceCmd.AddCommand(toggleFirehoseCmd)
+	instanceCmd.AddCommand(instanceUpgradeCmd)
 }
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
@@ -35,12 +34,11 @@ var rotatePasswordCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		err = c.RotatePassword(idFlag)
 		if err != nil {
-			fmt.Printf("Error rotating password: %v\n", err)
-			return err
+			return commandError("rotating password", err)
 		}
 
 		fmt.Println("Password rotation initiated successfully.")
@@ -65,12 +63,11 @@ var rotateInstanceAPIKeyCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		err = c.RotateInstanceAPIKey(idFlag)
 		if err != nil {
-			fmt.Printf("Error rotating instance API key: %v\n", err)
-			return err
+			return commandError("rotating instance API key", err)
 		}
 
 		fmt.Println("Instance API key rotation initiated successfully.")
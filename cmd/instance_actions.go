@@ -9,24 +9,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var instanceActionsCmd = &cobra.Command{
-	Use:   "actions",
-	Short: "Perform instance actions",
-	Long:  `Restart, stop, start, reboot, and upgrade instance components.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		cmd.Help()
-		cmd.SilenceUsage = true
-		return fmt.Errorf("subcommand required")
-	},
-}
-
 // Restart commands
 var restartRabbitMQCmd = &cobra.Command{
 	Use:   "restart-rabbitmq --id <instance_id>",
 	Short: "Restart RabbitMQ",
 	Long:  `Restart RabbitMQ on specified nodes or all nodes.`,
 	Example: `  cloudamqp instance restart-rabbitmq --id 1234
-  cloudamqp instance restart-rabbitmq --id 1234 --nodes=node1,node2`,
+  cloudamqp instance restart-rabbitmq --id 1234 --nodes=node1,node2
+  cloudamqp instance restart-rabbitmq --id 1234 --wait`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return performNodeAction(cmd, "restart-rabbitmq")
 	},
@@ -74,10 +64,11 @@ var startCmd = &cobra.Command{
 }
 
 var rebootCmd = &cobra.Command{
-	Use:     "reboot --id <instance_id>",
-	Short:   "Reboot instance",
-	Long:    `Reboot specified nodes or all nodes.`,
-	Example: `  cloudamqp instance reboot --id 1234`,
+	Use:   "reboot --id <instance_id>",
+	Short: "Reboot instance",
+	Long:  `Reboot specified nodes or all nodes.`,
+	Example: `  cloudamqp instance reboot --id 1234
+  cloudamqp instance reboot --id 1234 --yes`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return performNodeAction(cmd, "reboot")
 	},
@@ -85,20 +76,22 @@ var rebootCmd = &cobra.Command{
 
 // Cluster commands
 var stopClusterCmd = &cobra.Command{
-	Use:     "stop-cluster --id <instance_id>",
-	Short:   "Stop cluster",
-	Long:    `Stop the entire cluster.`,
-	Example: `  cloudamqp instance stop-cluster --id 1234`,
+	Use:   "stop-cluster --id <instance_id>",
+	Short: "Stop cluster",
+	Long:  `Stop the entire cluster.`,
+	Example: `  cloudamqp instance stop-cluster --id 1234
+  cloudamqp instance stop-cluster --id 1234 --wait`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return performClusterAction(cmd, "stop-cluster")
 	},
 }
 
 var startClusterCmd = &cobra.Command{
-	Use:     "start-cluster --id <instance_id>",
-	Short:   "Start cluster",
-	Long:    `Start the entire cluster.`,
-	Example: `  cloudamqp instance start-cluster --id 1234`,
+	Use:   "start-cluster --id <instance_id>",
+	Short: "Start cluster",
+	Long:  `Start the entire cluster.`,
+	Example: `  cloudamqp instance start-cluster --id 1234
+  cloudamqp instance start-cluster --id 1234 --wait`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return performClusterAction(cmd, "start-cluster")
 	},
@@ -111,7 +104,8 @@ var upgradeErlangCmd = &cobra.Command{
 	Long: `Always updates to latest compatible version.
 
 Note: This action is asynchronous. The request will return immediately, the process runs in the background.`,
-	Example: `  cloudamqp instance upgrade-erlang --id 1234`,
+	Example: `  cloudamqp instance upgrade-erlang --id 1234
+  cloudamqp instance upgrade-erlang --id 1234 --force`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return performUpgradeAction(cmd, "upgrade-erlang", "")
 	},
@@ -122,8 +116,10 @@ var upgradeRabbitMQCmd = &cobra.Command{
 	Short: "Upgrade RabbitMQ",
 	Long: `Upgrade RabbitMQ to specified version.
 
-Note: This action is asynchronous. The request will return immediately, the process runs in the background.`,
-	Example: `  cloudamqp instance upgrade-rabbitmq --id 1234 --version=3.10.7`,
+Note: This action is asynchronous. The request will return immediately, the process runs in the background.
+Use --wait to wait until all nodes run the new version.`,
+	Example: `  cloudamqp instance upgrade-rabbitmq --id 1234 --version=3.10.7
+  cloudamqp instance upgrade-rabbitmq --id 1234 --version=3.10.7 --wait --wait-timeout=30m`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		version, _ := cmd.Flags().GetString("version")
 		if version == "" {
@@ -183,12 +179,11 @@ var upgradeVersionsCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		versions, err := c.GetUpgradeVersions(idFlag)
 		if err != nil {
-			fmt.Printf("Error getting upgrade versions: %v\n", err)
-			return err
+			return commandError("getting upgrade versions", err)
 		}
 
 		output, err := json.MarshalIndent(versions, "", "  ")
@@ -214,7 +209,7 @@ func performNodeAction(cmd *cobra.Command, action string) error {
 		return fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	c := client.New(apiKey, Version)
+	c := newClient(apiKey)
 
 	nodesStr, _ := cmd.Flags().GetString("nodes")
 	var nodes []string
@@ -222,6 +217,30 @@ func performNodeAction(cmd *cobra.Command, action string) error {
 		nodes = strings.Split(nodesStr, ",")
 	}
 
+	if action == "stop" {
+		if err := checkInstanceProtectionByID(cmd, c, idFlag, "stop"); err != nil {
+			return err
+		}
+	}
+
+	if action == "reboot" {
+		confirmed, err := confirmInstanceAction(cmd, c, idFlag, "reboot nodes of")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Reboot cancelled.")
+			return nil
+		}
+	}
+
+	// Restarted nodes still report running right after the request, so --wait
+	// first has to see the restart happen
+	var restarts *nodeRestarts
+	if action == "restart-rabbitmq" || action == "reboot" {
+		restarts = trackRestarts(cmd, c, idFlag)
+	}
+
 	switch action {
 	case "restart-rabbitmq":
 		err = c.RestartRabbitMQ(idFlag, nodes)
@@ -238,12 +257,18 @@ func performNodeAction(cmd *cobra.Command, action string) error {
 	}
 
 	if err != nil {
-		fmt.Printf("Error performing %s: %v\n", action, err)
-		return err
+		return commandError(fmt.Sprintf("performing %s", action), err)
 	}
 
 	fmt.Printf("%s initiated successfully.\n", strings.Title(strings.ReplaceAll(action, "-", " ")))
-	return nil
+
+	switch action {
+	case "stop":
+		return waitIfRequested(cmd, c, idFlag, "nodes-stopped", nodes, nil)
+	case "restart-management":
+		return nil
+	}
+	return waitIfRequested(cmd, c, idFlag, "nodes-running", nodes, restarts)
 }
 
 func performClusterAction(cmd *cobra.Command, action string) error {
@@ -258,7 +283,27 @@ func performClusterAction(cmd *cobra.Command, action string) error {
 		return fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	c := client.New(apiKey, Version)
+	c := newClient(apiKey)
+
+	if action == "stop-cluster" {
+		if err := checkInstanceProtectionByID(cmd, c, idFlag, "stop"); err != nil {
+			return err
+		}
+
+		confirmed, err := confirmInstanceAction(cmd, c, idFlag, "stop all nodes of")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Stop cluster cancelled.")
+			return nil
+		}
+	}
+
+	var restarts *nodeRestarts
+	if action == "restart-cluster" {
+		restarts = trackRestarts(cmd, c, idFlag)
+	}
 
 	switch action {
 	case "restart-cluster":
@@ -272,12 +317,15 @@ func performClusterAction(cmd *cobra.Command, action string) error {
 	}
 
 	if err != nil {
-		fmt.Printf("Error performing %s: %v\n", action, err)
-		return err
+		return commandError(fmt.Sprintf("performing %s", action), err)
 	}
 
 	fmt.Printf("%s initiated successfully.\n", strings.Title(strings.ReplaceAll(action, "-", " ")))
-	return nil
+
+	if action == "stop-cluster" {
+		return waitIfRequested(cmd, c, idFlag, "nodes-stopped", nil, nil)
+	}
+	return waitIfRequested(cmd, c, idFlag, "nodes-running", nil, restarts)
 }
 
 func performUpgradeAction(cmd *cobra.Command, action, version string) error {
@@ -292,7 +340,16 @@ func performUpgradeAction(cmd *cobra.Command, action, version string) error {
 		return fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	c := client.New(apiKey, Version)
+	c := newClient(apiKey)
+
+	confirmed, err := confirmInstanceAction(cmd, c, idFlag, action+" on")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Upgrade cancelled.")
+		return nil
+	}
 
 	switch action {
 	case "upgrade-erlang":
@@ -306,11 +363,14 @@ func performUpgradeAction(cmd *cobra.Command, action, version string) error {
 	}
 
 	if err != nil {
-		fmt.Printf("Error performing %s: %v\n", action, err)
-		return err
+		return commandError(fmt.Sprintf("performing %s", action), err)
 	}
 
 	fmt.Printf("%s initiated successfully.\n", strings.Title(strings.ReplaceAll(action, "-", " ")))
+
+	if action == "upgrade-rabbitmq" {
+		return waitIfRequested(cmd, c, idFlag, "version="+version, nil, nil)
+	}
 	return nil
 }
 
@@ -326,14 +386,14 @@ func performToggleAction(cmd *cobra.Command, action string) error {
 		return fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	c := client.New(apiKey, Version)
+	c := newClient(apiKey)
 
 	enable, _ := cmd.Flags().GetBool("enable")
+	var nodes []string
 
 	switch action {
 	case "hipe":
 		nodesStr, _ := cmd.Flags().GetString("nodes")
-		var nodes []string
 		if nodesStr != "" {
 			nodes = strings.Split(nodesStr, ",")
 		}
@@ -361,8 +421,7 @@ func performToggleAction(cmd *cobra.Command, action string) error {
 	}
 
 	if err != nil {
-		fmt.Printf("Error toggling %s: %v\n", action, err)
-		return err
+		return commandError(fmt.Sprintf("toggling %s", action), err)
 	}
 
 	status := "disabled"
@@ -370,6 +429,10 @@ func performToggleAction(cmd *cobra.Command, action string) error {
 		status = "enabled"
 	}
 	fmt.Printf("%s %s successfully.\n", strings.Title(action), status)
+
+	if action == "hipe" {
+		return waitIfRequested(cmd, c, idFlag, fmt.Sprintf("hipe=%t", enable), nodes, nil)
+	}
 	return nil
 }
 
@@ -396,6 +459,29 @@ func init() {
 	startCmd.Flags().String("nodes", "", "Comma-separated list of node names")
 	rebootCmd.Flags().String("nodes", "", "Comma-separated list of node names")
 
+	addProtectionFlag(stopCmd)
+	addProtectionFlag(stopClusterCmd)
+
+	// Add confirmation flags to disruptive actions
+	for _, cmd := range []*cobra.Command{
+		rebootCmd, stopClusterCmd,
+		upgradeErlangCmd, upgradeRabbitMQCmd, upgradeRabbitMQErlangCmd,
+	} {
+		addConfirmFlags(cmd)
+	}
+
+	// Add wait flags to asynchronous actions with an observable result. A
+	// management restart leaves the nodes running and the management API may
+	// be back before it is polled, so it has no completion to wait for.
+	for _, cmd := range []*cobra.Command{
+		restartRabbitMQCmd, restartClusterCmd,
+		stopCmd, startCmd, rebootCmd,
+		stopClusterCmd, startClusterCmd,
+		upgradeRabbitMQCmd, toggleHiPECmd,
+	} {
+		addWaitFlags(cmd, "15m")
+	}
+
 	// Add version flag for RabbitMQ upgrade
 	upgradeRabbitMQCmd.Flags().String("version", "", "RabbitMQ version (required)")
 	upgradeRabbitMQCmd.MarkFlagRequired("version")
@@ -410,19 +496,4 @@ func init() {
 	toggleFirehoseCmd.MarkFlagRequired("enable")
 	toggleFirehoseCmd.MarkFlagRequired("vhost")
 
-	// Add all commands to actions
-	instanceActionsCmd.AddCommand(restartRabbitMQCmd)
-	instanceActionsCmd.AddCommand(restartClusterCmd)
-	instanceActionsCmd.AddCommand(restartManagementCmd)
-	instanceActionsCmd.AddCommand(stopCmd)
-	instanceActionsCmd.AddCommand(startCmd)
-	instanceActionsCmd.AddCommand(rebootCmd)
-	instanceActionsCmd.AddCommand(stopClusterCmd)
-	instanceActionsCmd.AddCommand(startClusterCmd)
-	instanceActionsCmd.AddCommand(upgradeErlangCmd)
-	instanceActionsCmd.AddCommand(upgradeRabbitMQCmd)
-	instanceActionsCmd.AddCommand(upgradeRabbitMQErlangCmd)
-	instanceActionsCmd.AddCommand(toggleHiPECmd)
-	instanceActionsCmd.AddCommand(toggleFirehoseCmd)
-	instanceActionsCmd.AddCommand(upgradeVersionsCmd)
 }
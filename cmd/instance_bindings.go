@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceBindingsCmd = &cobra.Command{
+	Use:   "bindings",
+	Short: "Manage bindings",
+	Long: `List, create, and delete bindings through the RabbitMQ management API, and
+render the routing topology as a graph.
+
+The management API is reached with the credentials of the instance URL.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceBindingsListCmd = &cobra.Command{
+	Use:   "list --id <instance_id>",
+	Short: "List bindings",
+	Long: `Lists bindings in all vhosts, or in the vhost given by --vhost.
+
+Bindings from the default exchange, which every queue has, are only listed
+with --include-default.`,
+	Example: `  cloudamqp instance bindings list --id 1234
+  cloudamqp instance bindings list --id 1234 --vhost=myvhost --source=orders`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vhost, _ := cmd.Flags().GetString("vhost")
+		source, _ := cmd.Flags().GetString("source")
+		destination, _ := cmd.Flags().GetString("destination")
+		includeDefault, _ := cmd.Flags().GetBool("include-default")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		bindings, err := mgmt.ListBindings(vhost)
+		if err != nil {
+			return commandError("listing bindings", err)
+		}
+
+		var matched []rabbitmq.Binding
+		for _, b := range filterBindings(bindings, includeDefault) {
+			if source != "" && b.Source != source {
+				continue
+			}
+			if destination != "" && b.Destination != destination {
+				continue
+			}
+			matched = append(matched, b)
+		}
+
+		if len(matched) == 0 {
+			fmt.Println("No bindings found.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "VHOST", "SOURCE", "DESTINATION", "DESTINATION_TYPE", "ROUTING_KEY", "ARGUMENTS")
+		for _, b := range matched {
+			arguments := "-"
+			if len(b.Arguments) > 0 {
+				data, _ := json.Marshal(b.Arguments)
+				arguments = string(data)
+			}
+			t.AddRow(b.Vhost, exchangeDisplayName(b.Source), b.Destination, b.DestinationType, b.RoutingKey, arguments)
+		}
+		t.Print()
+
+		return nil
+	},
+}
+
+var instanceBindingsCreateCmd = &cobra.Command{
+	Use:   "create --id <instance_id> --source <exchange> --destination <name>",
+	Short: "Create a binding",
+	Long: `Binds a queue, or an exchange with --destination-type=exchange, to a source
+exchange.
+
+The vhost defaults to the vhost of the instance URL. Arguments are given as a
+JSON object, e.g. the match headers of a headers exchange binding.`,
+	Example: `  cloudamqp instance bindings create --id 1234 --source=orders --destination=orders.eu --routing-key='eu.#'
+  cloudamqp instance bindings create --id 1234 --source=events --destination=audit --destination-type=exchange
+  cloudamqp instance bindings create --id 1234 --source=reports --destination=pdf --arguments='{"x-match": "all", "format": "pdf"}'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("source")
+		destination, _ := cmd.Flags().GetString("destination")
+		destinationType, _ := cmd.Flags().GetString("destination-type")
+		routingKey, _ := cmd.Flags().GetString("routing-key")
+		argumentsFlag, _ := cmd.Flags().GetString("arguments")
+
+		arguments, err := parseArguments(argumentsFlag)
+		if err != nil {
+			return err
+		}
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		if err := mgmt.CreateBinding(vhost, source, destinationType, destination, routingKey, arguments); err != nil {
+			return commandError("creating binding", err)
+		}
+
+		fmt.Printf("Binding from %s to %s %s created.\n", source, destinationType, destination)
+		return nil
+	},
+}
+
+var instanceBindingsDeleteCmd = &cobra.Command{
+	Use:   "delete --id <instance_id> --source <exchange> --destination <name>",
+	Short: "Delete a binding",
+	Long: `Deletes the binding between a source exchange and a destination with the
+given routing key.
+
+When several bindings share the routing key, --arguments selects the one to
+delete. The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance bindings delete --id 1234 --source=orders --destination=orders.eu --routing-key='eu.#'`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("source")
+		destination, _ := cmd.Flags().GetString("destination")
+		destinationType, _ := cmd.Flags().GetString("destination-type")
+		routingKey, _ := cmd.Flags().GetString("routing-key")
+		argumentsFlag, _ := cmd.Flags().GetString("arguments")
+
+		arguments, err := parseArguments(argumentsFlag)
+		if err != nil {
+			return err
+		}
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		bindings, err := mgmt.ListBindingsBetween(vhost, source, destinationType, destination)
+		if err != nil {
+			return commandError("listing bindings", err)
+		}
+
+		binding, err := findBinding(bindings, routingKey, arguments, argumentsFlag != "")
+		if err != nil {
+			return err
+		}
+
+		if err := mgmt.DeleteBinding(vhost, source, destinationType, destination, binding.PropertiesKey); err != nil {
+			return commandError("deleting binding", err)
+		}
+
+		fmt.Printf("Binding from %s to %s %s deleted.\n", source, destinationType, destination)
+		return nil
+	},
+}
+
+var instanceBindingsGraphCmd = &cobra.Command{
+	Use:   "graph --id <instance_id>",
+	Short: "Render the routing topology as a graph",
+	Long: `Renders exchanges, queues and the bindings between them as a Graphviz DOT or
+Mermaid flowchart. Each vhost is drawn as a separate cluster.
+
+Bindings from the default exchange are only included with --include-default.`,
+	Example: `  cloudamqp instance bindings graph --id 1234 | dot -Tsvg -o topology.svg
+  cloudamqp instance bindings graph --id 1234 --vhost=myvhost --format=mermaid > topology.mmd`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vhost, _ := cmd.Flags().GetString("vhost")
+		format, _ := cmd.Flags().GetString("format")
+		includeDefault, _ := cmd.Flags().GetBool("include-default")
+
+		if format != "dot" && format != "mermaid" {
+			return fmt.Errorf("invalid format %q, must be dot or mermaid", format)
+		}
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		exchanges, err := mgmt.ListExchanges(vhost)
+		if err != nil {
+			return commandError("listing exchanges", err)
+		}
+
+		bindings, err := mgmt.ListBindings(vhost)
+		if err != nil {
+			return commandError("listing bindings", err)
+		}
+
+		graph := newBindingGraph(exchanges, filterBindings(bindings, includeDefault))
+		if format == "mermaid" {
+			graph.writeMermaid(os.Stdout)
+		} else {
+			graph.writeDOT(os.Stdout)
+		}
+		return nil
+	},
+}
+
+// filterBindings drops bindings from the default exchange unless includeDefault is set
+func filterBindings(bindings []rabbitmq.Binding, includeDefault bool) []rabbitmq.Binding {
+	if includeDefault {
+		return bindings
+	}
+	var filtered []rabbitmq.Binding
+	for _, b := range bindings {
+		if b.Source != "" {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+// findBinding returns the binding with the routing key, and the arguments if
+// matchArguments is set. It fails when no or several bindings match.
+func findBinding(bindings []rabbitmq.Binding, routingKey string, arguments map[string]any, matchArguments bool) (*rabbitmq.Binding, error) {
+	var matched []rabbitmq.Binding
+	for _, b := range bindings {
+		if b.RoutingKey != routingKey {
+			continue
+		}
+		if matchArguments && !reflect.DeepEqual(b.Arguments, arguments) {
+			continue
+		}
+		matched = append(matched, b)
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("no binding found with routing key %q", routingKey)
+	case 1:
+		return &matched[0], nil
+	default:
+		return nil, fmt.Errorf("%d bindings found with routing key %q, use --arguments to select one", len(matched), routingKey)
+	}
+}
+
+// bindingGraph is the routing topology of one or more vhosts
+type bindingGraph struct {
+	vhosts []string
+	nodes  map[string][]graphNode
+	edges  []rabbitmq.Binding
+	ids    map[string]string
+}
+
+type graphNode struct {
+	key   string
+	label string
+	queue bool
+}
+
+func graphNodeKey(vhost, kind, name string) string {
+	return vhost + "\x00" + kind + "\x00" + name
+}
+
+func newBindingGraph(exchanges []rabbitmq.Exchange, bindings []rabbitmq.Binding) *bindingGraph {
+	exchangeTypes := make(map[string]string)
+	for _, e := range exchanges {
+		exchangeTypes[graphNodeKey(e.Vhost, rabbitmq.DestinationExchange, e.Name)] = e.Type
+	}
+
+	g := &bindingGraph{nodes: make(map[string][]graphNode), ids: make(map[string]string)}
+	addNode := func(vhost, kind, name string) {
+		key := graphNodeKey(vhost, kind, name)
+		if _, ok := g.ids[key]; ok {
+			return
+		}
+		g.ids[key] = fmt.Sprintf("n%d", len(g.ids)+1)
+
+		node := graphNode{key: key, label: exchangeDisplayName(name), queue: kind == rabbitmq.DestinationQueue}
+		if t := exchangeTypes[key]; !node.queue && t != "" {
+			node.label = fmt.Sprintf("%s (%s)", node.label, t)
+		}
+		if _, ok := g.nodes[vhost]; !ok {
+			g.vhosts = append(g.vhosts, vhost)
+		}
+		g.nodes[vhost] = append(g.nodes[vhost], node)
+	}
+
+	sorted := append([]rabbitmq.Binding(nil), bindings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Vhost != b.Vhost {
+			return a.Vhost < b.Vhost
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Destination != b.Destination {
+			return a.Destination < b.Destination
+		}
+		return a.RoutingKey < b.RoutingKey
+	})
+
+	for _, b := range sorted {
+		addNode(b.Vhost, rabbitmq.DestinationExchange, b.Source)
+		addNode(b.Vhost, b.DestinationType, b.Destination)
+		g.edges = append(g.edges, b)
+	}
+	sort.Strings(g.vhosts)
+	return g
+}
+
+func (g *bindingGraph) edgeIDs(b rabbitmq.Binding) (string, string) {
+	return g.ids[graphNodeKey(b.Vhost, rabbitmq.DestinationExchange, b.Source)],
+		g.ids[graphNodeKey(b.Vhost, b.DestinationType, b.Destination)]
+}
+
+func (g *bindingGraph) writeDOT(w io.Writer) {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	fmt.Fprintln(w, "digraph bindings {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for i, vhost := range g.vhosts {
+		fmt.Fprintf(w, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(w, "    label=\"vhost %s\";\n", quote.Replace(vhost))
+		for _, node := range g.nodes[vhost] {
+			shape := "box"
+			if node.queue {
+				shape = "ellipse"
+			}
+			fmt.Fprintf(w, "    %s [label=\"%s\", shape=%s];\n", g.ids[node.key], quote.Replace(node.label), shape)
+		}
+		fmt.Fprintln(w, "  }")
+	}
+	for _, b := range g.edges {
+		from, to := g.edgeIDs(b)
+		if b.RoutingKey == "" {
+			fmt.Fprintf(w, "  %s -> %s;\n", from, to)
+		} else {
+			fmt.Fprintf(w, "  %s -> %s [label=\"%s\"];\n", from, to, quote.Replace(b.RoutingKey))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *bindingGraph) writeMermaid(w io.Writer) {
+	quote := strings.NewReplacer(`"`, "#quot;")
+
+	fmt.Fprintln(w, "flowchart LR")
+	for i, vhost := range g.vhosts {
+		fmt.Fprintf(w, "  subgraph vhost_%d[\"vhost %s\"]\n", i, quote.Replace(vhost))
+		for _, node := range g.nodes[vhost] {
+			if node.queue {
+				fmt.Fprintf(w, "    %s([\"%s\"])\n", g.ids[node.key], quote.Replace(node.label))
+			} else {
+				fmt.Fprintf(w, "    %s[\"%s\"]\n", g.ids[node.key], quote.Replace(node.label))
+			}
+		}
+		fmt.Fprintln(w, "  end")
+	}
+	for _, b := range g.edges {
+		from, to := g.edgeIDs(b)
+		if b.RoutingKey == "" {
+			fmt.Fprintf(w, "  %s --> %s\n", from, to)
+		} else {
+			fmt.Fprintf(w, "  %s -->|\"%s\"| %s\n", from, quote.Replace(b.RoutingKey), to)
+		}
+	}
+}
+
+func completeDestinationTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{rabbitmq.DestinationQueue, rabbitmq.DestinationExchange}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		instanceBindingsListCmd, instanceBindingsCreateCmd,
+		instanceBindingsDeleteCmd, instanceBindingsGraphCmd,
+	} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instanceBindingsListCmd.Flags().String("vhost", "", "Only list bindings in this vhost")
+	instanceBindingsListCmd.Flags().String("source", "", "Only list bindings from this exchange")
+	instanceBindingsListCmd.Flags().String("destination", "", "Only list bindings to this queue or exchange")
+	instanceBindingsListCmd.Flags().Bool("include-default", false, "Include bindings from the default exchange")
+
+	for _, cmd := range []*cobra.Command{instanceBindingsCreateCmd, instanceBindingsDeleteCmd} {
+		cmd.Flags().String("vhost", "", "Vhost of the binding (default: vhost of the instance URL)")
+		cmd.Flags().String("source", "", "Source exchange (required)")
+		cmd.Flags().String("destination", "", "Destination queue or exchange (required)")
+		cmd.Flags().String("destination-type", rabbitmq.DestinationQueue, "Destination type (queue or exchange)")
+		cmd.Flags().String("routing-key", "", "Routing key")
+		cmd.Flags().String("arguments", "", "Binding arguments as a JSON object")
+		cmd.MarkFlagRequired("source")
+		cmd.MarkFlagRequired("destination")
+		cmd.RegisterFlagCompletionFunc("destination-type", completeDestinationTypes)
+	}
+
+	instanceBindingsGraphCmd.Flags().String("vhost", "", "Only render bindings in this vhost")
+	instanceBindingsGraphCmd.Flags().String("format", "dot", "Output format (dot or mermaid)")
+	instanceBindingsGraphCmd.Flags().Bool("include-default", false, "Include bindings from the default exchange")
+	instanceBindingsGraphCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"dot", "mermaid"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	instanceBindingsCmd.AddCommand(instanceBindingsListCmd)
+	instanceBindingsCmd.AddCommand(instanceBindingsCreateCmd)
+	instanceBindingsCmd.AddCommand(instanceBindingsDeleteCmd)
+	instanceBindingsCmd.AddCommand(instanceBindingsGraphCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"cloudamqp-cli/rabbitmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBindings() ([]rabbitmq.Exchange, []rabbitmq.Binding) {
+	exchanges := []rabbitmq.Exchange{
+		{Name: "orders", Vhost: "/", Type: "topic"},
+		{Name: "audit", Vhost: "/", Type: "fanout"},
+	}
+	bindings := []rabbitmq.Binding{
+		{Source: "", Vhost: "/", Destination: "orders.eu", DestinationType: "queue", RoutingKey: "orders.eu"},
+		{Source: "orders", Vhost: "/", Destination: "orders.eu", DestinationType: "queue", RoutingKey: "eu.#", PropertiesKey: "eu.%23"},
+		{Source: "orders", Vhost: "/", Destination: "audit", DestinationType: "exchange"},
+	}
+	return exchanges, bindings
+}
+
+func TestBindingGraph_DOT(t *testing.T) {
+	exchanges, bindings := testBindings()
+
+	var out bytes.Buffer
+	newBindingGraph(exchanges, filterBindings(bindings, false)).writeDOT(&out)
+
+	assert.Equal(t, `digraph bindings {
+  rankdir=LR;
+  subgraph cluster_0 {
+    label="vhost /";
+    n1 [label="orders (topic)", shape=box];
+    n2 [label="audit (fanout)", shape=box];
+    n3 [label="orders.eu", shape=ellipse];
+  }
+  n1 -> n2;
+  n1 -> n3 [label="eu.#"];
+}
+`, out.String())
+}
+
+func TestBindingGraph_Mermaid(t *testing.T) {
+	exchanges, bindings := testBindings()
+
+	var out bytes.Buffer
+	newBindingGraph(exchanges, filterBindings(bindings, true)).writeMermaid(&out)
+
+	assert.Equal(t, `flowchart LR
+  subgraph vhost_0["vhost /"]
+    n1["(AMQP default)"]
+    n2(["orders.eu"])
+    n3["orders (topic)"]
+    n4["audit (fanout)"]
+  end
+  n1 -->|"orders.eu"| n2
+  n3 --> n4
+  n3 -->|"eu.#"| n2
+`, out.String())
+}
+
+func TestFindBinding(t *testing.T) {
+	bindings := []rabbitmq.Binding{
+		{RoutingKey: "eu", PropertiesKey: "eu~1", Arguments: map[string]any{"x-match": "all"}},
+		{RoutingKey: "eu", PropertiesKey: "eu~2", Arguments: map[string]any{"x-match": "any"}},
+		{RoutingKey: "us", PropertiesKey: "us"},
+	}
+
+	b, err := findBinding(bindings, "us", nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "us", b.PropertiesKey)
+
+	_, err = findBinding(bindings, "eu", nil, false)
+	assert.Error(t, err)
+
+	b, err = findBinding(bindings, "eu", map[string]any{"x-match": "any"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu~2", b.PropertiesKey)
+
+	_, err = findBinding(bindings, "asia", nil, false)
+	assert.Error(t, err)
+}
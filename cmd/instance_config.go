@@ -5,7 +5,6 @@ import (
 	"strconv"
 	"strings"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
@@ -37,12 +36,11 @@ var instanceConfigListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		config, err := c.GetRabbitMQConfig(idFlag)
 		if err != nil {
-			fmt.Printf("Error getting configuration: %v\n", err)
-			return err
+			return commandError("getting configuration", err)
 		}
 
 		if len(config) == 0 {
@@ -87,12 +85,11 @@ var instanceConfigGetCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		config, err := c.GetRabbitMQConfig(idFlag)
 		if err != nil {
-			fmt.Printf("Error getting configuration: %v\n", err)
-			return err
+			return commandError("getting configuration", err)
 		}
 
 		if value, exists := config[settingName]; exists {
@@ -127,7 +124,7 @@ var instanceConfigSetCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		// Convert string value to appropriate type
 		var value interface{}
@@ -151,8 +148,7 @@ var instanceConfigSetCmd = &cobra.Command{
 
 		err = c.UpdateRabbitMQConfig(idFlag, config)
 		if err != nil {
-			fmt.Printf("Error updating configuration: %v\n", err)
-			return err
+			return commandError("updating configuration", err)
 		}
 
 		fmt.Printf("Configuration setting '%s' updated to: %v\n", settingName, value)
@@ -52,7 +52,7 @@ Optional flags:
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		req := &client.InstanceCreateRequest{
 			Name:   instanceName,
@@ -86,8 +86,7 @@ Optional flags:
 
 		resp, err := c.CreateInstance(req)
 		if err != nil {
-			fmt.Printf("Error creating instance: %v\n", err)
-			return err
+			return commandError("creating instance", err)
 		}
 
 		if instanceWait {
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceDefinitionsCmd = &cobra.Command{
+	Use:   "definitions",
+	Short: "Export and import broker definitions",
+	Long: `Export and import the broker topology (vhosts, users, permissions, policies,
+parameters, exchanges, queues and bindings) through the RabbitMQ management API.
+
+Use export on one instance and import on another to promote topology between
+environments, e.g. from staging to production.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceDefinitionsExportCmd = &cobra.Command{
+	Use:   "export --id <instance_id>",
+	Short: "Export definitions",
+	Long: `Exports the definitions of the instance as JSON to --file, or to stdout.
+
+Password hashes of users are removed unless --include-password-hashes is set.
+Users without a password hash are skipped on import.`,
+	Example: `  cloudamqp instance definitions export --id 1234 --file staging.json
+  cloudamqp instance definitions export --id 1234 --vhost=orders --exclude-users --exclude-permissions`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		includeHashes, _ := cmd.Flags().GetBool("include-password-hashes")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		defs, err := mgmt.GetDefinitions()
+		if err != nil {
+			return commandError("exporting definitions", err)
+		}
+
+		defs = defs.Filter(definitionsFilter(cmd))
+		if !includeHashes {
+			defs.ScrubPasswordHashes()
+		}
+
+		output, err := json.MarshalIndent(defs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format definitions: %v", err)
+		}
+
+		if file == "" || file == "-" {
+			fmt.Println(string(output))
+			return nil
+		}
+
+		if err := os.WriteFile(file, append(output, '\n'), 0600); err != nil {
+			return fmt.Errorf("failed to write definitions: %w", err)
+		}
+		fmt.Printf("Definitions exported to %s.\n", file)
+		return nil
+	},
+}
+
+var instanceDefinitionsImportCmd = &cobra.Command{
+	Use:   "import --id <instance_id> --file <file>",
+	Short: "Import definitions",
+	Long: `Imports definitions from --file, or from stdin with --file=-.
+
+Objects in the file are created or updated, objects that only exist on the
+instance are left untouched. Users without a password hash, e.g. from an export
+without --include-password-hashes, are skipped with their permissions so that
+existing passwords are not reset.
+
+Use --diff to preview what the import would create or update without changing
+anything.`,
+	Example: `  cloudamqp instance definitions import --id 5678 --file staging.json --diff
+  cloudamqp instance definitions import --id 5678 --file staging.json --vhost=orders
+  cloudamqp instance definitions export --id 1234 | cloudamqp instance definitions import --id 5678 --file - --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idFlag, _ := cmd.Flags().GetString("id")
+		file, _ := cmd.Flags().GetString("file")
+		diff, _ := cmd.Flags().GetBool("diff")
+
+		defs, err := readDefinitions(file)
+		if err != nil {
+			return err
+		}
+
+		filter := definitionsFilter(cmd)
+		defs = defs.Filter(filter)
+		if skipped := defs.UsersWithoutPasswordHash(); len(skipped) > 0 {
+			fmt.Fprintf(os.Stderr, "Skipping users without password hash and their permissions: %s\n", strings.Join(skipped, ", "))
+			defs = defs.WithoutUsers(skipped)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		mgmt, err := newRabbitMQClient(c, idFlag)
+		if err != nil {
+			return err
+		}
+
+		if diff {
+			current, err := mgmt.GetDefinitions()
+			if err != nil {
+				return commandError("exporting current definitions", err)
+			}
+			printDefinitionChanges(rabbitmq.DiffDefinitions(current.Filter(filter), defs))
+			return nil
+		}
+
+		confirmed, err := confirmInstanceAction(cmd, c, idFlag, "import definitions from "+file+" into")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Import cancelled.")
+			return nil
+		}
+
+		if err := mgmt.ImportDefinitions(defs); err != nil {
+			return commandError("importing definitions", err)
+		}
+
+		fmt.Printf("Definitions imported into instance %s.\n", idFlag)
+		return nil
+	},
+}
+
+func definitionsFilter(cmd *cobra.Command) rabbitmq.DefinitionsFilter {
+	vhosts, _ := cmd.Flags().GetStringSlice("vhost")
+	excludeUsers, _ := cmd.Flags().GetBool("exclude-users")
+	excludePermissions, _ := cmd.Flags().GetBool("exclude-permissions")
+	return rabbitmq.DefinitionsFilter{
+		Vhosts:             vhosts,
+		ExcludeUsers:       excludeUsers,
+		ExcludePermissions: excludePermissions,
+	}
+}
+
+func readDefinitions(file string) (*rabbitmq.Definitions, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read definitions: %w", err)
+	}
+
+	var defs rabbitmq.Definitions
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("invalid definitions file: %w", err)
+	}
+	return &defs, nil
+}
+
+func printDefinitionChanges(changes []rabbitmq.DefinitionChange) {
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return
+	}
+
+	created, updated := 0, 0
+	for _, change := range changes {
+		fmt.Println(change)
+		if change.Action == rabbitmq.ChangeCreate {
+			created++
+		} else {
+			updated++
+		}
+	}
+	fmt.Printf("\n%d to create, %d to update.\n", created, updated)
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{instanceDefinitionsExportCmd, instanceDefinitionsImportCmd} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.Flags().StringSlice("vhost", []string{}, "Only include objects in these vhosts (can be specified multiple times)")
+		cmd.Flags().Bool("exclude-users", false, "Leave out users")
+		cmd.Flags().Bool("exclude-permissions", false, "Leave out permissions and topic permissions")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instanceDefinitionsExportCmd.Flags().String("file", "", "File to write the definitions to (default: stdout)")
+	instanceDefinitionsExportCmd.Flags().Bool("include-password-hashes", false, "Keep the password hashes of users")
+
+	instanceDefinitionsImportCmd.Flags().String("file", "", "File to read the definitions from, - for stdin (required)")
+	instanceDefinitionsImportCmd.Flags().Bool("diff", false, "Show what the import would change without importing")
+	instanceDefinitionsImportCmd.MarkFlagRequired("file")
+	addConfirmFlags(instanceDefinitionsImportCmd)
+
+	instanceDefinitionsCmd.AddCommand(instanceDefinitionsExportCmd)
+	instanceDefinitionsCmd.AddCommand(instanceDefinitionsImportCmd)
+}
@@ -1,19 +1,14 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strconv"
-	"strings"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
 var (
 	deleteInstanceID string
-	forceDelete      bool
 )
 
 var instanceDeleteCmd = &cobra.Command{
@@ -21,9 +16,13 @@ var instanceDeleteCmd = &cobra.Command{
 	Short: "Delete a CloudAMQP instance",
 	Long: `Delete a CloudAMQP instance permanently.
 
-WARNING: This action cannot be undone. All data will be lost.`,
+WARNING: This action cannot be undone. All data will be lost.
+
+Protected instances (see --override-protection) cannot be deleted unless the
+protection is overridden and the instance name is typed to confirm.`,
 	Example: `  cloudamqp instance delete --id 1234
-  cloudamqp instance delete --id 1234 --force`,
+  cloudamqp instance delete --id 1234 --force
+  cloudamqp instance delete --id 1234 --force --wait`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
@@ -41,37 +40,36 @@ WARNING: This action cannot be undone. All data will be lost.`,
 			return fmt.Errorf("invalid instance ID: %v", err)
 		}
 
-		if !forceDelete {
-			fmt.Printf("Are you sure you want to delete instance %d? This action cannot be undone. (y/N): ", instanceID)
-			reader := bufio.NewReader(os.Stdin)
-			response, err := reader.ReadString('\n')
-			if err != nil {
-				return fmt.Errorf("failed to read confirmation: %v", err)
-			}
+		c := newClient(apiKey)
 
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Delete operation cancelled.")
-				return nil
-			}
+		if err := checkInstanceProtection(cmd, c, instanceID, "delete"); err != nil {
+			return err
 		}
 
-		c := client.New(apiKey, Version)
+		confirmed, err := confirmInstanceAction(cmd, c, deleteInstanceID, "permanently delete")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Delete operation cancelled.")
+			return nil
+		}
 
 		err = c.DeleteInstance(instanceID)
 		if err != nil {
-			fmt.Printf("Error deleting instance: %v\n", err)
-			return err
+			return commandError("deleting instance", err)
 		}
 
 		fmt.Printf("Instance %d deleted successfully.\n", instanceID)
-		return nil
+		return waitIfRequested(cmd, c, deleteInstanceID, "instance-deleted", nil, nil)
 	},
 }
 
 func init() {
 	instanceDeleteCmd.Flags().StringVar(&deleteInstanceID, "id", "", "Instance ID (required)")
-	instanceDeleteCmd.Flags().BoolVar(&forceDelete, "force", false, "Skip confirmation prompt")
+	addConfirmFlags(instanceDeleteCmd)
+	addWaitFlags(instanceDeleteCmd, "15m")
+	addProtectionFlag(instanceDeleteCmd)
 	instanceDeleteCmd.MarkFlagRequired("id")
 	instanceDeleteCmd.RegisterFlagCompletionFunc("id", completeInstances)
 }
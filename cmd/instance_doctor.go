@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/internal/doctor"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceDoctorCmd = &cobra.Command{
+	Use:   "doctor --id <instance_id>",
+	Short: "Diagnose connectivity to an instance",
+	Long: `Runs a checklist of connectivity checks against an instance and prints a
+pass/fail report with hints on how to fix failures:
+
+  dns         The external hostname resolves
+  tcp/<port>  AMQP (5672), AMQPS (5671), MQTT (1883), MQTTS (8883) and HTTPS
+              (443) are reachable. MQTT failures are warnings.
+  tls         The certificate chain on 5671 verifies and does not expire soon
+  amqp        An AMQP handshake with the instance URL credentials succeeds
+  management  The management API answers with the instance URL credentials
+
+The command exits with an error when any check fails.`,
+	Example: `  cloudamqp instance doctor --id 1234
+  cloudamqp instance doctor --id 1234 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idFlag, _ := cmd.Flags().GetString("id")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		timeoutFlag, _ := cmd.Flags().GetString("timeout")
+
+		timeout, err := time.ParseDuration(timeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid timeout value: %v", err)
+		}
+
+		instanceID, err := strconv.Atoi(idFlag)
+		if err != nil {
+			return fmt.Errorf("invalid instance ID: %v", err)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		instance, err := c.GetInstance(instanceID)
+		if err != nil {
+			return commandError("getting instance", err)
+		}
+		if instance.HostnameExternal == "" {
+			return fmt.Errorf("instance %d has no external hostname, it may not be ready yet", instanceID)
+		}
+
+		checker := doctor.New(instance.HostnameExternal)
+		checker.Timeout = timeout
+		checker.AMQP = func(ctx context.Context) (string, error) {
+			conn, err := rabbitmq.DialAMQPContext(ctx, instance.URL, Version)
+			if err != nil {
+				return "", err
+			}
+			conn.Close()
+			return fmt.Sprintf("AMQP handshake succeeded as %s", amqpIdentity(instance.URL)), nil
+		}
+		checker.Management = func(ctx context.Context) (string, error) {
+			mgmt, err := rabbitmq.NewFromURL(instance.URL, Version)
+			if err != nil {
+				return "", err
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				mgmt.SetTimeout(time.Until(deadline))
+			}
+			overview, err := mgmt.GetOverview()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("management API answers, RabbitMQ %s, cluster %s", overview.RabbitMQVersion, overview.ClusterName), nil
+		}
+
+		results := checker.Run(context.Background())
+		failed := doctor.Failed(results)
+
+		if jsonOutput {
+			report := struct {
+				InstanceID int             `json:"instance_id"`
+				Hostname   string          `json:"hostname"`
+				Passed     bool            `json:"passed"`
+				Checks     []doctor.Result `json:"checks"`
+			}{instanceID, instance.HostnameExternal, failed == 0, results}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return fmt.Errorf("failed to format report: %v", err)
+			}
+		} else {
+			printDoctorReport(instance.Name, instance.HostnameExternal, results)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// amqpIdentity describes the user and vhost of an instance URL without the password
+func amqpIdentity(instanceURL string) string {
+	u, err := url.Parse(instanceURL)
+	if err != nil || u.User == nil {
+		return "instance user"
+	}
+	vhost := strings.TrimPrefix(u.Path, "/")
+	if vhost == "" {
+		vhost = "/"
+	}
+	return fmt.Sprintf("user %s on vhost %s", u.User.Username(), vhost)
+}
+
+func printDoctorReport(name, hostname string, results []doctor.Result) {
+	fmt.Printf("Checking %s (%s)\n\n", name, hostname)
+
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+		fmt.Printf("%-4s  %-10s  %s\n", strings.ToUpper(r.Status), r.Name, r.Message)
+		if r.Hint != "" {
+			fmt.Printf("      %-10s  hint: %s\n", "", r.Hint)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed, %d warnings, %d skipped\n",
+		counts[doctor.StatusPass], counts[doctor.StatusFail], counts[doctor.StatusWarn], counts[doctor.StatusSkip])
+}
+
+func init() {
+	instanceDoctorCmd.Flags().StringP("id", "", "", "Instance ID (required)")
+	instanceDoctorCmd.Flags().Bool("json", false, "Print the report as JSON")
+	instanceDoctorCmd.Flags().String("timeout", "5s", "Timeout for each check")
+	instanceDoctorCmd.MarkFlagRequired("id")
+	instanceDoctorCmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+}
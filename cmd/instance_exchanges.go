@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceExchangesCmd = &cobra.Command{
+	Use:   "exchanges",
+	Short: "Manage exchanges",
+	Long: `List, declare, and delete exchanges through the RabbitMQ management API.
+
+The management API is reached with the credentials of the instance URL.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceExchangesListCmd = &cobra.Command{
+	Use:   "list --id <instance_id>",
+	Short: "List exchanges",
+	Long:  `Lists exchanges in all vhosts, or in the vhost given by --vhost.`,
+	Example: `  cloudamqp instance exchanges list --id 1234
+  cloudamqp instance exchanges list --id 1234 --vhost=myvhost --type=topic`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vhost, _ := cmd.Flags().GetString("vhost")
+		exchangeType, _ := cmd.Flags().GetString("type")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		exchanges, err := mgmt.ListExchanges(vhost)
+		if err != nil {
+			return commandError("listing exchanges", err)
+		}
+
+		var matched []rabbitmq.Exchange
+		for _, e := range exchanges {
+			if exchangeType == "" || e.Type == exchangeType {
+				matched = append(matched, e)
+			}
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].Vhost != matched[j].Vhost {
+				return matched[i].Vhost < matched[j].Vhost
+			}
+			return matched[i].Name < matched[j].Name
+		})
+
+		if len(matched) == 0 {
+			fmt.Println("No exchanges found.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "VHOST", "NAME", "TYPE", "DURABLE", "AUTO_DELETE", "INTERNAL")
+		for _, e := range matched {
+			t.AddRow(e.Vhost, exchangeDisplayName(e.Name), e.Type, yesNo(e.Durable), yesNo(e.AutoDelete), yesNo(e.Internal))
+		}
+		t.Print()
+
+		return nil
+	},
+}
+
+var instanceExchangesDeclareCmd = &cobra.Command{
+	Use:   "declare <exchange_name> --id <instance_id>",
+	Short: "Declare an exchange",
+	Long: `Declares an exchange. Declaring an existing exchange with the same settings
+has no effect, declaring it with different settings fails.
+
+The vhost defaults to the vhost of the instance URL. Arguments are given as a
+JSON object.`,
+	Example: `  cloudamqp instance exchanges declare orders --id 1234 --type=topic
+  cloudamqp instance exchanges declare orders.dlx --id 1234 --type=fanout --durable=false --auto-delete
+  cloudamqp instance exchanges declare events --id 1234 --type=headers --arguments='{"alternate-exchange": "unrouted"}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		exchangeType, _ := cmd.Flags().GetString("type")
+		durable, _ := cmd.Flags().GetBool("durable")
+		autoDelete, _ := cmd.Flags().GetBool("auto-delete")
+		internal, _ := cmd.Flags().GetBool("internal")
+		argumentsFlag, _ := cmd.Flags().GetString("arguments")
+
+		arguments, err := parseArguments(argumentsFlag)
+		if err != nil {
+			return err
+		}
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		settings := rabbitmq.ExchangeSettings{
+			Type:       exchangeType,
+			Durable:    durable,
+			AutoDelete: autoDelete,
+			Internal:   internal,
+			Arguments:  arguments,
+		}
+		if err := mgmt.DeclareExchange(vhost, name, settings); err != nil {
+			return commandError("declaring exchange", err)
+		}
+
+		fmt.Printf("Exchange %s declared in vhost %s.\n", name, vhost)
+		return nil
+	},
+}
+
+var instanceExchangesDeleteCmd = &cobra.Command{
+	Use:   "delete <exchange_name> --id <instance_id>",
+	Short: "Delete an exchange",
+	Long: `Deletes an exchange and all bindings from it.
+
+Use --if-unused to only delete the exchange when it has no bindings. The vhost
+defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance exchanges delete orders --id 1234
+  cloudamqp instance exchanges delete orders --id 1234 --vhost=myvhost --if-unused`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		ifUnused, _ := cmd.Flags().GetBool("if-unused")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		if err := mgmt.DeleteExchange(vhost, name, ifUnused); err != nil {
+			return commandError("deleting exchange", err)
+		}
+
+		fmt.Printf("Exchange %s deleted.\n", name)
+		return nil
+	},
+}
+
+// exchangeDisplayName returns the name shown for an exchange, where the
+// default exchange has an empty name
+func exchangeDisplayName(name string) string {
+	if name == "" {
+		return "(AMQP default)"
+	}
+	return name
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+func completeExchangeTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"direct", "fanout", "topic", "headers"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		instanceExchangesListCmd, instanceExchangesDeclareCmd, instanceExchangesDeleteCmd,
+	} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instanceExchangesListCmd.Flags().String("vhost", "", "Only list exchanges in this vhost")
+	instanceExchangesListCmd.Flags().String("type", "", "Only list exchanges of this type")
+	instanceExchangesListCmd.RegisterFlagCompletionFunc("type", completeExchangeTypes)
+
+	instanceExchangesDeclareCmd.Flags().String("vhost", "", "Vhost of the exchange (default: vhost of the instance URL)")
+	instanceExchangesDeclareCmd.Flags().String("type", "direct", "Exchange type (direct, fanout, topic, headers)")
+	instanceExchangesDeclareCmd.Flags().Bool("durable", true, "Survive broker restarts")
+	instanceExchangesDeclareCmd.Flags().Bool("auto-delete", false, "Delete the exchange when its last binding is removed")
+	instanceExchangesDeclareCmd.Flags().Bool("internal", false, "Only allow publishing from other exchanges")
+	instanceExchangesDeclareCmd.Flags().String("arguments", "", "Exchange arguments as a JSON object")
+	instanceExchangesDeclareCmd.RegisterFlagCompletionFunc("type", completeExchangeTypes)
+
+	instanceExchangesDeleteCmd.Flags().String("vhost", "", "Vhost of the exchange (default: vhost of the instance URL)")
+	instanceExchangesDeleteCmd.Flags().Bool("if-unused", false, "Only delete the exchange if it has no bindings")
+
+	instanceExchangesCmd.AddCommand(instanceExchangesListCmd)
+	instanceExchangesCmd.AddCommand(instanceExchangesDeclareCmd)
+	instanceExchangesCmd.AddCommand(instanceExchangesDeleteCmd)
+}
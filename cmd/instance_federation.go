@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+// federationPlugins are the plugins needed to create and monitor federation links
+var federationPlugins = []string{"rabbitmq_federation", "rabbitmq_federation_management"}
+
+var instanceFederationCmd = &cobra.Command{
+	Use:   "federation",
+	Short: "Link instances with federation",
+	Long: `Set up federation between instances, so that exchanges or queues on one
+instance receive messages published to another.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceFederationLinkCmd = &cobra.Command{
+	Use:   "link --from-id <instance_id> --to-id <instance_id>",
+	Short: "Federate from one instance to another",
+	Long: `Creates a federation upstream on the downstream instance (--to-id) that points
+to the upstream instance (--from-id). The rabbitmq_federation and
+rabbitmq_federation_management plugins are enabled on the downstream instance
+first if needed.
+
+With --pattern a policy named federate-<name> is created that applies the
+upstream to all exchanges (or queues, with --apply-to=queues) matching the
+pattern. Only one policy applies to an exchange or queue, so give the policy
+a higher --priority than other policies matching the same names.
+
+Both instances are reached with the credentials and vhost of their instance
+URLs.`,
+	Example: `  cloudamqp instance federation link --from-id 1234 --to-id 5678
+  cloudamqp instance federation link --from-id 1234 --to-id 5678 --pattern='^events\.'
+  cloudamqp instance federation link --from-id 1234 --to-id 5678 --pattern='^orders$' --apply-to=queues --priority=10`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		pattern, _ := cmd.Flags().GetString("pattern")
+		applyTo, _ := cmd.Flags().GetString("apply-to")
+		priority, _ := cmd.Flags().GetInt("priority")
+		pluginTimeoutFlag, _ := cmd.Flags().GetString("plugin-timeout")
+
+		fromID, toID, err := instancePairFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if applyTo != "exchanges" && applyTo != "queues" {
+			return fmt.Errorf("invalid apply-to value %q, must be one of: exchanges, queues", applyTo)
+		}
+		pluginTimeout, err := time.ParseDuration(pluginTimeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid plugin-timeout value: %v", err)
+		}
+		if name == "" {
+			name = fmt.Sprintf("instance-%d", fromID)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		from, to, err := getInstancePair(c, fromID, toID)
+		if err != nil {
+			return commandError("getting instance", err)
+		}
+
+		if err := ensurePlugins(c, toID, pluginTimeout, federationPlugins...); err != nil {
+			return err
+		}
+
+		mgmt, err := managementClientFor(to)
+		if err != nil {
+			return err
+		}
+		vhost := mgmt.DefaultVhost()
+
+		upstream := rabbitmq.FederationUpstream{URI: from.URL, AckMode: rabbitmq.ShovelAckOnConfirm}
+		err = mgmt.SetFederationUpstream(vhost, name, upstream)
+		switch {
+		case err == nil:
+			fmt.Printf("Federation upstream %s created on instance %d, pointing to instance %d.\n", name, toID, fromID)
+		case !isDryRun(err):
+			return commandError("creating federation upstream", err)
+		}
+
+		if pattern == "" {
+			fmt.Println("No --pattern given, apply the upstream with a policy using the federation-upstream key.")
+			return nil
+		}
+
+		policy := rabbitmq.Policy{
+			Vhost:      vhost,
+			Name:       "federate-" + name,
+			Pattern:    pattern,
+			ApplyTo:    applyTo,
+			Priority:   priority,
+			Definition: map[string]any{"federation-upstream": name},
+		}
+		if err := mgmt.SetPolicy(rabbitmq.PolicyKindPolicy, policy); err != nil {
+			return commandError("creating federation policy", err)
+		}
+
+		fmt.Printf("Policy %s created, federating %s matching %s.\n", policy.Name, applyTo, pattern)
+		return nil
+	},
+}
+
+func init() {
+	instanceFederationLinkCmd.Flags().String("from-id", "", "Upstream instance ID (required)")
+	instanceFederationLinkCmd.Flags().String("to-id", "", "Downstream instance ID (required)")
+	instanceFederationLinkCmd.Flags().String("name", "", "Upstream name (default: instance-<from-id>)")
+	instanceFederationLinkCmd.Flags().String("pattern", "", "Create a policy applying the upstream to names matching this regular expression")
+	instanceFederationLinkCmd.Flags().String("apply-to", "exchanges", "What the policy applies to: exchanges or queues")
+	instanceFederationLinkCmd.Flags().Int("priority", 0, "Priority of the policy")
+	instanceFederationLinkCmd.Flags().String("plugin-timeout", "5m", "Timeout for enabling the federation plugins")
+	instanceFederationLinkCmd.MarkFlagRequired("from-id")
+	instanceFederationLinkCmd.MarkFlagRequired("to-id")
+	instanceFederationLinkCmd.RegisterFlagCompletionFunc("from-id", completeInstanceIDFlag)
+	instanceFederationLinkCmd.RegisterFlagCompletionFunc("to-id", completeInstanceIDFlag)
+
+	instanceFederationCmd.AddCommand(instanceFederationLinkCmd)
+}
@@ -6,21 +6,30 @@ import (
 	"strings"
 
 	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/watch"
 	"github.com/spf13/cobra"
 )
 
 var instanceGetCmd = &cobra.Command{
-	Use:     "get --id <id>",
-	Short:   "Get details of a specific CloudAMQP instance",
-	Long:    `Retrieves and displays detailed information about a specific CloudAMQP instance.`,
-	Example: `  cloudamqp instance get --id 1234`,
+	Use:   "get --id <id>",
+	Short: "Get details of a specific CloudAMQP instance",
+	Long: `Retrieves and displays detailed information about a specific CloudAMQP instance.
+
+With --watch the instance is polled every --watch-interval and changed fields
+are printed until the command is interrupted.`,
+	Example: `  cloudamqp instance get --id 1234
+  cloudamqp instance get --id 1234 --watch --watch-interval=5s`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		idFlag, _ := cmd.Flags().GetString("id")
 		if idFlag == "" {
 			return fmt.Errorf("instance ID is required. Use --id flag")
 		}
 
-		var err error
+		opts, watching, err := watchOptions(cmd, "instance")
+		if err != nil {
+			return err
+		}
+
 		apiKey, err = getAPIKey()
 		if err != nil {
 			return fmt.Errorf("failed to get API key: %w", err)
@@ -31,32 +40,51 @@ var instanceGetCmd = &cobra.Command{
 			return fmt.Errorf("invalid instance ID: %v", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		instance, err := c.GetInstance(instanceID)
 		if err != nil {
-			fmt.Printf("Error getting instance: %v\n", err)
-			return err
+			return commandError("getting instance", err)
 		}
 
-		// Format output as "Name = Value"
-		fmt.Printf("Name = %s\n", instance.Name)
-		fmt.Printf("Plan = %s\n", instance.Plan)
-		fmt.Printf("Region = %s\n", instance.Region)
-		fmt.Printf("Tags = %s\n", strings.Join(instance.Tags, ","))
-		fmt.Printf("Hostname = %s\n", instance.HostnameExternal)
-		ready := "No"
-		if instance.Ready {
-			ready = "Yes"
+		if !opts.JSON {
+			printInstance(instance)
+		}
+		if !watching {
+			return nil
 		}
-		fmt.Printf("Ready = %s\n", ready)
 
-		return nil
+		// A deleted instance is reported as removed
+		return runWatch(opts, []watch.Resource{instanceResource(*instance)}, func() ([]watch.Resource, error) {
+			instance, err := c.GetInstance(instanceID)
+			if client.IsNotFound(err) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return []watch.Resource{instanceResource(*instance)}, nil
+		})
 	},
 }
 
+func printInstance(instance *client.Instance) {
+	// Format output as "Name = Value"
+	fmt.Printf("Name = %s\n", instance.Name)
+	fmt.Printf("Plan = %s\n", instance.Plan)
+	fmt.Printf("Region = %s\n", instance.Region)
+	fmt.Printf("Tags = %s\n", strings.Join(instance.Tags, ","))
+	fmt.Printf("Hostname = %s\n", instance.HostnameExternal)
+	ready := "No"
+	if instance.Ready {
+		ready = "Yes"
+	}
+	fmt.Printf("Ready = %s\n", ready)
+}
+
 func init() {
 	instanceGetCmd.Flags().StringP("id", "", "", "Instance ID (required)")
 	instanceGetCmd.MarkFlagRequired("id")
 	instanceGetCmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	addWatchFlags(instanceGetCmd)
 }
@@ -7,46 +7,76 @@ import (
 
 	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/internal/watch"
 	"github.com/spf13/cobra"
 )
 
 var instanceListCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List all CloudAMQP instances",
-	Long:    `Retrieves and displays all CloudAMQP instances in your account.`,
-	Example: `  cloudamqp instance list`,
+	Use:   "list",
+	Short: "List all CloudAMQP instances",
+	Long: `Retrieves and displays all CloudAMQP instances in your account.
+
+With --watch the list is polled every --watch-interval and instances that are
+added, removed or changed are printed, e.g. to follow provisioning.`,
+	Example: `  cloudamqp instance list
+  cloudamqp instance list --watch
+  cloudamqp instance list --watch --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var err error
+		opts, watching, err := watchOptions(cmd, "instance")
+		if err != nil {
+			return err
+		}
+
 		apiKey, err = getAPIKey()
 		if err != nil {
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
-		instances, err := c.ListInstances()
+		// A watch starts from the current state, not a cached one
+		lister := c
+		if !watching {
+			lister = c.Cached()
+		}
+		instances, err := lister.ListInstances()
 		if err != nil {
-			fmt.Printf("Error listing instances: %v\n", err)
-			return err
+			return commandError("listing instances", err)
 		}
 
-		if len(instances) == 0 {
-			fmt.Println("No instances found.")
-			return nil
+		if !opts.JSON {
+			printInstanceList(instances)
 		}
-
-		// Create table and populate data
-		t := table.New(os.Stdout, "ID", "NAME", "PLAN", "REGION")
-		for _, instance := range instances {
-			t.AddRow(
-				strconv.Itoa(instance.ID),
-				instance.Name,
-				instance.Plan,
-				instance.Region,
-			)
+		if !watching {
+			return nil
 		}
-		t.Print()
 
-		return nil
+		return runWatch(opts, instanceResources(instances), func() ([]watch.Resource, error) {
+			instances, err := c.ListInstances()
+			return instanceResources(instances), err
+		})
 	},
 }
+
+func printInstanceList(instances []client.Instance) {
+	if len(instances) == 0 {
+		fmt.Println("No instances found.")
+		return
+	}
+
+	// Create table and populate data
+	t := table.New(os.Stdout, "ID", "NAME", "PLAN", "REGION")
+	for _, instance := range instances {
+		t.AddRow(
+			strconv.Itoa(instance.ID),
+			instance.Name,
+			instance.Plan,
+			instance.Region,
+		)
+	}
+	t.Print()
+}
+
+func init() {
+	addWatchFlags(instanceListCmd)
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/spf13/cobra"
+)
+
+var instancePublishCmd = &cobra.Command{
+	Use:   "publish --id <instance_id> --routing-key <key>",
+	Short: "Publish a message over AMQP",
+	Long: `Publishes a message to the instance over AMQP or AMQPS, using the instance URL.
+
+The message is published as mandatory and the command waits for the broker to
+confirm it, so it fails when the message could not be routed to any queue.
+Use --body @file to read the body from a file, or --body @- to read it from
+stdin. Headers are given as a JSON object.`,
+	Example: `  cloudamqp instance publish --id 1234 --routing-key=smoke-test --body='hello'
+  cloudamqp instance publish --id 1234 --exchange=orders --routing-key=eu.new --body @order.json --content-type=application/json
+  cloudamqp instance publish --id 1234 --exchange=events --body @- --headers='{"source": "ci"}' < event.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchange, _ := cmd.Flags().GetString("exchange")
+		routingKey, _ := cmd.Flags().GetString("routing-key")
+		bodyFlag, _ := cmd.Flags().GetString("body")
+		headersFlag, _ := cmd.Flags().GetString("headers")
+		contentType, _ := cmd.Flags().GetString("content-type")
+		messageID, _ := cmd.Flags().GetString("message-id")
+		persistent, _ := cmd.Flags().GetBool("persistent")
+		timeoutFlag, _ := cmd.Flags().GetString("timeout")
+
+		timeout, err := time.ParseDuration(timeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid timeout value: %v", err)
+		}
+
+		body, err := readMessageBody(bodyFlag)
+		if err != nil {
+			return err
+		}
+
+		headers, err := parseMessageHeaders(headersFlag)
+		if err != nil {
+			return err
+		}
+
+		msg := amqp.Publishing{
+			Headers:      headers,
+			ContentType:  contentType,
+			MessageId:    messageID,
+			Timestamp:    time.Now(),
+			DeliveryMode: amqp.Transient,
+			Body:         body,
+		}
+		if persistent {
+			msg.DeliveryMode = amqp.Persistent
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		conn, err := instanceAMQPClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if err := conn.Publish(ctx, exchange, routingKey, msg); err != nil {
+			return commandError("publishing message", err)
+		}
+
+		fmt.Printf("Message published to exchange %s with routing key %q.\n", exchangeDisplayName(exchange), routingKey)
+		return nil
+	},
+}
+
+var instanceConsumeCmd = &cobra.Command{
+	Use:   "consume --id <instance_id> --queue <queue>",
+	Short: "Consume messages over AMQP",
+	Long: `Consumes messages from a queue over AMQP or AMQPS, using the instance URL, and
+prints them as JSON lines including their properties.
+
+Ack modes:
+  requeue  Return the messages to the queue, leaving it unchanged (default)
+  ack      Acknowledge and thereby remove the messages
+  reject   Reject the messages without requeueing (dead-letters them if the
+           queue has a dead letter exchange)
+
+The command fails if fewer than --count messages arrive within --timeout.`,
+	Example: `  cloudamqp instance consume --id 1234 --queue=smoke-test
+  cloudamqp instance consume --id 1234 --queue=orders --count=10 --ack-mode=ack
+  cloudamqp instance consume --id 1234 --queue=orders --timeout=5s | jq .body`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queue, _ := cmd.Flags().GetString("queue")
+		count, _ := cmd.Flags().GetInt("count")
+		ackMode, _ := cmd.Flags().GetString("ack-mode")
+		timeoutFlag, _ := cmd.Flags().GetString("timeout")
+
+		if count < 1 {
+			return fmt.Errorf("count must be at least 1")
+		}
+		timeout, err := time.ParseDuration(timeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid timeout value: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		conn, err := instanceAMQPClient(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		encoder := json.NewEncoder(os.Stdout)
+		_, err = conn.Consume(ctx, queue, count, ackMode, func(d amqp.Delivery) error {
+			return encoder.Encode(rabbitmq.NewConsumedMessage(d))
+		})
+		return err
+	},
+}
+
+// parseMessageHeaders parses headers given as a JSON object. Nested objects
+// become nested tables, since amqp091 only accepts tables in field values,
+// and whole numbers stay integers, e.g. for x-delay.
+func parseMessageHeaders(value string) (amqp.Table, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var headers map[string]any
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&headers); err != nil {
+		return nil, fmt.Errorf("invalid headers, must be a JSON object: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid headers, must be a single JSON object")
+	}
+	table := amqpFieldValue(headers).(amqp.Table)
+	if err := table.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid headers: %v", err)
+	}
+	return table, nil
+}
+
+// amqpFieldValue converts a decoded JSON value to an AMQP field value
+func amqpFieldValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		table := make(amqp.Table, len(v))
+		for key, item := range v {
+			table[key] = amqpFieldValue(item)
+		}
+		return table
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = amqpFieldValue(item)
+		}
+		return items
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// readMessageBody returns the --body value, or the contents of the file it
+// names when it starts with @ (@- is stdin)
+func readMessageBody(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, "@") {
+		return []byte(value), nil
+	}
+
+	var data []byte
+	var err error
+	if value == "@-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(strings.TrimPrefix(value, "@"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return data, nil
+}
+
+func completeAckModes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{rabbitmq.AckModeRequeue, rabbitmq.AckModeAck, rabbitmq.AckModeReject}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{instancePublishCmd, instanceConsumeCmd} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instancePublishCmd.Flags().String("exchange", "", "Exchange to publish to (default: the default exchange)")
+	instancePublishCmd.Flags().String("routing-key", "", "Routing key")
+	instancePublishCmd.Flags().String("body", "", "Message body, @file to read it from a file or @- for stdin")
+	instancePublishCmd.Flags().String("headers", "", "Message headers as a JSON object")
+	instancePublishCmd.Flags().String("content-type", "", "Content type property")
+	instancePublishCmd.Flags().String("message-id", "", "Message ID property")
+	instancePublishCmd.Flags().Bool("persistent", true, "Publish as a persistent message")
+	instancePublishCmd.Flags().String("timeout", "30s", "Maximum time to connect and wait for the publisher confirm")
+
+	instanceConsumeCmd.Flags().String("queue", "", "Queue to consume from (required)")
+	instanceConsumeCmd.Flags().Int("count", 1, "Number of messages to consume")
+	instanceConsumeCmd.Flags().String("ack-mode", rabbitmq.AckModeRequeue, "What to do with consumed messages (requeue, ack, reject)")
+	instanceConsumeCmd.Flags().String("timeout", "30s", "Maximum time to connect and wait for the messages")
+	instanceConsumeCmd.MarkFlagRequired("queue")
+	instanceConsumeCmd.RegisterFlagCompletionFunc("ack-mode", completeAckModes)
+}
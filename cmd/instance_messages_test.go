@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessageHeaders(t *testing.T) {
+	headers, err := parseMessageHeaders(`{"source": "ci", "retry": 2, "trace": {"id": "abc", "spans": [{"name": "publish"}, 1]}}`)
+	require.NoError(t, err)
+	assert.NoError(t, headers.Validate())
+
+	trace, ok := headers["trace"].(amqp.Table)
+	require.True(t, ok, "nested objects must be tables")
+	assert.Equal(t, "abc", trace["id"])
+	spans := trace["spans"].([]any)
+	assert.Equal(t, amqp.Table{"name": "publish"}, spans[0])
+
+	headers, err = parseMessageHeaders("")
+	assert.NoError(t, err)
+	assert.Nil(t, headers)
+
+	_, err = parseMessageHeaders(`["not", "an", "object"]`)
+	assert.Error(t, err)
+	_, err = parseMessageHeaders(`{"source": "ci"} {"trailing": true}`)
+	assert.Error(t, err)
+}
+
+func TestParseMessageHeaders_Numbers(t *testing.T) {
+	headers, err := parseMessageHeaders(`{"x-delay": 5000, "ratio": 0.5, "big": 1e3, "nested": {"count": [1, 2]}}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5000), headers["x-delay"], "whole numbers must not become doubles")
+	assert.Equal(t, 0.5, headers["ratio"])
+	assert.Equal(t, float64(1000), headers["big"])
+	assert.Equal(t, []any{int64(1), int64(2)}, headers["nested"].(amqp.Table)["count"])
+}
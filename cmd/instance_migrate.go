@@ -0,0 +1,458 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/migrate"
+	"cloudamqp-cli/internal/wait"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceMigrateCmd = &cobra.Command{
+	Use:   "migrate --from-id <instance_id> [--to-plan <plan>] [--to-region <region>]",
+	Short: "Migrate an instance to a new plan or region",
+	Long: `Migrates an instance to a new instance on another plan or in another region
+without downtime:
+
+  1. Creates the target instance with the tags of the source and copies the
+     settings given by --copy-settings
+  2. Waits for the target to be ready
+  3. Exports the definitions of the source and imports them into the target
+  4. Creates a shovel on the target for every queue with messages, moving
+     the messages from the source queue to the same queue on the target
+  5. Prints the cutover steps and the drain progress of the source queues
+
+Only queues that have messages when the shovels are created get a shovel.
+Messages published to other queues on the source, e.g. during cutover, are
+not moved, so point all publishers at the target first.
+
+With --dry-run the create request of the target is printed and the
+migration stops there.
+
+Progress is kept in ~/.cloudamqp-migrations (or CLOUDAMQP_MIGRATION_DIR). Run
+the same command again to resume an interrupted migration, or to see the
+drain progress. After moving publishers and consumers to the target, run it
+with --wait-drained to wait until all source queues are empty and print the
+clean up steps.
+
+Settings can only be copied from dedicated instances, pass --copy-settings=""
+when migrating from a shared plan.`,
+	Example: `  cloudamqp instance migrate --from-id 1234 --to-plan bunny-1
+  cloudamqp instance migrate --from-id 1234 --to-region amazon-web-services::eu-west-1 --name my-app-eu
+  cloudamqp instance migrate --from-id 1234
+  cloudamqp instance migrate --from-id 1234 --wait-drained --drain-timeout 2h`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFlag, _ := cmd.Flags().GetString("from-id")
+		toPlan, _ := cmd.Flags().GetString("to-plan")
+		toRegion, _ := cmd.Flags().GetString("to-region")
+		name, _ := cmd.Flags().GetString("name")
+		copySettings, _ := cmd.Flags().GetStringSlice("copy-settings")
+		waitTimeoutFlag, _ := cmd.Flags().GetString("wait-timeout")
+		waitDrained, _ := cmd.Flags().GetBool("wait-drained")
+		drainTimeoutFlag, _ := cmd.Flags().GetString("drain-timeout")
+		reset, _ := cmd.Flags().GetBool("reset")
+
+		sourceID, err := strconv.Atoi(fromFlag)
+		if err != nil {
+			return fmt.Errorf("invalid instance ID: %v", err)
+		}
+		waitTimeout, err := time.ParseDuration(waitTimeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid wait-timeout value: %v", err)
+		}
+		drainTimeout, err := time.ParseDuration(drainTimeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid drain-timeout value: %v", err)
+		}
+
+		dir, err := migrate.Dir()
+		if err != nil {
+			return err
+		}
+		state, err := migrate.Load(dir, sourceID)
+		if err != nil {
+			return err
+		}
+		if state != nil && reset {
+			if err := state.Remove(); err != nil {
+				return err
+			}
+			fmt.Printf("Discarded migration state of instance %d. Instances and shovels created so far are kept.\n", sourceID)
+			state = nil
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		source, err := c.GetInstance(sourceID)
+		if err != nil {
+			return commandError("getting instance", err)
+		}
+
+		if state == nil {
+			if toPlan == "" && toRegion == "" {
+				return fmt.Errorf("no migration of instance %d in progress, give --to-plan and/or --to-region to start one", sourceID)
+			}
+			if toPlan == "" {
+				toPlan = source.Plan
+			}
+			if toRegion == "" {
+				toRegion = source.Region
+			}
+			if toPlan == source.Plan && toRegion == source.Region {
+				return fmt.Errorf("instance %d already runs plan %s in region %s", sourceID, toPlan, toRegion)
+			}
+			if name == "" {
+				name = source.Name + "-migrated"
+			}
+
+			action := fmt.Sprintf("create instance %s on plan %s in region %s and migrate instance %d (%s) to it",
+				name, toPlan, toRegion, sourceID, source.Name)
+			confirmed, err := confirmAction(cmd, action, "instance", source.Name)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				fmt.Println("Migration cancelled.")
+				return nil
+			}
+
+			state = migrate.New(dir, sourceID, name, toPlan, toRegion)
+		} else {
+			if (toPlan != "" && toPlan != state.TargetPlan) || (toRegion != "" && toRegion != state.TargetRegion) {
+				return fmt.Errorf("a migration of instance %d to plan %s in region %s is in progress, use --reset to start over",
+					sourceID, state.TargetPlan, state.TargetRegion)
+			}
+			fmt.Printf("Resuming migration of instance %d to instance %d (next step: %s).\n", sourceID, state.TargetID, state.Next())
+		}
+
+		m := &migration{c: c, state: state, source: source}
+		return m.run(copySettings, waitTimeout, waitDrained, drainTimeout)
+	},
+}
+
+// migration runs the steps of an instance migration, saving the state after
+// every step
+type migration struct {
+	c      *client.Client
+	state  *migrate.State
+	source *client.Instance
+	target *client.Instance
+}
+
+func (m *migration) step(step, description string) {
+	for i, s := range migrate.Steps {
+		if s == step {
+			fmt.Printf("[%d/%d] %s\n", i+1, len(migrate.Steps), description)
+		}
+	}
+}
+
+func (m *migration) run(copySettings []string, waitTimeout time.Duration, waitDrained bool, drainTimeout time.Duration) error {
+	if !m.state.Done(migrate.StepCreateTarget) {
+		err := m.createTarget(copySettings)
+		if isDryRun(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !m.state.Done(migrate.StepTargetReady) {
+		m.step(migrate.StepTargetReady, fmt.Sprintf("Waiting for instance %d to be ready...", m.state.TargetID))
+		if err := waitForInstanceReady(m.c, m.state.TargetID, waitTimeout); err != nil {
+			return fmt.Errorf("target instance not ready, run the command again to resume: %w", err)
+		}
+		if err := m.state.Complete(migrate.StepTargetReady); err != nil {
+			return err
+		}
+	}
+
+	target, err := m.c.GetInstance(m.state.TargetID)
+	if err != nil {
+		return fmt.Errorf("failed to get target instance: %w", err)
+	}
+	m.target = target
+
+	sourceMgmt, err := managementClientFor(m.source)
+	if err != nil {
+		return err
+	}
+	targetMgmt, err := managementClientFor(m.target)
+	if err != nil {
+		return err
+	}
+
+	if !m.state.Done(migrate.StepDefinitions) {
+		if err := m.copyDefinitions(sourceMgmt, targetMgmt); err != nil {
+			return err
+		}
+	}
+
+	if !m.state.Done(migrate.StepShovels) {
+		if err := m.createShovels(sourceMgmt, targetMgmt, waitTimeout); err != nil {
+			return err
+		}
+	}
+
+	if !waitDrained {
+		remaining, nonEmpty, err := m.drainStatus(sourceMgmt)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nDrain progress: %d messages left in %d of %d shoveled queues on instance %d.\n",
+			remaining, nonEmpty, len(m.state.Shovels), m.source.ID)
+		m.printCutoverSteps()
+		return nil
+	}
+
+	m.step(migrate.StepDrained, fmt.Sprintf("Waiting for the queues on instance %d to drain...", m.source.ID))
+	opts := wait.DefaultOptions(drainTimeout)
+	opts.Progress = printWaitProgress(fmt.Sprintf("instance %d", m.source.ID))
+	check := func() (bool, string, error) {
+		remaining, nonEmpty, err := m.drainStatus(sourceMgmt)
+		if err != nil {
+			return false, "", err
+		}
+		return remaining == 0, fmt.Sprintf("%d messages left in %d queues", remaining, nonEmpty), nil
+	}
+	if err := wait.Until(context.Background(), "drained", check, opts); err != nil {
+		return fmt.Errorf("queues not drained, run the command again to resume: %w", err)
+	}
+	if err := m.state.Complete(migrate.StepDrained); err != nil {
+		return err
+	}
+
+	m.printCleanupSteps()
+	return m.state.Remove()
+}
+
+func (m *migration) createTarget(copySettings []string) error {
+	m.step(migrate.StepCreateTarget, fmt.Sprintf("Creating instance %s on plan %s in region %s...",
+		m.state.TargetName, m.state.TargetPlan, m.state.TargetRegion))
+
+	req := &client.InstanceCreateRequest{
+		Name:   m.state.TargetName,
+		Plan:   m.state.TargetPlan,
+		Region: m.state.TargetRegion,
+		Tags:   m.source.Tags,
+	}
+	// A VPC only spans one region
+	if m.source.VPCID != nil && m.state.TargetRegion == m.source.Region {
+		req.VPCID = m.source.VPCID
+	}
+	if len(copySettings) > 0 {
+		req.CopySettings = &client.CopySettings{
+			SubscriptionID: m.source.ID,
+			Settings:       copySettings,
+		}
+	}
+
+	resp, err := m.c.CreateInstance(req)
+	if isDryRun(err) {
+		// Without a target instance there is nothing for the later steps to
+		// work on, so run stops on the dry-run result
+		fmt.Println("The remaining steps need the target instance and are not run in dry-run mode.")
+		return err
+	}
+	if err != nil {
+		return commandError("creating instance", err)
+	}
+
+	m.state.TargetID = resp.ID
+	if err := m.state.Complete(migrate.StepCreateTarget); err != nil {
+		return err
+	}
+	fmt.Printf("Created instance %d. Migration state saved to %s\n", resp.ID, m.state.Path())
+	return nil
+}
+
+// copyDefinitions copies the definitions of all vhosts when the source user
+// may export them, or of the source vhost into the target vhost otherwise
+// (e.g. on shared plans)
+func (m *migration) copyDefinitions(sourceMgmt, targetMgmt *rabbitmq.Client) error {
+	m.step(migrate.StepDefinitions, fmt.Sprintf("Copying definitions from instance %d to instance %d...", m.source.ID, m.target.ID))
+
+	vhosts := make(map[string]string)
+	defs, err := sourceMgmt.GetDefinitions()
+	switch {
+	case err == nil:
+		if skipped := defs.UsersWithoutPasswordHash(); len(skipped) > 0 {
+			fmt.Fprintf(os.Stderr, "Skipping users without password hash: %s\n", strings.Join(skipped, ", "))
+			defs = defs.WithoutUsers(skipped)
+		}
+		if err := targetMgmt.ImportDefinitions(defs); err != nil {
+			return commandError("importing definitions", err)
+		}
+
+		// The shovels publish to the target as the target instance user
+		targetUser := urlUsername(m.target.URL)
+		for _, vhost := range defs.VhostNames() {
+			vhosts[vhost] = vhost
+			if err := targetMgmt.SetPermissions(vhost, targetUser, ".*", ".*", ".*"); err != nil {
+				return fmt.Errorf("failed to grant %s access to vhost %s: %w", targetUser, vhost, err)
+			}
+		}
+	case rabbitmq.IsForbidden(err):
+		sourceVhost, targetVhost := sourceMgmt.DefaultVhost(), targetMgmt.DefaultVhost()
+		fmt.Printf("The source user may not export all definitions, copying vhost %s into vhost %s.\n", sourceVhost, targetVhost)
+
+		defs, err := sourceMgmt.GetVhostDefinitions(sourceVhost)
+		if err != nil {
+			return commandError("exporting definitions", err)
+		}
+		if err := targetMgmt.ImportVhostDefinitions(targetVhost, defs); err != nil {
+			return commandError("importing definitions", err)
+		}
+		vhosts[sourceVhost] = targetVhost
+	default:
+		return commandError("exporting definitions", err)
+	}
+
+	m.state.Vhosts = vhosts
+	return m.state.Complete(migrate.StepDefinitions)
+}
+
+// createShovels creates a shovel for every queue with messages in a migrated
+// vhost. Shovels are saved one by one, so a resumed run skips those created.
+func (m *migration) createShovels(sourceMgmt, targetMgmt *rabbitmq.Client, pluginTimeout time.Duration) error {
+	m.step(migrate.StepShovels, "Creating shovels for queues with messages...")
+
+	if err := ensurePlugins(m.c, m.target.ID, pluginTimeout, shovelPlugins...); err != nil {
+		return err
+	}
+
+	queues, err := sourceMgmt.ListQueues("")
+	if err != nil {
+		return commandError("listing queues", err)
+	}
+
+	for _, q := range queues {
+		targetVhost, migrated := m.state.Vhosts[q.Vhost]
+		if !migrated || q.Messages == 0 || q.Exclusive || m.state.HasShovel(q.Vhost, q.Name) {
+			continue
+		}
+
+		srcURI, err := uriWithVhost(m.source.URL, q.Vhost)
+		if err != nil {
+			return err
+		}
+		destURI, err := uriWithVhost(m.target.URL, targetVhost)
+		if err != nil {
+			return err
+		}
+
+		name := "migrate-" + q.Name
+		definition := queueShovelDefinition(srcURI, q.Name, destURI, q.Name, rabbitmq.ShovelDeleteNever)
+		if err := targetMgmt.CreateShovel(targetVhost, name, definition); err != nil {
+			return commandError(fmt.Sprintf("creating shovel for queue %s", q.Name), err)
+		}
+
+		m.state.Shovels = append(m.state.Shovels, migrate.Shovel{Vhost: q.Vhost, Queue: q.Name, Name: name})
+		if err := m.state.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("  shovel %s: %d messages in %s/%s\n", name, q.Messages, q.Vhost, q.Name)
+	}
+
+	if len(m.state.Shovels) == 0 {
+		fmt.Println("  no queues with messages, no shovels needed")
+	}
+	return m.state.Complete(migrate.StepShovels)
+}
+
+// drainStatus returns the number of messages left in the shoveled source
+// queues and how many of them are not empty
+func (m *migration) drainStatus(sourceMgmt *rabbitmq.Client) (int, int, error) {
+	queues, err := sourceMgmt.ListQueues("")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list source queues: %w", err)
+	}
+
+	remaining, nonEmpty := 0, 0
+	for _, q := range queues {
+		if m.state.HasShovel(q.Vhost, q.Name) && q.Messages > 0 {
+			remaining += q.Messages
+			nonEmpty++
+		}
+	}
+	return remaining, nonEmpty, nil
+}
+
+// printCutoverSteps prints how to move clients to the target. Only the queues
+// that had messages when the shovels were created are shoveled, so messages
+// published to any other queue on the source after that stay there.
+func (m *migration) printCutoverSteps() {
+	fmt.Printf(`
+Cutover steps:
+  1. Point your publishers at instance %d. Get its URL with:
+       cloudamqp instance get --id %d
+     Messages published to instance %d after this point are only moved to
+     instance %d if their queue has a shovel.
+  2. Point your consumers at instance %d. The shovels move the messages
+     queued on or still published to these queues on instance %d:
+`, m.target.ID, m.target.ID, m.source.ID, m.target.ID, m.target.ID, m.source.ID)
+	if len(m.state.Shovels) == 0 {
+		fmt.Println("       (none)")
+	}
+	for _, s := range m.state.Shovels {
+		fmt.Printf("       %s/%s\n", s.Vhost, s.Queue)
+	}
+	fmt.Printf(`  3. Wait until the queues on instance %d are drained:
+       cloudamqp instance migrate --from-id %d --wait-drained
+`, m.source.ID, m.source.ID)
+}
+
+func (m *migration) printCleanupSteps() {
+	fmt.Printf("\nMigration of instance %d to instance %d complete. Clean up with:\n", m.source.ID, m.target.ID)
+
+	shovels := append([]migrate.Shovel(nil), m.state.Shovels...)
+	sort.Slice(shovels, func(i, j int) bool { return shovels[i].Name < shovels[j].Name })
+	for _, s := range shovels {
+		fmt.Printf("  cloudamqp instance shovel delete %s --id %d --vhost %s\n", s.Name, m.target.ID, m.state.Vhosts[s.Vhost])
+	}
+	fmt.Printf("  cloudamqp instance delete --id %d\n", m.source.ID)
+}
+
+// urlUsername returns the username of an instance URL
+func urlUsername(instanceURL string) string {
+	u, err := url.Parse(instanceURL)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	return u.User.Username()
+}
+
+func init() {
+	instanceMigrateCmd.Flags().String("from-id", "", "Instance ID to migrate (required)")
+	instanceMigrateCmd.Flags().String("to-plan", "", "Plan of the new instance (default: plan of the source)")
+	instanceMigrateCmd.Flags().String("to-region", "", "Region of the new instance (default: region of the source)")
+	instanceMigrateCmd.Flags().String("name", "", "Name of the new instance (default: <source name>-migrated)")
+	instanceMigrateCmd.Flags().StringSlice("copy-settings", []string{"alarms", "metrics", "logs", "firewall", "config"}, "Settings to copy from the source")
+	instanceMigrateCmd.Flags().String("wait-timeout", "30m", "Timeout for the new instance to become ready and for enabling plugins")
+	instanceMigrateCmd.Flags().Bool("wait-drained", false, "Wait until the source queues are drained, after cutover")
+	instanceMigrateCmd.Flags().String("drain-timeout", "1h", "Timeout for --wait-drained")
+	instanceMigrateCmd.Flags().Bool("reset", false, "Discard the local migration state and start over")
+	addConfirmFlags(instanceMigrateCmd)
+
+	instanceMigrateCmd.MarkFlagRequired("from-id")
+	instanceMigrateCmd.RegisterFlagCompletionFunc("from-id", completeInstanceIDFlag)
+	instanceMigrateCmd.RegisterFlagCompletionFunc("to-plan", completePlans)
+	instanceMigrateCmd.RegisterFlagCompletionFunc("to-region", completeRegions)
+	instanceMigrateCmd.RegisterFlagCompletionFunc("copy-settings", completeCopySettings)
+}
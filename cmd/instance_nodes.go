@@ -6,6 +6,7 @@ import (
 
 	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/internal/watch"
 	"github.com/spf13/cobra"
 )
 
@@ -21,61 +22,80 @@ var instanceNodesCmd = &cobra.Command{
 }
 
 var instanceNodesListCmd = &cobra.Command{
-	Use:     "list --id <instance_id>",
-	Short:   "List nodes in the instance",
-	Long:    `Retrieves all nodes in the instance.`,
-	Example: `  cloudamqp instance nodes list --id 1234`,
+	Use:   "list --id <instance_id>",
+	Short: "List nodes in the instance",
+	Long: `Retrieves all nodes in the instance.
+
+With --watch the nodes are polled every --watch-interval and nodes that are
+added, removed or changed are printed, e.g. to follow a resize or upgrade.`,
+	Example: `  cloudamqp instance nodes list --id 1234
+  cloudamqp instance nodes list --id 1234 --watch`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		idFlag, _ := cmd.Flags().GetString("id")
 		if idFlag == "" {
 			return fmt.Errorf("instance ID is required. Use --id flag")
 		}
 
-		var err error
+		opts, watching, err := watchOptions(cmd, "node")
+		if err != nil {
+			return err
+		}
+
 		apiKey, err := getAPIKey()
 		if err != nil {
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		nodes, err := c.ListNodes(idFlag)
 		if err != nil {
-			fmt.Printf("Error listing nodes: %v\n", err)
-			return err
+			return commandError("listing nodes", err)
 		}
 
-		if len(nodes) == 0 {
-			fmt.Println("No nodes found.")
-			return nil
+		if !opts.JSON {
+			printNodeList(nodes)
 		}
-
-		// Create table and populate data
-		t := table.New(os.Stdout, "NAME", "CONFIGURED", "RUNNING", "DISK_SIZE", "RABBITMQ_VERSION")
-		for _, node := range nodes {
-			configured := "No"
-			if node.Configured {
-				configured = "Yes"
-			}
-			running := "No"
-			if node.Running {
-				running = "Yes"
-			}
-			totalDisk := node.DiskSize + node.AdditionalDiskSize
-			t.AddRow(
-				node.Name,
-				configured,
-				running,
-				fmt.Sprintf("%d GB", totalDisk),
-				node.RabbitMQVersion,
-			)
+		if !watching {
+			return nil
 		}
-		t.Print()
 
-		return nil
+		return runWatch(opts, nodeResources(nodes), func() ([]watch.Resource, error) {
+			nodes, err := c.ListNodes(idFlag)
+			return nodeResources(nodes), err
+		})
 	},
 }
 
+func printNodeList(nodes []client.Node) {
+	if len(nodes) == 0 {
+		fmt.Println("No nodes found.")
+		return
+	}
+
+	// Create table and populate data
+	t := table.New(os.Stdout, "NAME", "CONFIGURED", "RUNNING", "DISK_SIZE", "RABBITMQ_VERSION")
+	for _, node := range nodes {
+		configured := "No"
+		if node.Configured {
+			configured = "Yes"
+		}
+		running := "No"
+		if node.Running {
+			running = "Yes"
+		}
+		totalDisk := node.DiskSize + node.AdditionalDiskSize
+		t.AddRow(
+			node.Name,
+			configured,
+			running,
+			fmt.Sprintf("%d GB", totalDisk),
+			node.RabbitMQVersion,
+		)
+	}
+	t.Print()
+}
+
 var instanceNodesVersionsCmd = &cobra.Command{
 	Use:     "versions --id <instance_id>",
 	Short:   "Get available versions",
@@ -93,12 +113,11 @@ var instanceNodesVersionsCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		versions, err := c.GetAvailableVersions(idFlag)
 		if err != nil {
-			fmt.Printf("Error getting available versions: %v\n", err)
-			return err
+			return commandError("getting available versions", err)
 		}
 
 		fmt.Printf("Available versions:\n")
@@ -116,6 +135,7 @@ func init() {
 	// Add --id flag to all subcommands
 	instanceNodesListCmd.Flags().StringP("id", "", "", "Instance ID (required)")
 	instanceNodesListCmd.MarkFlagRequired("id")
+	addWatchFlags(instanceNodesListCmd)
 
 	instanceNodesVersionsCmd.Flags().StringP("id", "", "", "Instance ID (required)")
 	instanceNodesVersionsCmd.MarkFlagRequired("id")
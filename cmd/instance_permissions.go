@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var instancePermissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "Manage user permissions on vhosts",
+	Long: `Set and clear the permissions of RabbitMQ users on vhosts through the
+RabbitMQ management API.
+
+Permissions are three regular expressions matched against resource names:
+configure (declare and delete), write (publish and bind) and read (consume
+and get).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instancePermissionsSetCmd = &cobra.Command{
+	Use:   "set --id <instance_id> --user <username>",
+	Short: "Set the permissions of a user on a vhost",
+	Long: `Sets the configure, write and read permissions of a user on a vhost. Each
+defaults to ".*" (all resources). Use "" to deny an operation.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance permissions set --id 1234 --user orders-app
+  cloudamqp instance permissions set --id 1234 --user orders-app --vhost=orders --configure="^orders\." --write=".*" --read=".*"
+  cloudamqp instance permissions set --id 1234 --user reporting --configure="" --write="" --read=".*"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, _ := cmd.Flags().GetString("user")
+		configure, _ := cmd.Flags().GetString("configure")
+		write, _ := cmd.Flags().GetString("write")
+		read, _ := cmd.Flags().GetString("read")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		if err := mgmt.SetPermissions(vhost, user, configure, write, read); err != nil {
+			return commandError("setting permissions", err)
+		}
+
+		fmt.Printf("Permissions of user %s on vhost %s set (configure %q, write %q, read %q).\n", user, vhost, configure, write, read)
+		return nil
+	},
+}
+
+var instancePermissionsClearCmd = &cobra.Command{
+	Use:   "clear --id <instance_id> --user <username>",
+	Short: "Clear the permissions of a user on a vhost",
+	Long: `Removes all permissions of a user on a vhost. The user can no longer connect
+to the vhost.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance permissions clear --id 1234 --user orders-app
+  cloudamqp instance permissions clear --id 1234 --user orders-app --vhost=orders`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, _ := cmd.Flags().GetString("user")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		if err := mgmt.ClearPermissions(vhost, user); err != nil {
+			return commandError("clearing permissions", err)
+		}
+
+		fmt.Printf("Permissions of user %s on vhost %s cleared.\n", user, vhost)
+		return nil
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{instancePermissionsSetCmd, instancePermissionsClearCmd} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.Flags().String("user", "", "Username (required)")
+		cmd.Flags().String("vhost", "", "Vhost (default: vhost of the instance URL)")
+		cmd.MarkFlagRequired("id")
+		cmd.MarkFlagRequired("user")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instancePermissionsSetCmd.Flags().String("configure", ".*", "Configure permission regular expression")
+	instancePermissionsSetCmd.Flags().String("write", ".*", "Write permission regular expression")
+	instancePermissionsSetCmd.Flags().String("read", ".*", "Read permission regular expression")
+
+	instancePermissionsCmd.AddCommand(instancePermissionsSetCmd)
+	instancePermissionsCmd.AddCommand(instancePermissionsClearCmd)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
 	"github.com/spf13/cobra"
 )
@@ -37,12 +36,11 @@ var instancePluginsListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		plugins, err := c.ListPlugins(idFlag)
 		if err != nil {
-			fmt.Printf("Error listing plugins: %v\n", err)
-			return err
+			return commandError("listing plugins", err)
 		}
 
 		if len(plugins) == 0 {
@@ -66,11 +64,12 @@ var instancePluginsListCmd = &cobra.Command{
 }
 
 var instancePluginsEnableCmd = &cobra.Command{
-	Use:     "enable <plugin_name> --id <instance_id>",
-	Short:   "Enable a plugin",
-	Long:    `Enables a RabbitMQ plugin on the instance.`,
-	Args:    cobra.ExactArgs(1),
-	Example: `  cloudamqp instance plugins enable rabbitmq_top --id 1234`,
+	Use:   "enable <plugin_name> --id <instance_id>",
+	Short: "Enable a plugin",
+	Long:  `Enables a RabbitMQ plugin on the instance.`,
+	Args:  cobra.ExactArgs(1),
+	Example: `  cloudamqp instance plugins enable rabbitmq_top --id 1234
+  cloudamqp instance plugins enable rabbitmq_top --id 1234 --wait`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pluginName := args[0]
 		idFlag, _ := cmd.Flags().GetString("id")
@@ -84,25 +83,25 @@ var instancePluginsEnableCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		err = c.EnablePlugin(idFlag, pluginName)
 		if err != nil {
-			fmt.Printf("Error enabling plugin '%s': %v\n", pluginName, err)
-			return err
+			return commandError(fmt.Sprintf("enabling plugin '%s'", pluginName), err)
 		}
 
 		fmt.Printf("Plugin '%s' enabled successfully.\n", pluginName)
-		return nil
+		return waitIfRequested(cmd, c, idFlag, "plugin-enabled="+pluginName, nil, nil)
 	},
 }
 
 var instancePluginsDisableCmd = &cobra.Command{
-	Use:     "disable <plugin_name> --id <instance_id>",
-	Short:   "Disable a plugin",
-	Long:    `Disables a RabbitMQ plugin on the instance.`,
-	Args:    cobra.ExactArgs(1),
-	Example: `  cloudamqp instance plugins disable rabbitmq_top --id 1234`,
+	Use:   "disable <plugin_name> --id <instance_id>",
+	Short: "Disable a plugin",
+	Long:  `Disables a RabbitMQ plugin on the instance.`,
+	Args:  cobra.ExactArgs(1),
+	Example: `  cloudamqp instance plugins disable rabbitmq_top --id 1234
+  cloudamqp instance plugins disable rabbitmq_top --id 1234 --wait`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pluginName := args[0]
 		idFlag, _ := cmd.Flags().GetString("id")
@@ -116,16 +115,15 @@ var instancePluginsDisableCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		err = c.DisablePlugin(idFlag, pluginName)
 		if err != nil {
-			fmt.Printf("Error disabling plugin '%s': %v\n", pluginName, err)
-			return err
+			return commandError(fmt.Sprintf("disabling plugin '%s'", pluginName), err)
 		}
 
 		fmt.Printf("Plugin '%s' disabled successfully.\n", pluginName)
-		return nil
+		return waitIfRequested(cmd, c, idFlag, "plugin-disabled="+pluginName, nil, nil)
 	},
 }
 
@@ -136,9 +134,11 @@ func init() {
 
 	instancePluginsEnableCmd.Flags().StringP("id", "", "", "Instance ID (required)")
 	instancePluginsEnableCmd.MarkFlagRequired("id")
+	addWaitFlags(instancePluginsEnableCmd, "5m")
 
 	instancePluginsDisableCmd.Flags().StringP("id", "", "", "Instance ID (required)")
 	instancePluginsDisableCmd.MarkFlagRequired("id")
+	addWaitFlags(instancePluginsDisableCmd, "5m")
 
 	// Add all commands to plugins
 	instancePluginsCmd.AddCommand(instancePluginsListCmd)
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instancePoliciesCmd = &cobra.Command{
+	Use:   "policies",
+	Short: "Manage policies",
+	Long: `List, set, and delete policies through the RabbitMQ management API.
+
+Policies apply settings such as message TTL, max length, dead lettering and
+delivery limits to all queues or exchanges whose name matches a pattern. When
+several policies match, the one with the highest priority applies.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceOperatorPoliciesCmd = &cobra.Command{
+	Use:   "operator-policies",
+	Short: "Manage operator policies",
+	Long: `List, set, and delete operator policies through the RabbitMQ management API.
+
+Operator policies enforce limits such as max length, message TTL and delivery
+limits on queues. They are merged with regular policies and the stricter value
+wins, so users cannot raise a limit with their own policy.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instancePoliciesListCmd = &cobra.Command{
+	Use:   "list --id <instance_id>",
+	Short: "List policies",
+	Long:  `Lists policies in all vhosts, or in the vhost given by --vhost.`,
+	Example: `  cloudamqp instance policies list --id 1234
+  cloudamqp instance policies list --id 1234 --vhost=myvhost`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listPolicies(cmd, rabbitmq.PolicyKindPolicy)
+	},
+}
+
+var instancePoliciesSetCmd = &cobra.Command{
+	Use:   "set <name> --id <instance_id> --pattern <regex> --definition <json>",
+	Short: "Create or update a policy",
+	Long: `Creates a policy, or replaces an existing policy with the same name.
+
+The definition is a JSON object. These keys are validated before the policy
+is submitted: ha-mode, ha-params, ha-sync-mode, max-length, max-length-bytes,
+message-ttl, expires, dead-letter-exchange, dead-letter-routing-key,
+delivery-limit and overflow. Other keys are passed to RabbitMQ as is.
+
+The apply-to values classic_queues, quorum_queues and streams require RabbitMQ
+3.12 or later. The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance policies set ttl --id 1234 --pattern='^orders\.' --definition='{"message-ttl": 60000}'
+  cloudamqp instance policies set dlx --id 1234 --pattern='.*' --apply-to=quorum_queues --priority=1 \
+    --definition='{"dead-letter-exchange": "dlx", "delivery-limit": 5}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPolicy(cmd, rabbitmq.PolicyKindPolicy, args[0])
+	},
+}
+
+var instancePoliciesDeleteCmd = &cobra.Command{
+	Use:   "delete <name> --id <instance_id>",
+	Short: "Delete a policy",
+	Long: `Deletes a policy. The settings of the policy are removed from all queues and
+exchanges it applied to.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance policies delete ttl --id 1234`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deletePolicy(cmd, rabbitmq.PolicyKindPolicy, args[0])
+	},
+}
+
+var instanceOperatorPoliciesListCmd = &cobra.Command{
+	Use:   "list --id <instance_id>",
+	Short: "List operator policies",
+	Long:  `Lists operator policies in all vhosts, or in the vhost given by --vhost.`,
+	Example: `  cloudamqp instance operator-policies list --id 1234
+  cloudamqp instance operator-policies list --id 1234 --vhost=myvhost`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listPolicies(cmd, rabbitmq.PolicyKindOperator)
+	},
+}
+
+var instanceOperatorPoliciesSetCmd = &cobra.Command{
+	Use:   "set <name> --id <instance_id> --pattern <regex> --definition <json>",
+	Short: "Create or update an operator policy",
+	Long: `Creates an operator policy, or replaces an existing one with the same name.
+
+The definition is a JSON object. Operator policies only support these keys,
+which are validated before the policy is submitted: expires, message-ttl,
+max-length, max-length-bytes, max-in-memory-length, max-in-memory-bytes,
+delivery-limit and queue-initial-cluster-size.
+
+Operator policies only apply to queues: apply-to must be queues, or on
+RabbitMQ 3.12 or later classic_queues, quorum_queues or streams. The vhost
+defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance operator-policies set limits --id 1234 --pattern='.*' \
+    --definition='{"max-length": 100000, "delivery-limit": 20}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPolicy(cmd, rabbitmq.PolicyKindOperator, args[0])
+	},
+}
+
+var instanceOperatorPoliciesDeleteCmd = &cobra.Command{
+	Use:   "delete <name> --id <instance_id>",
+	Short: "Delete an operator policy",
+	Long: `Deletes an operator policy.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance operator-policies delete limits --id 1234`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deletePolicy(cmd, rabbitmq.PolicyKindOperator, args[0])
+	},
+}
+
+// policyNoun names a policy kind in messages
+func policyNoun(kind string) string {
+	if kind == rabbitmq.PolicyKindOperator {
+		return "operator policy"
+	}
+	return "policy"
+}
+
+// policyTitle names a policy kind at the start of a message
+func policyTitle(kind string) string {
+	if kind == rabbitmq.PolicyKindOperator {
+		return "Operator policy"
+	}
+	return "Policy"
+}
+
+func listPolicies(cmd *cobra.Command, kind string) error {
+	vhost, _ := cmd.Flags().GetString("vhost")
+
+	mgmt, err := instanceManagementClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	plural := strings.ReplaceAll(kind, "-", " ")
+	policies, err := mgmt.ListPolicies(kind, vhost)
+	if err != nil {
+		return commandError(fmt.Sprintf("listing %s", plural), err)
+	}
+
+	if len(policies) == 0 {
+		fmt.Printf("No %s found.\n", plural)
+		return nil
+	}
+
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Vhost != policies[j].Vhost {
+			return policies[i].Vhost < policies[j].Vhost
+		}
+		return policies[i].Priority > policies[j].Priority
+	})
+
+	t := table.New(os.Stdout, "VHOST", "NAME", "PATTERN", "APPLY_TO", "PRIORITY", "DEFINITION")
+	for _, p := range policies {
+		definition, _ := json.Marshal(p.Definition)
+		t.AddRow(p.Vhost, p.Name, p.Pattern, p.ApplyTo, fmt.Sprintf("%d", p.Priority), string(definition))
+	}
+	t.Print()
+
+	return nil
+}
+
+func setPolicy(cmd *cobra.Command, kind, name string) error {
+	pattern, _ := cmd.Flags().GetString("pattern")
+	applyTo, _ := cmd.Flags().GetString("apply-to")
+	priority, _ := cmd.Flags().GetInt("priority")
+	definitionFlag, _ := cmd.Flags().GetString("definition")
+
+	definition, err := parsePolicyDefinition(definitionFlag)
+	if err != nil {
+		return err
+	}
+
+	policy := rabbitmq.Policy{
+		Name:       name,
+		Pattern:    pattern,
+		ApplyTo:    applyTo,
+		Priority:   priority,
+		Definition: definition,
+	}
+	if err := rabbitmq.ValidatePolicy(kind, policy); err != nil {
+		return err
+	}
+
+	mgmt, err := instanceManagementClient(cmd)
+	if err != nil {
+		return err
+	}
+	policy.Vhost = managementVhost(cmd, mgmt)
+
+	if err := mgmt.SetPolicy(kind, policy); err != nil {
+		return commandError(fmt.Sprintf("setting %s", policyNoun(kind)), err)
+	}
+
+	fmt.Printf("%s %s set on vhost %s.\n", policyTitle(kind), name, policy.Vhost)
+	return nil
+}
+
+func deletePolicy(cmd *cobra.Command, kind, name string) error {
+	mgmt, err := instanceManagementClient(cmd)
+	if err != nil {
+		return err
+	}
+	vhost := managementVhost(cmd, mgmt)
+
+	if err := mgmt.DeletePolicy(kind, vhost, name); err != nil {
+		return commandError(fmt.Sprintf("deleting %s", policyNoun(kind)), err)
+	}
+
+	fmt.Printf("%s %s deleted from vhost %s.\n", policyTitle(kind), name, vhost)
+	return nil
+}
+
+// parsePolicyDefinition parses the JSON object given to --definition
+func parsePolicyDefinition(value string) (map[string]any, error) {
+	var definition map[string]any
+	if err := json.Unmarshal([]byte(value), &definition); err != nil {
+		return nil, fmt.Errorf("invalid definition, must be a JSON object: %v", err)
+	}
+	return definition, nil
+}
+
+func completePolicyApplyTo(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if cmd == instanceOperatorPoliciesSetCmd {
+		return rabbitmq.OperatorPolicyApplyTo, cobra.ShellCompDirectiveNoFileComp
+	}
+	return rabbitmq.PolicyApplyTo, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		instancePoliciesListCmd, instancePoliciesSetCmd, instancePoliciesDeleteCmd,
+		instanceOperatorPoliciesListCmd, instanceOperatorPoliciesSetCmd, instanceOperatorPoliciesDeleteCmd,
+	} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instancePoliciesListCmd.Flags().String("vhost", "", "Only list policies in this vhost")
+	instanceOperatorPoliciesListCmd.Flags().String("vhost", "", "Only list operator policies in this vhost")
+
+	for _, cmd := range []*cobra.Command{
+		instancePoliciesSetCmd, instancePoliciesDeleteCmd,
+		instanceOperatorPoliciesSetCmd, instanceOperatorPoliciesDeleteCmd,
+	} {
+		cmd.Flags().String("vhost", "", "Vhost of the policy (default: vhost of the instance URL)")
+	}
+
+	for cmd, kind := range map[*cobra.Command]string{
+		instancePoliciesSetCmd:         rabbitmq.PolicyKindPolicy,
+		instanceOperatorPoliciesSetCmd: rabbitmq.PolicyKindOperator,
+	} {
+		cmd.Flags().String("pattern", "", "Regular expression matched against queue or exchange names (required)")
+		cmd.Flags().String("definition", "", "Policy definition as a JSON object (required)")
+		cmd.Flags().String("apply-to", "queues", "What the policy applies to: "+strings.Join(rabbitmq.ApplyToValues(kind), ", "))
+		cmd.Flags().Int("priority", 0, "Priority, the matching policy with the highest priority applies")
+		cmd.MarkFlagRequired("pattern")
+		cmd.MarkFlagRequired("definition")
+		cmd.RegisterFlagCompletionFunc("apply-to", completePolicyApplyTo)
+	}
+
+	instancePoliciesCmd.AddCommand(instancePoliciesListCmd)
+	instancePoliciesCmd.AddCommand(instancePoliciesSetCmd)
+	instancePoliciesCmd.AddCommand(instancePoliciesDeleteCmd)
+
+	instanceOperatorPoliciesCmd.AddCommand(instanceOperatorPoliciesListCmd)
+	instanceOperatorPoliciesCmd.AddCommand(instanceOperatorPoliciesSetCmd)
+	instanceOperatorPoliciesCmd.AddCommand(instanceOperatorPoliciesDeleteCmd)
+}
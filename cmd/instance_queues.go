@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceQueuesCmd = &cobra.Command{
+	Use:   "queues",
+	Short: "Inspect and manage queues",
+	Long: `List, inspect, purge, and delete queues through the RabbitMQ management API.
+
+The management API is reached with the credentials of the instance URL.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceQueuesListCmd = &cobra.Command{
+	Use:   "list --id <instance_id>",
+	Short: "List queues",
+	Long: `Lists queues in all vhosts, or in the vhost given by --vhost.
+
+Use --filter to only show queues whose name matches a regular expression and
+--sort to order them by name, messages, consumers or rate (publish rate).
+Numeric sort keys list the largest values first.`,
+	Example: `  cloudamqp instance queues list --id 1234
+  cloudamqp instance queues list --id 1234 --vhost=myvhost --sort=messages
+  cloudamqp instance queues list --id 1234 --filter='^orders\.' --sort=rate`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vhost, _ := cmd.Flags().GetString("vhost")
+		sortBy, _ := cmd.Flags().GetString("sort")
+		filter, _ := cmd.Flags().GetString("filter")
+
+		pattern, err := compileQueueFilter(filter)
+		if err != nil {
+			return err
+		}
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		queues, err := mgmt.ListQueues(vhost)
+		if err != nil {
+			return commandError("listing queues", err)
+		}
+
+		queues = filterQueues(queues, pattern)
+		if err := sortQueues(queues, sortBy); err != nil {
+			return err
+		}
+
+		if len(queues) == 0 {
+			fmt.Println("No queues found.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "VHOST", "NAME", "TYPE", "STATE", "MESSAGES", "READY", "UNACKED", "CONSUMERS", "PUBLISH/S", "DELIVER/S")
+		for _, q := range queues {
+			t.AddRow(
+				q.Vhost,
+				q.Name,
+				q.Type,
+				q.State,
+				fmt.Sprintf("%d", q.Messages),
+				fmt.Sprintf("%d", q.MessagesReady),
+				fmt.Sprintf("%d", q.MessagesUnacknowledged),
+				fmt.Sprintf("%d", q.Consumers),
+				fmt.Sprintf("%.1f", q.MessageStats.PublishDetails.Rate),
+				fmt.Sprintf("%.1f", q.MessageStats.DeliverGetDetails.Rate),
+			)
+		}
+		t.Print()
+
+		return nil
+	},
+}
+
+var instanceQueuesGetCmd = &cobra.Command{
+	Use:   "get <queue_name> --id <instance_id>",
+	Short: "Get queue details",
+	Long: `Retrieves details of a queue as JSON.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance queues get orders --id 1234
+  cloudamqp instance queues get orders --id 1234 --vhost=myvhost`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		queue, err := mgmt.GetQueue(vhost, args[0])
+		if err != nil {
+			return commandError("getting queue", err)
+		}
+
+		output, err := json.MarshalIndent(queue, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format response: %v", err)
+		}
+
+		fmt.Println(string(output))
+		return nil
+	},
+}
+
+var instanceQueuesPurgeCmd = &cobra.Command{
+	Use:   "purge <queue_name> --id <instance_id>",
+	Short: "Purge a queue",
+	Long: `Removes all ready messages from a queue. Unacknowledged messages are kept.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance queues purge orders --id 1234
+  cloudamqp instance queues purge orders --id 1234 --vhost=myvhost --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		confirmed, err := confirmAction(cmd, fmt.Sprintf("purge all ready messages from queue %s in vhost %s", name, vhost), "queue", name)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Purge cancelled.")
+			return nil
+		}
+
+		if err := mgmt.PurgeQueue(vhost, name); err != nil {
+			return commandError("purging queue", err)
+		}
+
+		fmt.Printf("Queue %s purged.\n", name)
+		return nil
+	},
+}
+
+var instanceQueuesDeleteCmd = &cobra.Command{
+	Use:   "delete <queue_name> --id <instance_id>",
+	Short: "Delete a queue",
+	Long: `Deletes a queue and all messages in it.
+
+Use --if-empty or --if-unused to only delete the queue when it has no
+messages or no consumers. The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance queues delete orders --id 1234
+  cloudamqp instance queues delete orders --id 1234 --if-empty --if-unused --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		ifEmpty, _ := cmd.Flags().GetBool("if-empty")
+		ifUnused, _ := cmd.Flags().GetBool("if-unused")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		confirmed, err := confirmAction(cmd, fmt.Sprintf("delete queue %s in vhost %s", name, vhost), "queue", name)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Delete operation cancelled.")
+			return nil
+		}
+
+		if err := mgmt.DeleteQueue(vhost, name, ifEmpty, ifUnused); err != nil {
+			return commandError("deleting queue", err)
+		}
+
+		fmt.Printf("Queue %s deleted.\n", name)
+		return nil
+	},
+}
+
+// compileQueueFilter compiles a queue name filter, "" matches all queues
+func compileQueueFilter(filter string) (*regexp.Regexp, error) {
+	if filter == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	return pattern, nil
+}
+
+// filterQueues returns the queues whose name matches pattern. A nil pattern
+// matches all queues.
+func filterQueues(queues []rabbitmq.Queue, pattern *regexp.Regexp) []rabbitmq.Queue {
+	if pattern == nil {
+		return queues
+	}
+	var matched []rabbitmq.Queue
+	for _, q := range queues {
+		if pattern.MatchString(q.Name) {
+			matched = append(matched, q)
+		}
+	}
+	return matched
+}
+
+// sortQueues sorts queues by name (ascending) or by messages, consumers or
+// publish rate (descending). Ties are broken by vhost and name.
+func sortQueues(queues []rabbitmq.Queue, by string) error {
+	var key func(q rabbitmq.Queue) float64
+	switch by {
+	case "", "name":
+	case "messages":
+		key = func(q rabbitmq.Queue) float64 { return float64(q.Messages) }
+	case "consumers":
+		key = func(q rabbitmq.Queue) float64 { return float64(q.Consumers) }
+	case "rate":
+		key = func(q rabbitmq.Queue) float64 { return q.MessageStats.PublishDetails.Rate }
+	default:
+		return fmt.Errorf("invalid sort key %q, must be one of: name, messages, consumers, rate", by)
+	}
+
+	sort.SliceStable(queues, func(i, j int) bool {
+		if key != nil && key(queues[i]) != key(queues[j]) {
+			return key(queues[i]) > key(queues[j])
+		}
+		if queues[i].Vhost != queues[j].Vhost {
+			return queues[i].Vhost < queues[j].Vhost
+		}
+		return queues[i].Name < queues[j].Name
+	})
+	return nil
+}
+
+func completeQueueSort(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"name", "messages", "consumers", "rate"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		instanceQueuesListCmd, instanceQueuesGetCmd,
+		instanceQueuesPurgeCmd, instanceQueuesDeleteCmd,
+	} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instanceQueuesListCmd.Flags().String("vhost", "", "Only list queues in this vhost")
+	instanceQueuesListCmd.Flags().String("sort", "name", "Sort by name, messages, consumers or rate")
+	instanceQueuesListCmd.Flags().String("filter", "", "Regular expression matched against queue names")
+	instanceQueuesListCmd.RegisterFlagCompletionFunc("sort", completeQueueSort)
+
+	for _, cmd := range []*cobra.Command{instanceQueuesGetCmd, instanceQueuesPurgeCmd, instanceQueuesDeleteCmd} {
+		cmd.Flags().String("vhost", "", "Vhost of the queue (default: vhost of the instance URL)")
+	}
+
+	addConfirmFlags(instanceQueuesPurgeCmd)
+	addConfirmFlags(instanceQueuesDeleteCmd)
+	instanceQueuesDeleteCmd.Flags().Bool("if-empty", false, "Only delete the queue if it has no messages")
+	instanceQueuesDeleteCmd.Flags().Bool("if-unused", false, "Only delete the queue if it has no consumers")
+
+	instanceQueuesCmd.AddCommand(instanceQueuesListCmd)
+	instanceQueuesCmd.AddCommand(instanceQueuesGetCmd)
+	instanceQueuesCmd.AddCommand(instanceQueuesPurgeCmd)
+	instanceQueuesCmd.AddCommand(instanceQueuesDeleteCmd)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"regexp"
+	"testing"
+
+	"cloudamqp-cli/rabbitmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func queueNames(queues []rabbitmq.Queue) []string {
+	names := make([]string, len(queues))
+	for i, q := range queues {
+		names[i] = q.Name
+	}
+	return names
+}
+
+func TestFilterAndSortQueues(t *testing.T) {
+	queues := []rabbitmq.Queue{
+		{Name: "orders.eu", Vhost: "/", Messages: 5, Consumers: 1},
+		{Name: "events", Vhost: "/", Messages: 50, Consumers: 0},
+		{Name: "orders.us", Vhost: "/", Messages: 5, Consumers: 3},
+	}
+	queues[0].MessageStats.PublishDetails.Rate = 10
+	queues[2].MessageStats.PublishDetails.Rate = 2.5
+
+	assert.NoError(t, sortQueues(queues, "name"))
+	assert.Equal(t, []string{"events", "orders.eu", "orders.us"}, queueNames(queues))
+
+	assert.NoError(t, sortQueues(queues, "messages"))
+	assert.Equal(t, []string{"events", "orders.eu", "orders.us"}, queueNames(queues))
+
+	assert.NoError(t, sortQueues(queues, "consumers"))
+	assert.Equal(t, []string{"orders.us", "orders.eu", "events"}, queueNames(queues))
+
+	assert.NoError(t, sortQueues(queues, "rate"))
+	assert.Equal(t, []string{"orders.eu", "orders.us", "events"}, queueNames(queues))
+
+	assert.Error(t, sortQueues(queues, "size"))
+
+	filtered := filterQueues(queues, regexp.MustCompile(`^orders\.`))
+	assert.Equal(t, []string{"orders.eu", "orders.us"}, queueNames(filtered))
+	assert.Len(t, filterQueues(queues, nil), 3)
+}
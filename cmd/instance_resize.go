@@ -21,12 +21,14 @@ var instanceResizeCmd = &cobra.Command{
 Currently limited to instances in Amazon Web Services (AWS) and Google Compute Engine (GCE).
 
 Note: This action is asynchronous. The request will return almost immediately. The disk resize runs in the background.
+Use --wait to wait until the new disk size is visible on all nodes.
 
 Note: Due to restrictions from cloud providers, it's only possible to resize the disk every 8 hours unless --allow-downtime is set.
 
 Available disk sizes: 0, 25, 50, 100, 250, 500, 1000, 2000 GB`,
 	Example: `  cloudamqp instance resize-disk --id 1234 --disk-size=100
-  cloudamqp instance resize-disk --id 1234 --disk-size=250 --allow-downtime`,
+  cloudamqp instance resize-disk --id 1234 --disk-size=250 --allow-downtime
+  cloudamqp instance resize-disk --id 1234 --disk-size=100 --wait`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
@@ -57,7 +59,22 @@ Available disk sizes: 0, 25, 50, 100, 250, 500, 1000, 2000 GB`,
 			return fmt.Errorf("invalid disk size. Valid sizes are: 0, 25, 50, 100, 250, 500, 1000, 2000 GB")
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
+
+		if err := checkInstanceProtection(cmd, c, instanceID, "resize"); err != nil {
+			return err
+		}
+
+		// Remember the current disk size so --wait knows which total to expect
+		shouldWait, _ := cmd.Flags().GetBool("wait")
+		targetDiskSize := 0
+		if shouldWait {
+			nodes, err := c.ListNodes(resizeInstanceID)
+			if err != nil {
+				return commandError("listing nodes", err)
+			}
+			targetDiskSize = resizeTargetDiskSize(nodes, diskSize)
+		}
 
 		req := &client.DiskResizeRequest{
 			ExtraDiskSize: diskSize,
@@ -66,22 +83,38 @@ Available disk sizes: 0, 25, 50, 100, 250, 500, 1000, 2000 GB`,
 
 		err = c.ResizeInstanceDisk(instanceID, req)
 		if err != nil {
-			fmt.Printf("Error resizing instance disk: %v\n", err)
-			return err
+			return commandError("resizing instance disk", err)
 		}
 
 		fmt.Printf("Disk resize initiated for instance %d. Additional disk size: %d GB\n", instanceID, diskSize)
 		if allowDowntime {
 			fmt.Println("Note: Downtime is allowed for this resize operation.")
 		}
-		return nil
+
+		spec := fmt.Sprintf("disk-size>=%d", targetDiskSize)
+		return waitIfRequested(cmd, c, resizeInstanceID, spec, nil, nil)
 	},
 }
 
+// resizeTargetDiskSize returns the total disk size, base plus additional,
+// that every node has once extra GB are added to the smallest node. It
+// measures the same total as the disk-size>= wait condition.
+func resizeTargetDiskSize(nodes []client.Node, extra int) int {
+	smallest := 0
+	for i, node := range nodes {
+		if size := node.DiskSize + node.AdditionalDiskSize; i == 0 || size < smallest {
+			smallest = size
+		}
+	}
+	return smallest + extra
+}
+
 func init() {
 	instanceResizeCmd.Flags().StringVar(&resizeInstanceID, "id", "", "Instance ID (required)")
 	instanceResizeCmd.Flags().IntVar(&diskSize, "disk-size", 0, "Disk size to add in gigabytes (0, 25, 50, 100, 250, 500, 1000, 2000)")
 	instanceResizeCmd.Flags().BoolVar(&allowDowntime, "allow-downtime", false, "Allow cluster downtime if needed when resizing disk")
+	addWaitFlags(instanceResizeCmd, "30m")
+	addProtectionFlag(instanceResizeCmd)
 	instanceResizeCmd.MarkFlagRequired("id")
 	instanceResizeCmd.MarkFlagRequired("disk-size")
 	instanceResizeCmd.RegisterFlagCompletionFunc("id", completeInstances)
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+// Thresholds used by recommendPlan, as fractions of the memory high watermark
+// and of scheduler run queue per core
+const (
+	rightsizeUpperMemory = 0.8
+	rightsizeLowerMemory = 0.3
+	rightsizeUpperCPU    = 1.0
+	rightsizeLowerCPU    = 0.3
+	rightsizeUpperDisk   = 0.8
+)
+
+var instanceRightsizeCmd = &cobra.Command{
+	Use:   "rightsize --id <instance_id>",
+	Short: "Recommend a smaller or larger plan",
+	Long: `Samples the memory, CPU and queue usage of the instance through the management
+API for --window, and recommends a plan of the same backend and node count
+that fits the usage, with the change in monthly price.
+
+A larger plan is recommended when memory use peaks above 80% of the high
+watermark, when the Erlang run queue exceeds one process per core, when a
+resource alarm is raised, or when the queue backlog grows during the window.
+A smaller plan is recommended when memory stays below 30% and the run queue
+below 0.3 per core. Disk is resized independently of the plan, so a nearly
+full disk is reported but does not change the recommendation.
+
+Node metrics require the monitoring tag, which the users of shared plans lack;
+for those instances only the queue backlog is considered.
+
+With --apply the instance is changed to the recommended plan after
+confirmation. Use --wait to wait until it is ready on the new plan.`,
+	Example: `  cloudamqp instance rightsize --id 1234
+  cloudamqp instance rightsize --id 1234 --window=30m --interval=1m
+  cloudamqp instance rightsize --id 1234 --apply --wait`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idFlag, _ := cmd.Flags().GetString("id")
+		windowFlag, _ := cmd.Flags().GetString("window")
+		intervalFlag, _ := cmd.Flags().GetString("interval")
+		apply, _ := cmd.Flags().GetBool("apply")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		instanceID, err := strconv.Atoi(idFlag)
+		if err != nil {
+			return fmt.Errorf("invalid instance ID: %v", err)
+		}
+		window, err := time.ParseDuration(windowFlag)
+		if err != nil {
+			return fmt.Errorf("invalid window value: %v", err)
+		}
+		interval, err := time.ParseDuration(intervalFlag)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %v", err)
+		}
+		if interval < time.Second || window < interval {
+			return fmt.Errorf("interval must be at least 1s and window at least one interval")
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		instance, err := c.GetInstance(instanceID)
+		if err != nil {
+			return commandError("getting instance", err)
+		}
+		nodes, err := c.ListNodes(idFlag)
+		if err != nil {
+			return commandError("listing nodes", err)
+		}
+		plans, err := c.Cached().ListPlans("")
+		if err != nil {
+			return commandError("listing plans", err)
+		}
+		current, ok := findPlan(plans, instance.Plan)
+		if !ok {
+			return fmt.Errorf("plan %s of instance %d not found in the plan list", instance.Plan, instanceID)
+		}
+
+		mgmt, err := managementClientFor(instance)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Sampling instance %d for %s (every %s)...\n", instanceID, window, interval)
+		samples, err := sampleUsage(mgmt, window, interval)
+		if err != nil {
+			return commandError("sampling usage", err)
+		}
+
+		usage := summarizeUsage(samples, nodes)
+		rec := recommendPlan(current, plans, usage)
+
+		if jsonOutput {
+			output, err := json.MarshalIndent(rec, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format recommendation: %w", err)
+			}
+			fmt.Println(string(output))
+		} else {
+			printRecommendation(rec)
+		}
+
+		if !apply || rec.Plan == rec.CurrentPlan {
+			return nil
+		}
+
+		if rec.PriceDelta < 0 {
+			if err := checkInstanceProtection(cmd, c, instanceID, "downgrade"); err != nil {
+				return err
+			}
+		}
+		action := fmt.Sprintf("change instance %d (%s) from %s to %s", instanceID, instance.Name, rec.CurrentPlan, rec.Plan)
+		confirmed, err := confirmAction(cmd, action, "instance", instance.Name)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Plan change cancelled.")
+			return nil
+		}
+
+		if err := c.UpdateInstance(instanceID, &client.InstanceUpdateRequest{Plan: rec.Plan}); err != nil {
+			return commandError("updating instance", err)
+		}
+		fmt.Printf("Instance %d is changing to plan %s.\n", instanceID, rec.Plan)
+
+		return waitIfRequested(cmd, c, idFlag, "plan="+rec.Plan, nil, nil)
+	},
+}
+
+// usageSample is one poll of the management API. Nodes is empty when node
+// metrics are not available.
+type usageSample struct {
+	Nodes    []rabbitmq.NodeStats
+	Messages int
+}
+
+// rightsizeUsage summarises the samples. Memory and CPU are nil when no node
+// metrics were available.
+type rightsizeUsage struct {
+	MemoryPeak      *float64 `json:"memory_peak,omitempty"`
+	MemoryAverage   *float64 `json:"memory_average,omitempty"`
+	CPUPeak         *float64 `json:"cpu_peak,omitempty"`
+	CPUAverage      *float64 `json:"cpu_average,omitempty"`
+	DiskUsed        *float64 `json:"disk_used,omitempty"`
+	Alarms          []string `json:"alarms,omitempty"`
+	MessagesAtStart int      `json:"messages_at_start"`
+	MessagesAtEnd   int      `json:"messages_at_end"`
+	Samples         int      `json:"samples"`
+}
+
+// planRecommendation is the outcome of recommendPlan
+type planRecommendation struct {
+	CurrentPlan  string         `json:"current_plan"`
+	CurrentPrice float64        `json:"current_price"`
+	Plan         string         `json:"plan"`
+	Price        float64        `json:"price"`
+	PriceDelta   float64        `json:"price_delta"`
+	Reasons      []string       `json:"reasons"`
+	Usage        rightsizeUsage `json:"usage"`
+}
+
+// sampleUsage polls node metrics and the queue backlog every interval for the
+// length of window. A failed poll is an error, but missing node metrics are
+// not.
+func sampleUsage(mgmt *rabbitmq.Client, window, interval time.Duration) ([]usageSample, error) {
+	count := int(window/interval) + 1
+	samples := make([]usageSample, 0, count)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		overview, err := mgmt.GetOverview()
+		if err != nil {
+			return nil, err
+		}
+		sample := usageSample{Messages: overview.QueueTotals.Messages}
+		if nodes, err := mgmt.ListNodes(); err == nil {
+			sample.Nodes = nodes
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// summarizeUsage computes peak and average use over the samples. Disk use is
+// relative to the disk size reported by the API for each node.
+func summarizeUsage(samples []usageSample, nodes []client.Node) rightsizeUsage {
+	usage := rightsizeUsage{Samples: len(samples)}
+	if len(samples) == 0 {
+		return usage
+	}
+	usage.MessagesAtStart = samples[0].Messages
+	usage.MessagesAtEnd = samples[len(samples)-1].Messages
+
+	diskSize := map[string]int64{}
+	for _, node := range nodes {
+		diskSize[node.Name] = int64(node.DiskSize+node.AdditionalDiskSize) << 30
+	}
+
+	var memSum, cpuSum float64
+	var memPeak, cpuPeak, diskPeak float64
+	var count int
+	hasDisk := false
+	alarms := map[string]bool{}
+	for _, sample := range samples {
+		for _, stats := range sample.Nodes {
+			count++
+			if stats.MemLimit > 0 {
+				mem := float64(stats.MemUsed) / float64(stats.MemLimit)
+				memSum += mem
+				memPeak = max(memPeak, mem)
+			}
+			if stats.Processors > 0 {
+				cpu := float64(stats.RunQueue) / float64(stats.Processors)
+				cpuSum += cpu
+				cpuPeak = max(cpuPeak, cpu)
+			}
+			if size := diskSize[stats.Hostname()]; size > 0 {
+				hasDisk = true
+				diskPeak = max(diskPeak, 1-float64(stats.DiskFree)/float64(size))
+			}
+			if stats.MemAlarm {
+				alarms["memory alarm on "+stats.Hostname()] = true
+			}
+			if stats.DiskFreeAlarm {
+				alarms["disk alarm on "+stats.Hostname()] = true
+			}
+		}
+	}
+
+	if count > 0 {
+		memAvg, cpuAvg := memSum/float64(count), cpuSum/float64(count)
+		usage.MemoryPeak, usage.MemoryAverage = &memPeak, &memAvg
+		usage.CPUPeak, usage.CPUAverage = &cpuPeak, &cpuAvg
+	}
+	if hasDisk {
+		usage.DiskUsed = &diskPeak
+	}
+	for alarm := range alarms {
+		usage.Alarms = append(usage.Alarms, alarm)
+	}
+	sort.Strings(usage.Alarms)
+	return usage
+}
+
+// planNodeCount returns the node count of a plan from its name suffix, e.g. 3
+// for rabbit-3, or 1 for plans without one
+func planNodeCount(name string) int {
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		if n, err := strconv.Atoi(name[i+1:]); err == nil {
+			return n
+		}
+	}
+	return 1
+}
+
+// comparablePlans returns the plans with the backend, sharing and node count
+// of current, cheapest first
+func comparablePlans(current client.Plan, plans []client.Plan) []client.Plan {
+	var comparable []client.Plan
+	for _, plan := range plans {
+		if plan.Backend == current.Backend && plan.Shared == current.Shared && planNodeCount(plan.Name) == planNodeCount(current.Name) {
+			comparable = append(comparable, plan)
+		}
+	}
+	sort.SliceStable(comparable, func(i, j int) bool {
+		return comparable[i].Price < comparable[j].Price
+	})
+	return comparable
+}
+
+// recommendPlan picks the next larger or smaller comparable plan based on
+// usage, or keeps the current plan
+func recommendPlan(current client.Plan, plans []client.Plan, usage rightsizeUsage) planRecommendation {
+	rec := planRecommendation{
+		CurrentPlan:  current.Name,
+		CurrentPrice: current.Price,
+		Plan:         current.Name,
+		Price:        current.Price,
+		Usage:        usage,
+	}
+
+	var up, down []string
+	up = append(up, usage.Alarms...)
+	if usage.MemoryPeak != nil {
+		if *usage.MemoryPeak >= rightsizeUpperMemory {
+			up = append(up, fmt.Sprintf("memory peaked at %.0f%% of the high watermark", *usage.MemoryPeak*100))
+		}
+		if *usage.CPUPeak >= rightsizeUpperCPU {
+			up = append(up, fmt.Sprintf("run queue peaked at %.1f per core", *usage.CPUPeak))
+		}
+		if *usage.MemoryPeak < rightsizeLowerMemory && *usage.CPUPeak < rightsizeLowerCPU {
+			down = append(down, fmt.Sprintf("memory peaked at %.0f%% of the high watermark and run queue at %.1f per core", *usage.MemoryPeak*100, *usage.CPUPeak))
+		}
+	} else {
+		rec.Reasons = append(rec.Reasons, "node metrics are not available, only the queue backlog was considered")
+	}
+	if usage.Samples > 1 && usage.MessagesAtEnd > usage.MessagesAtStart*2 && usage.MessagesAtEnd-usage.MessagesAtStart > 1000 {
+		up = append(up, fmt.Sprintf("queue backlog grew from %d to %d messages", usage.MessagesAtStart, usage.MessagesAtEnd))
+	}
+	if usage.DiskUsed != nil && *usage.DiskUsed >= rightsizeUpperDisk {
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("disk is %.0f%% used, consider instance resize-disk", *usage.DiskUsed*100))
+	}
+
+	comparable := comparablePlans(current, plans)
+	index := -1
+	for i, plan := range comparable {
+		if plan.Name == current.Name {
+			index = i
+		}
+	}
+
+	switch {
+	case len(up) > 0 && index >= 0 && index+1 < len(comparable):
+		rec.Plan, rec.Price = comparable[index+1].Name, comparable[index+1].Price
+		rec.Reasons = append(up, rec.Reasons...)
+	case len(up) > 0:
+		rec.Reasons = append(append(up, "no larger plan with the same node count"), rec.Reasons...)
+	case len(down) > 0 && index > 0:
+		rec.Plan, rec.Price = comparable[index-1].Name, comparable[index-1].Price
+		rec.Reasons = append(down, rec.Reasons...)
+	case len(down) > 0:
+		rec.Reasons = append(append(down, "no smaller plan with the same node count"), rec.Reasons...)
+	default:
+		if usage.MemoryPeak != nil {
+			rec.Reasons = append([]string{"usage fits the current plan"}, rec.Reasons...)
+		}
+	}
+	rec.PriceDelta = rec.Price - rec.CurrentPrice
+	return rec
+}
+
+func printRecommendation(rec planRecommendation) {
+	usage := rec.Usage
+	fmt.Printf("Current plan = %s (%s/month)\n", rec.CurrentPlan, formatPrice(rec.CurrentPrice))
+	if usage.MemoryPeak != nil {
+		fmt.Printf("Memory = peak %.0f%%, average %.0f%% of the high watermark\n", *usage.MemoryPeak*100, *usage.MemoryAverage*100)
+		fmt.Printf("CPU = peak %.1f, average %.1f runnable processes per core\n", *usage.CPUPeak, *usage.CPUAverage)
+	}
+	if usage.DiskUsed != nil {
+		fmt.Printf("Disk = %.0f%% used\n", *usage.DiskUsed*100)
+	}
+	fmt.Printf("Queue backlog = %d -> %d messages\n", usage.MessagesAtStart, usage.MessagesAtEnd)
+
+	if rec.Plan == rec.CurrentPlan {
+		fmt.Printf("Recommendation = keep %s\n", rec.Plan)
+	} else {
+		fmt.Printf("Recommendation = %s (%s/month, %+.2f USD/month)\n", rec.Plan, formatPrice(rec.Price), rec.PriceDelta)
+	}
+	for _, reason := range rec.Reasons {
+		fmt.Printf("  - %s\n", reason)
+	}
+}
+
+func init() {
+	instanceRightsizeCmd.Flags().StringP("id", "", "", "Instance ID (required)")
+	instanceRightsizeCmd.Flags().String("window", "5m", "How long to sample usage")
+	instanceRightsizeCmd.Flags().String("interval", "30s", "Delay between samples")
+	instanceRightsizeCmd.Flags().Bool("apply", false, "Change the instance to the recommended plan")
+	instanceRightsizeCmd.Flags().Bool("json", false, "Print the recommendation as JSON")
+	addConfirmFlags(instanceRightsizeCmd)
+	addProtectionFlag(instanceRightsizeCmd)
+	addWaitFlags(instanceRightsizeCmd, "30m")
+	instanceRightsizeCmd.MarkFlagRequired("id")
+	instanceRightsizeCmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"testing"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rightsizePlans = []client.Plan{
+	{Name: "lemming", Price: 0, Backend: "rabbitmq", Shared: true},
+	{Name: "bunny-1", Price: 99, Backend: "rabbitmq"},
+	{Name: "rabbit-1", Price: 299, Backend: "rabbitmq"},
+	{Name: "panda-1", Price: 599, Backend: "rabbitmq"},
+	{Name: "rabbit-3", Price: 897, Backend: "rabbitmq"},
+	{Name: "penguin-1", Price: 199, Backend: "lavinmq"},
+}
+
+func usageWith(memory, cpu float64) rightsizeUsage {
+	return rightsizeUsage{MemoryPeak: &memory, MemoryAverage: &memory, CPUPeak: &cpu, CPUAverage: &cpu, Samples: 2}
+}
+
+func TestComparablePlans(t *testing.T) {
+	var names []string
+	for _, plan := range comparablePlans(rightsizePlans[2], rightsizePlans) {
+		names = append(names, plan.Name)
+	}
+	assert.Equal(t, []string{"bunny-1", "rabbit-1", "panda-1"}, names)
+	assert.Equal(t, 3, planNodeCount("rabbit-3"))
+	assert.Equal(t, 1, planNodeCount("lemming"))
+}
+
+func TestRecommendPlan(t *testing.T) {
+	current := rightsizePlans[2]
+
+	rec := recommendPlan(current, rightsizePlans, usageWith(0.9, 0.2))
+	assert.Equal(t, "panda-1", rec.Plan)
+	assert.Equal(t, 300.0, rec.PriceDelta)
+
+	rec = recommendPlan(current, rightsizePlans, usageWith(0.1, 0.1))
+	assert.Equal(t, "bunny-1", rec.Plan)
+	assert.Equal(t, -200.0, rec.PriceDelta)
+
+	rec = recommendPlan(current, rightsizePlans, usageWith(0.5, 0.5))
+	assert.Equal(t, "rabbit-1", rec.Plan)
+	assert.Equal(t, 0.0, rec.PriceDelta)
+
+	rec = recommendPlan(rightsizePlans[3], rightsizePlans, usageWith(0.95, 0.2))
+	assert.Equal(t, "panda-1", rec.Plan)
+	assert.Contains(t, rec.Reasons, "no larger plan with the same node count")
+
+	growing := rightsizeUsage{MessagesAtStart: 100, MessagesAtEnd: 50000, Samples: 5}
+	rec = recommendPlan(current, rightsizePlans, growing)
+	assert.Equal(t, "panda-1", rec.Plan)
+	assert.Contains(t, rec.Reasons, "node metrics are not available, only the queue backlog was considered")
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	nodes := []client.Node{{Name: "host-01", DiskSize: 10}}
+	samples := []usageSample{
+		{Messages: 10, Nodes: []rabbitmq.NodeStats{{Name: "rabbit@host-01", MemUsed: 20, MemLimit: 100, Processors: 2, RunQueue: 1, DiskFree: 8 << 30}}},
+		{Messages: 30, Nodes: []rabbitmq.NodeStats{{Name: "rabbit@host-01", MemUsed: 60, MemLimit: 100, Processors: 2, RunQueue: 0, DiskFree: 7 << 30, MemAlarm: true}}},
+	}
+
+	usage := summarizeUsage(samples, nodes)
+	require.NotNil(t, usage.MemoryPeak)
+	assert.InDelta(t, 0.6, *usage.MemoryPeak, 0.001)
+	assert.InDelta(t, 0.4, *usage.MemoryAverage, 0.001)
+	assert.InDelta(t, 0.5, *usage.CPUPeak, 0.001)
+	require.NotNil(t, usage.DiskUsed)
+	assert.InDelta(t, 0.3, *usage.DiskUsed, 0.001)
+	assert.Equal(t, []string{"memory alarm on host-01"}, usage.Alarms)
+	assert.Equal(t, 10, usage.MessagesAtStart)
+	assert.Equal(t, 30, usage.MessagesAtEnd)
+
+	usage = summarizeUsage([]usageSample{{Messages: 5}}, nodes)
+	assert.Nil(t, usage.MemoryPeak)
+	assert.Nil(t, usage.DiskUsed)
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/internal/wait"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollingRestartNodes        string
+	rollingRestartInterval     string
+	rollingRestartNodeTimeout  string
+	rollingRestartQueuesSynced bool
+)
+
+// Node states reported by rolling-restart
+const (
+	nodeStatePending    = "pending"
+	nodeStateRestarting = "restarting"
+	nodeStateDone       = "restarted"
+	nodeStateFailed     = "failed"
+)
+
+var instanceRollingRestartCmd = &cobra.Command{
+	Use:   "rolling-restart --id <instance_id>",
+	Short: "Restart RabbitMQ one node at a time",
+	Long: `Restart RabbitMQ on each node of the instance, one node at a time.
+
+Each node is restarted and polled until it has been seen restarting and reports
+running and configured again before the next node is restarted. A restart is
+seen when the node stops running or, with management API access, when its
+uptime drops. With --wait-queues-synced the management API is also polled until
+every mirrored classic queue is synchronised and every quorum queue and stream
+member is online, so that no queue loses its last in-sync replica.
+
+If a node fails to come back within the node timeout the rolling restart is
+aborted and the state of every node is reported.`,
+	Example: `  cloudamqp instance rolling-restart --id 1234
+  cloudamqp instance rolling-restart --id 1234 --nodes=node1,node2
+  cloudamqp instance rolling-restart --id 1234 --node-timeout=15m --wait-queues-synced`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idFlag, _ := cmd.Flags().GetString("id")
+		if idFlag == "" {
+			return fmt.Errorf("instance ID is required. Use --id flag")
+		}
+
+		interval, err := time.ParseDuration(rollingRestartInterval)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %v", err)
+		}
+		nodeTimeout, err := time.ParseDuration(rollingRestartNodeTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid node-timeout value: %v", err)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		nodes, err := c.ListNodes(idFlag)
+		if err != nil {
+			return commandError("listing nodes", err)
+		}
+
+		order, err := rollingRestartOrder(nodes, rollingRestartNodes)
+		if err != nil {
+			return err
+		}
+
+		var mgmt *rabbitmq.Client
+		if rollingRestartQueuesSynced {
+			if mgmt, err = newRabbitMQClient(c, idFlag); err != nil {
+				return err
+			}
+		}
+
+		states := make(map[string]string, len(order))
+		for _, name := range order {
+			states[name] = nodeStatePending
+		}
+
+		for i, name := range order {
+			fmt.Printf("[%d/%d] Restarting RabbitMQ on %s...\n", i+1, len(order), name)
+			states[name] = nodeStateRestarting
+
+			restarts := newNodeRestarts(c, idFlag)
+			err := c.RestartRabbitMQ(idFlag, []string{name})
+			if errors.Is(err, client.ErrDryRun) {
+				// Print the request for every node without waiting
+				states[name] = nodeStatePending
+				continue
+			}
+			if err != nil {
+				states[name] = nodeStateFailed
+				printRollingRestartReport(c, idFlag, order, states)
+				return fmt.Errorf("rolling restart aborted at %s: %w", name, err)
+			}
+
+			if err := waitForNodeRestart(c, mgmt, restarts, idFlag, name, interval, nodeTimeout); err != nil {
+				states[name] = nodeStateFailed
+				printRollingRestartReport(c, idFlag, order, states)
+				return fmt.Errorf("rolling restart aborted at %s: %w", name, err)
+			}
+
+			states[name] = nodeStateDone
+			fmt.Printf("[%d/%d] %s is running again.\n", i+1, len(order), name)
+		}
+
+		if dryRun {
+			return client.ErrDryRun
+		}
+
+		fmt.Printf("Rolling restart of %d node(s) completed successfully.\n", len(order))
+		return nil
+	},
+}
+
+// rollingRestartOrder returns the node names to restart, either all nodes of
+// the instance or the comma-separated subset given by the user.
+func rollingRestartOrder(nodes []client.Node, nodesFlag string) ([]string, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("instance has no nodes")
+	}
+
+	known := make(map[string]bool, len(nodes))
+	var all []string
+	for _, node := range nodes {
+		known[node.Name] = true
+		all = append(all, node.Name)
+	}
+
+	if nodesFlag == "" {
+		return all, nil
+	}
+
+	var order []string
+	for _, name := range strings.Split(nodesFlag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown node: %s", name)
+		}
+		order = append(order, name)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no nodes selected")
+	}
+	return order, nil
+}
+
+// waitForNodeRestart waits until the named node has been seen restarting and
+// then reports running and configured again. With mgmt it then waits until all
+// queues are synced. All steps share the timeout.
+func waitForNodeRestart(c *client.Client, mgmt *rabbitmq.Client, restarts *nodeRestarts, instanceID, nodeName string, interval, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	opts := wait.Options{
+		Interval: interval,
+		Backoff:  1,
+		Progress: printWaitProgress(nodeName),
+	}
+
+	if err := wait.Until(ctx, "restart", restarts.Check([]string{nodeName}), opts); err != nil {
+		return err
+	}
+
+	running := func() (bool, string, error) {
+		nodes, err := c.ListNodes(instanceID)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to check node status: %w", err)
+		}
+		for _, node := range nodes {
+			if node.Name == nodeName {
+				if node.Running && node.Configured {
+					return true, nodeName + " is running", nil
+				}
+				return false, nodeName + " is not running", nil
+			}
+		}
+		return false, nodeName + " not found", nil
+	}
+	if err := wait.Until(ctx, "running", running, opts); err != nil {
+		return err
+	}
+
+	if mgmt == nil {
+		return nil
+	}
+	return wait.Until(ctx, "queues synced", queuesSynced(mgmt), opts)
+}
+
+// queuesSynced returns a check that is met when every queue is synced
+func queuesSynced(mgmt *rabbitmq.Client) wait.CheckFunc {
+	return func() (bool, string, error) {
+		queues, err := mgmt.ListQueues("")
+		if err != nil {
+			// The management API can be briefly unavailable after a restart
+			return false, "management API unavailable", nil
+		}
+		unsynced := 0
+		for _, q := range queues {
+			if !q.Synced() {
+				unsynced++
+			}
+		}
+		return unsynced == 0, fmt.Sprintf("%d/%d queues not synced", unsynced, len(queues)), nil
+	}
+}
+
+// printRollingRestartReport prints the rolling restart state of every node
+// together with the node status currently reported by the API.
+func printRollingRestartReport(c *client.Client, instanceID string, order []string, states map[string]string) {
+	current := make(map[string]client.Node)
+	if nodes, err := c.ListNodes(instanceID); err == nil {
+		for _, node := range nodes {
+			current[node.Name] = node
+		}
+	}
+
+	fmt.Println("\nRolling restart state:")
+	t := table.New(os.Stdout, "NAME", "STATE", "RUNNING", "CONFIGURED")
+	for _, name := range order {
+		running, configured := "Unknown", "Unknown"
+		if node, ok := current[name]; ok {
+			running, configured = "No", "No"
+			if node.Running {
+				running = "Yes"
+			}
+			if node.Configured {
+				configured = "Yes"
+			}
+		}
+		t.AddRow(name, states[name], running, configured)
+	}
+	t.Print()
+}
+
+func init() {
+	instanceRollingRestartCmd.Flags().StringP("id", "", "", "Instance ID (required)")
+	instanceRollingRestartCmd.Flags().StringVar(&rollingRestartNodes, "nodes", "", "Comma-separated list of node names to restart, in order (default: all nodes)")
+	instanceRollingRestartCmd.Flags().StringVar(&rollingRestartInterval, "interval", "10s", "Polling interval while waiting for a node")
+	instanceRollingRestartCmd.Flags().StringVar(&rollingRestartNodeTimeout, "node-timeout", "10m", "Maximum time to wait for each node to come back")
+	instanceRollingRestartCmd.Flags().BoolVar(&rollingRestartQueuesSynced, "wait-queues-synced", false, "Wait until all queues are synced via the management API before restarting the next node")
+	instanceRollingRestartCmd.MarkFlagRequired("id")
+	instanceRollingRestartCmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+}
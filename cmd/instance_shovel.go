@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+// shovelPlugins are the plugins needed to create and monitor dynamic shovels
+var shovelPlugins = []string{"rabbitmq_shovel", "rabbitmq_shovel_management"}
+
+var instanceShovelCmd = &cobra.Command{
+	Use:   "shovel",
+	Short: "Move messages between instances with shovels",
+	Long: `Create, monitor, and delete dynamic shovels that move messages from a queue
+on one instance to a queue on another, e.g. when migrating to a new instance.
+
+Shovels run on the destination instance.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceShovelCreateCmd = &cobra.Command{
+	Use:   "create --from-id <instance_id> --to-id <instance_id> --queue <queue_name>",
+	Short: "Create a shovel between two instances",
+	Long: `Creates a dynamic shovel on the destination instance that moves messages from
+a queue on the source instance to a queue on the destination instance.
+
+Both instances are reached with the credentials and vhost of their instance
+URLs. The rabbitmq_shovel and rabbitmq_shovel_management plugins are enabled
+on the destination instance first if needed.
+
+By default the shovel keeps running and moves new messages as they arrive.
+With --delete-after=queue-length it moves the messages in the queue when the
+shovel starts and then deletes itself.`,
+	Example: `  cloudamqp instance shovel create --from-id 1234 --to-id 5678 --queue orders
+  cloudamqp instance shovel create --from-id 1234 --to-id 5678 --queue orders --dest-queue orders.v2 --delete-after=queue-length`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queue, _ := cmd.Flags().GetString("queue")
+		destQueue, _ := cmd.Flags().GetString("dest-queue")
+		name, _ := cmd.Flags().GetString("name")
+		deleteAfter, _ := cmd.Flags().GetString("delete-after")
+		pluginTimeoutFlag, _ := cmd.Flags().GetString("plugin-timeout")
+
+		fromID, toID, err := instancePairFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if deleteAfter != rabbitmq.ShovelDeleteNever && deleteAfter != rabbitmq.ShovelDeleteQueueLength {
+			return fmt.Errorf("invalid delete-after value %q, must be one of: never, queue-length", deleteAfter)
+		}
+		pluginTimeout, err := time.ParseDuration(pluginTimeoutFlag)
+		if err != nil {
+			return fmt.Errorf("invalid plugin-timeout value: %v", err)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		from, to, err := getInstancePair(c, fromID, toID)
+		if err != nil {
+			return commandError("getting instance", err)
+		}
+
+		if destQueue == "" {
+			destQueue = queue
+		}
+		if name == "" {
+			name = fmt.Sprintf("shovel-%s-from-%d", queue, fromID)
+		}
+
+		if err := ensurePlugins(c, toID, pluginTimeout, shovelPlugins...); err != nil {
+			return err
+		}
+
+		mgmt, err := managementClientFor(to)
+		if err != nil {
+			return err
+		}
+		vhost := mgmt.DefaultVhost()
+
+		definition := queueShovelDefinition(from.URL, queue, to.URL, destQueue, deleteAfter)
+		if err := mgmt.CreateShovel(vhost, name, definition); err != nil {
+			return commandError("creating shovel", err)
+		}
+
+		fmt.Printf("Shovel %s created on instance %d, moving queue %s from instance %d to queue %s.\n",
+			name, toID, queue, fromID, destQueue)
+		fmt.Printf("Follow the progress with: cloudamqp instance shovel status --id %d --vhost %s\n", toID, vhost)
+		return nil
+	},
+}
+
+var instanceShovelStatusCmd = &cobra.Command{
+	Use:   "status --id <instance_id>",
+	Short: "Show shovel state and transfer progress",
+	Long: `Lists the shovels running on an instance with their state, the number of
+messages forwarded so far and the number of messages remaining in the source
+queue.
+
+The forwarded count is reported by RabbitMQ 3.12 and later. When RabbitMQ
+does not report the remaining count, the source queue is looked up through
+the management API of the source.`,
+	Example: `  cloudamqp instance shovel status --id 5678
+  cloudamqp instance shovel status --id 5678 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vhost, _ := cmd.Flags().GetString("vhost")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		progress, err := shovelProgress(mgmt, vhost)
+		if err != nil {
+			return commandError("getting shovel status", err)
+		}
+
+		if jsonOutput {
+			output, err := json.MarshalIndent(progress, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format response: %v", err)
+			}
+			fmt.Println(string(output))
+			return nil
+		}
+
+		if len(progress) == 0 {
+			fmt.Println("No shovels found.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "VHOST", "NAME", "STATE", "SOURCE", "DESTINATION", "FORWARDED", "REMAINING")
+		for _, p := range progress {
+			t.AddRow(p.Vhost, p.Name, p.State,
+				shovelEndpoint(p.SourceQueue, p.SourceHost),
+				shovelEndpoint(p.DestQueue, p.DestHost),
+				optionalCount(p.Forwarded), optionalCount(p.Remaining))
+		}
+		t.Print()
+
+		return nil
+	},
+}
+
+var instanceShovelDeleteCmd = &cobra.Command{
+	Use:   "delete <name> --id <instance_id>",
+	Short: "Delete a shovel",
+	Long: `Deletes a dynamic shovel. Messages that were already moved stay in the
+destination queue.
+
+The vhost defaults to the vhost of the instance URL.`,
+	Example: `  cloudamqp instance shovel delete shovel-orders-from-1234 --id 5678`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+		vhost := managementVhost(cmd, mgmt)
+
+		if err := mgmt.DeleteShovel(vhost, name); err != nil {
+			return commandError("deleting shovel", err)
+		}
+
+		fmt.Printf("Shovel %s deleted.\n", name)
+		return nil
+	},
+}
+
+// ShovelProgress is the state and transfer progress of a shovel
+type ShovelProgress struct {
+	Vhost       string `json:"vhost"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Reason      string `json:"reason,omitempty"`
+	SourceHost  string `json:"source_host"`
+	SourceQueue string `json:"source_queue,omitempty"`
+	DestHost    string `json:"destination_host"`
+	DestQueue   string `json:"destination_queue,omitempty"`
+	Forwarded   *int   `json:"forwarded,omitempty"`
+	Remaining   *int   `json:"remaining,omitempty"`
+}
+
+// instancePairFlags parses the --from-id and --to-id flags of cmd
+func instancePairFlags(cmd *cobra.Command) (int, int, error) {
+	fromFlag, _ := cmd.Flags().GetString("from-id")
+	toFlag, _ := cmd.Flags().GetString("to-id")
+
+	fromID, err := strconv.Atoi(fromFlag)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid source instance ID: %v", err)
+	}
+	toID, err := strconv.Atoi(toFlag)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid destination instance ID: %v", err)
+	}
+	if fromID == toID {
+		return 0, 0, fmt.Errorf("source and destination must be different instances")
+	}
+	return fromID, toID, nil
+}
+
+// getInstancePair fetches the source and destination instances of a
+// shovel or federation link
+func getInstancePair(c *client.Client, fromID, toID int) (*client.Instance, *client.Instance, error) {
+	from, err := c.GetInstance(fromID)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := c.GetInstance(toID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, instance := range []*client.Instance{from, to} {
+		if instance.URL == "" {
+			return nil, nil, fmt.Errorf("instance %d has no URL, it may not be ready yet", instance.ID)
+		}
+	}
+	return from, to, nil
+}
+
+// queueShovelDefinition returns the definition of a shovel that moves
+// messages from queue at srcURI to destQueue at destURI, with publisher
+// confirms so no message is lost on the way
+func queueShovelDefinition(srcURI, queue, destURI, destQueue, deleteAfter string) rabbitmq.ShovelDefinition {
+	return rabbitmq.ShovelDefinition{
+		SrcProtocol:    "amqp091",
+		SrcURI:         srcURI,
+		SrcQueue:       queue,
+		SrcDeleteAfter: deleteAfter,
+		DestProtocol:   "amqp091",
+		DestURI:        destURI,
+		DestQueue:      destQueue,
+		AckMode:        rabbitmq.ShovelAckOnConfirm,
+	}
+}
+
+// uriWithVhost returns an AMQP URI with its vhost replaced by vhost
+func uriWithVhost(uri, vhost string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid instance URL: %w", err)
+	}
+	u.Path = "/" + vhost
+	u.RawPath = "/" + url.PathEscape(vhost)
+	return u.String(), nil
+}
+
+// shovelProgress combines the shovel definitions and runtime status on an
+// instance. Remaining counts not reported by RabbitMQ are looked up in the
+// source queue, on a best effort basis.
+func shovelProgress(mgmt *rabbitmq.Client, vhost string) ([]ShovelProgress, error) {
+	shovels, err := mgmt.ListShovels(vhost)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := mgmt.ListShovelStatus(vhost)
+	if err != nil && !rabbitmq.IsNotFound(err) {
+		return nil, err
+	}
+	statusByKey := make(map[string]rabbitmq.ShovelStatus)
+	for _, s := range statuses {
+		statusByKey[s.Vhost+"/"+s.Name] = s
+	}
+
+	sources := make(map[string]*rabbitmq.Client)
+	var progress []ShovelProgress
+	for _, shovel := range shovels {
+		p := ShovelProgress{
+			Vhost:       shovel.Vhost,
+			Name:        shovel.Name,
+			State:       "unknown",
+			SourceHost:  uriHost(shovel.Definition.SrcURI),
+			SourceQueue: shovel.Definition.SrcQueue,
+			DestHost:    uriHost(shovel.Definition.DestURI),
+			DestQueue:   shovel.Definition.DestQueue,
+		}
+
+		if status, ok := statusByKey[shovel.Vhost+"/"+shovel.Name]; ok {
+			p.State = status.State
+			p.Reason = status.Reason
+			p.Forwarded = status.Forwarded
+			p.Remaining = status.Remaining
+		}
+
+		if p.Remaining == nil && p.SourceQueue != "" {
+			p.Remaining = sourceQueueMessages(sources, shovel.Definition.SrcURI, p.SourceQueue)
+		}
+
+		progress = append(progress, p)
+	}
+
+	sort.Slice(progress, func(i, j int) bool {
+		if progress[i].Vhost != progress[j].Vhost {
+			return progress[i].Vhost < progress[j].Vhost
+		}
+		return progress[i].Name < progress[j].Name
+	})
+	return progress, nil
+}
+
+// sourceQueueMessages returns the number of messages in a queue reached
+// through the AMQP URI of a shovel source, or nil when it cannot be looked up.
+// Clients are cached in sources by URI.
+func sourceQueueMessages(sources map[string]*rabbitmq.Client, uri, queue string) *int {
+	source, ok := sources[uri]
+	if !ok {
+		source, _ = rabbitmq.NewFromURL(uri, Version)
+		sources[uri] = source
+	}
+	if source == nil {
+		return nil
+	}
+
+	q, err := source.GetQueue(source.DefaultVhost(), queue)
+	if err != nil {
+		return nil
+	}
+	return &q.Messages
+}
+
+// uriHost returns the host of an AMQP URI without credentials
+func uriHost(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return "local"
+	}
+	return u.Hostname()
+}
+
+func shovelEndpoint(queue, host string) string {
+	if queue == "" {
+		return host
+	}
+	return queue + "@" + host
+}
+
+func optionalCount(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.Itoa(*n)
+}
+
+func completeShovelDeleteAfter(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{rabbitmq.ShovelDeleteNever, rabbitmq.ShovelDeleteQueueLength}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	instanceShovelCreateCmd.Flags().String("from-id", "", "Source instance ID (required)")
+	instanceShovelCreateCmd.Flags().String("to-id", "", "Destination instance ID (required)")
+	instanceShovelCreateCmd.Flags().String("queue", "", "Source queue (required)")
+	instanceShovelCreateCmd.Flags().String("dest-queue", "", "Destination queue (default: same as --queue)")
+	instanceShovelCreateCmd.Flags().String("name", "", "Shovel name (default: shovel-<queue>-from-<from-id>)")
+	instanceShovelCreateCmd.Flags().String("delete-after", rabbitmq.ShovelDeleteNever, "When the shovel deletes itself: never or queue-length")
+	instanceShovelCreateCmd.Flags().String("plugin-timeout", "5m", "Timeout for enabling the shovel plugins")
+	instanceShovelCreateCmd.MarkFlagRequired("from-id")
+	instanceShovelCreateCmd.MarkFlagRequired("to-id")
+	instanceShovelCreateCmd.MarkFlagRequired("queue")
+	instanceShovelCreateCmd.RegisterFlagCompletionFunc("from-id", completeInstanceIDFlag)
+	instanceShovelCreateCmd.RegisterFlagCompletionFunc("to-id", completeInstanceIDFlag)
+	instanceShovelCreateCmd.RegisterFlagCompletionFunc("delete-after", completeShovelDeleteAfter)
+
+	for _, cmd := range []*cobra.Command{instanceShovelStatusCmd, instanceShovelDeleteCmd} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+	instanceShovelStatusCmd.Flags().String("vhost", "", "Only show shovels in this vhost")
+	instanceShovelStatusCmd.Flags().Bool("json", false, "Print the status as JSON")
+	instanceShovelDeleteCmd.Flags().String("vhost", "", "Vhost of the shovel (default: vhost of the instance URL)")
+
+	instanceShovelCmd.AddCommand(instanceShovelCreateCmd)
+	instanceShovelCmd.AddCommand(instanceShovelStatusCmd)
+	instanceShovelCmd.AddCommand(instanceShovelDeleteCmd)
+}
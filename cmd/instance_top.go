@@ -0,0 +1,403 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// topSortKeys is the order the s key cycles through sort keys in
+var topSortKeys = []string{"messages", "rate", "consumers", "name"}
+
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+var instanceTopCmd = &cobra.Command{
+	Use:   "top --id <instance_id>",
+	Short: "Live dashboard of nodes and queues",
+	Long: `Shows a refreshing dashboard of an instance: node status from the CloudAMQP
+API, and message rates, queue depths, connections, channels and node memory,
+disk and file descriptor usage from the RabbitMQ management API.
+
+Keys:
+  s      cycle the queue sort order (messages, rate, consumers, name)
+  /      filter queues by a regular expression, Enter to apply
+  c      clear the filter
+  r      refresh now
+  q      quit
+
+When stdout is not a terminal a plain snapshot is printed every --interval,
+which is useful for logging. Use --iterations to stop after a number of
+refreshes.`,
+	Example: `  cloudamqp instance top --id 1234
+  cloudamqp instance top --id 1234 --sort=rate --filter='^orders\.'
+  cloudamqp instance top --id 1234 --interval=30s --iterations=10 > top.log`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idFlag, _ := cmd.Flags().GetString("id")
+		intervalFlag, _ := cmd.Flags().GetString("interval")
+		iterations, _ := cmd.Flags().GetInt("iterations")
+		limit, _ := cmd.Flags().GetInt("limit")
+		sortBy, _ := cmd.Flags().GetString("sort")
+		filter, _ := cmd.Flags().GetString("filter")
+
+		interval, err := time.ParseDuration(intervalFlag)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %v", err)
+		}
+		if interval < time.Second {
+			return fmt.Errorf("interval must be at least 1s")
+		}
+		if err := sortQueues(nil, sortBy); err != nil {
+			return err
+		}
+		if _, err := compileQueueFilter(filter); err != nil {
+			return err
+		}
+
+		instanceID, err := strconv.Atoi(idFlag)
+		if err != nil {
+			return fmt.Errorf("invalid instance ID: %v", err)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		instance, err := c.GetInstance(instanceID)
+		if err != nil {
+			return commandError("getting instance", err)
+		}
+		mgmt, err := managementClientFor(instance)
+		if err != nil {
+			return err
+		}
+
+		top := &instanceTop{
+			c:        c,
+			mgmt:     mgmt,
+			instance: instance,
+			view:     topView{Sort: sortBy, Filter: filter, Limit: limit},
+		}
+
+		if stdoutIsTerminal() && stdinIsTerminal() {
+			return top.runInteractive(interval, iterations)
+		}
+		return top.runSnapshots(os.Stdout, interval, iterations)
+	},
+}
+
+// topView holds the display settings changed by key presses
+type topView struct {
+	Sort   string
+	Filter string
+	Limit  int
+
+	// editing is set while a filter is typed after /, input holds it
+	editing bool
+	input   string
+	message string
+}
+
+// topSnapshot is the data shown by one refresh
+type topSnapshot struct {
+	Time      time.Time
+	Nodes     []client.Node
+	NodeStats []rabbitmq.NodeStats
+	Overview  *rabbitmq.Overview
+	Queues    []rabbitmq.Queue
+	Errors    []string
+}
+
+type instanceTop struct {
+	c        *client.Client
+	mgmt     *rabbitmq.Client
+	instance *client.Instance
+	view     topView
+}
+
+// fetch collects a snapshot. Failing sources are reported in the snapshot
+// instead of stopping the dashboard.
+func (t *instanceTop) fetch() *topSnapshot {
+	snap := &topSnapshot{Time: time.Now()}
+
+	nodes, err := t.c.ListNodes(strconv.Itoa(t.instance.ID))
+	if err != nil {
+		snap.Errors = append(snap.Errors, fmt.Sprintf("nodes: %v", err))
+	}
+	snap.Nodes = nodes
+
+	overview, err := t.mgmt.GetOverview()
+	if err != nil {
+		snap.Errors = append(snap.Errors, fmt.Sprintf("overview: %v", err))
+	}
+	snap.Overview = overview
+
+	// Node metrics need the monitoring tag, which users on shared plans lack
+	if stats, err := t.mgmt.ListNodes(); err == nil {
+		snap.NodeStats = stats
+	}
+
+	queues, err := t.mgmt.ListQueues("")
+	if err != nil {
+		snap.Errors = append(snap.Errors, fmt.Sprintf("queues: %v", err))
+	}
+	snap.Queues = queues
+
+	return snap
+}
+
+func (t *instanceTop) runSnapshots(w io.Writer, interval time.Duration, iterations int) error {
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+			fmt.Fprintln(w)
+		}
+		renderTop(w, t.instance, t.fetch(), &t.view, false)
+	}
+	return nil
+}
+
+func (t *instanceTop) runInteractive(interval time.Duration, iterations int) error {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return t.runSnapshots(os.Stdout, interval, iterations)
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	// Hide the cursor while the dashboard runs
+	fmt.Print("\033[?25l")
+	defer fmt.Print("\033[?25h\r\n")
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := os.Stdin.Read(buf); err != nil {
+				close(keys)
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+
+	snap := t.fetch()
+	draw := func() {
+		var buf bytes.Buffer
+		renderTop(&buf, t.instance, snap, &t.view, true)
+		// Raw mode does not translate newlines into carriage return and newline
+		fmt.Print("\033[H\033[2J" + strings.ReplaceAll(buf.String(), "\n", "\r\n"))
+	}
+	draw()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for refreshes := 1; iterations == 0 || refreshes < iterations; {
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			quit, refresh := handleTopKey(&t.view, key)
+			if quit {
+				return nil
+			}
+			if refresh {
+				snap = t.fetch()
+				refreshes++
+			}
+		case <-ticker.C:
+			snap = t.fetch()
+			refreshes++
+		}
+		draw()
+	}
+	return nil
+}
+
+// handleTopKey applies a key press to the view and reports whether the
+// dashboard should quit or refresh
+func handleTopKey(view *topView, key byte) (quit, refresh bool) {
+	view.message = ""
+
+	if view.editing {
+		switch key {
+		case '\r', '\n':
+			if _, err := compileQueueFilter(view.input); err != nil {
+				view.message = err.Error()
+			} else {
+				view.Filter = view.input
+			}
+			view.editing = false
+		case 27: // Escape
+			view.editing = false
+		case 127, 8: // Backspace
+			if len(view.input) > 0 {
+				view.input = view.input[:len(view.input)-1]
+			}
+		case 3: // Ctrl+C
+			return true, false
+		default:
+			if key >= 32 && key < 127 {
+				view.input += string(key)
+			}
+		}
+		return false, false
+	}
+
+	switch key {
+	case 'q', 3: // q or Ctrl+C
+		return true, false
+	case 's':
+		next := 0
+		for i, k := range topSortKeys {
+			if k == view.Sort {
+				next = (i + 1) % len(topSortKeys)
+			}
+		}
+		view.Sort = topSortKeys[next]
+	case '/':
+		view.editing = true
+		view.input = view.Filter
+	case 'c':
+		view.Filter = ""
+	case 'r':
+		return false, true
+	}
+	return false, false
+}
+
+// renderTop writes one screen of the dashboard
+func renderTop(w io.Writer, instance *client.Instance, snap *topSnapshot, view *topView, interactive bool) {
+	filterText := view.Filter
+	if filterText == "" {
+		filterText = "none"
+	}
+	fmt.Fprintf(w, "%s (%d)  %s  sort: %s  filter: %s\n",
+		instance.Name, instance.ID, snap.Time.Format("15:04:05"), view.Sort, filterText)
+
+	if o := snap.Overview; o != nil {
+		fmt.Fprintf(w, "Connections %d  Channels %d  Consumers %d  Queues %d  Messages %d (ready %d, unacked %d)\n",
+			o.ObjectTotals.Connections, o.ObjectTotals.Channels, o.ObjectTotals.Consumers, o.ObjectTotals.Queues,
+			o.QueueTotals.Messages, o.QueueTotals.MessagesReady, o.QueueTotals.MessagesUnacknowledged)
+		fmt.Fprintf(w, "Publish %.1f/s  Deliver %.1f/s  Ack %.1f/s\n",
+			o.MessageStats.PublishDetails.Rate, o.MessageStats.DeliverGetDetails.Rate, o.MessageStats.AckDetails.Rate)
+	}
+	for _, e := range snap.Errors {
+		fmt.Fprintf(w, "Error: %s\n", e)
+	}
+
+	if len(snap.Nodes) > 0 {
+		fmt.Fprintln(w)
+		stats := make(map[string]rabbitmq.NodeStats)
+		for _, s := range snap.NodeStats {
+			stats[s.Hostname()] = s
+		}
+
+		t := table.New(w, "NODE", "RUNNING", "VERSION", "MEMORY", "DISK_FREE", "FDS", "ALARMS")
+		for _, n := range snap.Nodes {
+			memory, diskFree, fds, alarms := "-", "-", "-", "-"
+			if s, ok := stats[n.Name]; ok {
+				memory = fmt.Sprintf("%s/%s", formatBytes(s.MemUsed), formatBytes(s.MemLimit))
+				diskFree = formatBytes(s.DiskFree)
+				fds = fmt.Sprintf("%d/%d", s.FDUsed, s.FDTotal)
+				alarms = nodeAlarms(s)
+			}
+			t.AddRow(n.Name, yesNo(n.Running), n.RabbitMQVersion, memory, diskFree, fds, alarms)
+		}
+		t.Print()
+	}
+
+	pattern, _ := compileQueueFilter(view.Filter)
+	queues := filterQueues(append([]rabbitmq.Queue(nil), snap.Queues...), pattern)
+	sortQueues(queues, view.Sort)
+
+	fmt.Fprintln(w)
+	if len(queues) == 0 {
+		fmt.Fprintln(w, "No queues found.")
+	} else {
+		shown := queues
+		if view.Limit > 0 && len(shown) > view.Limit {
+			shown = shown[:view.Limit]
+		}
+		t := table.New(w, "VHOST", "NAME", "STATE", "MESSAGES", "READY", "UNACKED", "CONSUMERS", "PUBLISH/S", "DELIVER/S")
+		for _, q := range shown {
+			t.AddRow(q.Vhost, q.Name, q.State,
+				strconv.Itoa(q.Messages), strconv.Itoa(q.MessagesReady), strconv.Itoa(q.MessagesUnacknowledged),
+				strconv.Itoa(q.Consumers),
+				fmt.Sprintf("%.1f", q.MessageStats.PublishDetails.Rate),
+				fmt.Sprintf("%.1f", q.MessageStats.DeliverGetDetails.Rate))
+		}
+		t.Print()
+		if len(shown) < len(queues) {
+			fmt.Fprintf(w, "... %d more queues\n", len(queues)-len(shown))
+		}
+	}
+
+	if !interactive {
+		return
+	}
+	fmt.Fprintln(w)
+	switch {
+	case view.editing:
+		fmt.Fprintf(w, "Filter: %s", view.input)
+	case view.message != "":
+		fmt.Fprintln(w, view.message)
+	default:
+		fmt.Fprintln(w, "[s] sort  [/] filter  [c] clear filter  [r] refresh  [q] quit")
+	}
+}
+
+func nodeAlarms(s rabbitmq.NodeStats) string {
+	var alarms []string
+	if s.MemAlarm {
+		alarms = append(alarms, "memory")
+	}
+	if s.DiskFreeAlarm {
+		alarms = append(alarms, "disk")
+	}
+	if len(alarms) == 0 {
+		return "none"
+	}
+	return strings.Join(alarms, ",")
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. 1.5GiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	instanceTopCmd.Flags().StringP("id", "", "", "Instance ID (required)")
+	instanceTopCmd.Flags().String("interval", "5s", "Refresh interval")
+	instanceTopCmd.Flags().Int("iterations", 0, "Stop after this many refreshes (0 runs until quit)")
+	instanceTopCmd.Flags().Int("limit", 20, "Maximum number of queues to show (0 shows all)")
+	instanceTopCmd.Flags().String("sort", "messages", "Sort queues by name, messages, consumers or rate")
+	instanceTopCmd.Flags().String("filter", "", "Regular expression matched against queue names")
+	instanceTopCmd.MarkFlagRequired("id")
+	instanceTopCmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	instanceTopCmd.RegisterFlagCompletionFunc("sort", completeQueueSort)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleTopKey(t *testing.T) {
+	view := &topView{Sort: "messages"}
+
+	handleTopKey(view, 's')
+	assert.Equal(t, "rate", view.Sort)
+	for i := 0; i < 3; i++ {
+		handleTopKey(view, 's')
+	}
+	assert.Equal(t, "messages", view.Sort)
+
+	handleTopKey(view, '/')
+	assert.True(t, view.editing)
+	for _, key := range []byte("ordx") {
+		handleTopKey(view, key)
+	}
+	handleTopKey(view, 127)
+	handleTopKey(view, 'e')
+	handleTopKey(view, '\r')
+	assert.False(t, view.editing)
+	assert.Equal(t, "orde", view.Filter)
+
+	handleTopKey(view, '/')
+	handleTopKey(view, '(')
+	handleTopKey(view, '\r')
+	assert.Equal(t, "orde", view.Filter, "invalid filters are not applied")
+	assert.Contains(t, view.message, "invalid filter")
+
+	handleTopKey(view, 'c')
+	assert.Equal(t, "", view.Filter)
+
+	quit, refresh := handleTopKey(view, 'r')
+	assert.False(t, quit)
+	assert.True(t, refresh)
+
+	quit, _ = handleTopKey(view, 'q')
+	assert.True(t, quit)
+}
+
+func TestRenderTop(t *testing.T) {
+	instance := &client.Instance{ID: 1234, Name: "prod"}
+	snap := &topSnapshot{
+		Time:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		Nodes:     []client.Node{{Name: "prod-01", Running: true, RabbitMQVersion: "3.13.7"}},
+		NodeStats: []rabbitmq.NodeStats{{Name: "rabbit@prod-01", MemUsed: 512 << 20, MemLimit: 2 << 30, FDUsed: 40, FDTotal: 1000, DiskFreeAlarm: true}},
+		Overview:  &rabbitmq.Overview{ObjectTotals: rabbitmq.ObjectTotals{Connections: 3, Channels: 7}},
+		Queues: []rabbitmq.Queue{
+			{Name: "orders", Vhost: "/", Messages: 10},
+			{Name: "events", Vhost: "/", Messages: 50},
+			{Name: "orders.dlq", Vhost: "/", Messages: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	renderTop(&buf, instance, snap, &topView{Sort: "messages", Filter: "^orders", Limit: 1}, false)
+	out := buf.String()
+
+	assert.Contains(t, out, "prod (1234)  15:04:05  sort: messages  filter: ^orders")
+	assert.Contains(t, out, "Connections 3  Channels 7")
+	assert.Contains(t, out, "512.0MiB/2.0GiB")
+	assert.Contains(t, out, "40/1000")
+	assert.Contains(t, out, "disk")
+	assert.Contains(t, out, "orders")
+	assert.NotContains(t, out, "events")
+	assert.Contains(t, out, "... 1 more queues")
+	assert.NotContains(t, out, "[q] quit")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512B", formatBytes(512))
+	assert.Equal(t, "1.5KiB", formatBytes(1536))
+	assert.Equal(t, "3.0GiB", formatBytes(3<<30))
+}
@@ -23,9 +23,19 @@ var instanceUpdateCmd = &cobra.Command{
 You can update the following fields:
   --name: Instance name
   --plan: Subscription plan
-  --tags: Instance tags (replaces existing tags)`,
+  --tags: Instance tags (replaces existing tags)
+
+Before changing the plan, the new plan is checked against the plan list: it
+must exist, be for the same backend (RabbitMQ or LavinMQ) as the instance, and
+not switch between shared and dedicated plans, which the API rejects; use
+"instance migrate" for those changes. Changes in node count and the expected
+downtime are printed with the change in monthly price.
+
+Changing the plan is asynchronous. Use --wait to wait until the instance is
+ready on the new plan.`,
 	Example: `  cloudamqp instance update --id 1234 --name=new-name
   cloudamqp instance update --id 1234 --plan=rabbit-1
+  cloudamqp instance update --id 1234 --plan=rabbit-1 --wait
   cloudamqp instance update --id 1234 --tags=production --tags=updated`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -44,7 +54,7 @@ You can update the following fields:
 			return fmt.Errorf("invalid instance ID: %v", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		req := &client.InstanceUpdateRequest{
 			Name: updateInstanceName,
@@ -56,22 +66,131 @@ You can update the following fields:
 			return fmt.Errorf("at least one field must be specified for update")
 		}
 
+		if req.Plan != "" {
+			instance, err := c.GetInstance(instanceID)
+			if err != nil {
+				return commandError("getting instance", err)
+			}
+			plans, err := c.Cached().ListPlans("")
+			if err != nil {
+				return commandError("listing plans", err)
+			}
+			nodes, err := c.ListNodes(updateInstanceID)
+			if err != nil {
+				return commandError("listing nodes", err)
+			}
+			change, err := planChangePreflight(instance, len(nodes), plans, req.Plan)
+			if err != nil {
+				return err
+			}
+			printPlanChange(change)
+
+			downgrade, err := isPlanDowngrade(c, instance.Plan, req.Plan)
+			if err != nil {
+				return err
+			}
+			if downgrade {
+				if err := checkInstanceProtection(cmd, c, instanceID, "downgrade"); err != nil {
+					return err
+				}
+			}
+		}
+
 		err = c.UpdateInstance(instanceID, req)
 		if err != nil {
-			fmt.Printf("Error updating instance: %v\n", err)
-			return err
+			return commandError("updating instance", err)
 		}
 
 		fmt.Printf("Instance %d updated successfully.\n", instanceID)
-		return nil
+
+		if req.Plan != "" {
+			return waitIfRequested(cmd, c, updateInstanceID, "plan="+req.Plan, nil, nil)
+		}
+		return waitIfRequested(cmd, c, updateInstanceID, "instance-ready", nil, nil)
 	},
 }
 
+// planChange describes a checked plan change
+type planChange struct {
+	From       client.Plan
+	To         client.Plan
+	FromNodes  int
+	ToNodes    int
+	PriceDelta float64
+	PriceKnown bool
+	Warnings   []string
+}
+
+// planChangePreflight checks that instance, currently running nodes nodes,
+// can change to the plan named target. Unknown current plans are only
+// warned about, since the instance may be on a plan no longer sold.
+func planChangePreflight(instance *client.Instance, nodes int, plans []client.Plan, target string) (*planChange, error) {
+	if target == instance.Plan {
+		return nil, fmt.Errorf("instance %d is already on plan %s", instance.ID, target)
+	}
+
+	to, ok := findPlan(plans, target)
+	if !ok {
+		return nil, fmt.Errorf("unknown plan %q, see 'cloudamqp plans' for available plans", target)
+	}
+
+	change := &planChange{To: to, ToNodes: planNodeCount(to.Name), FromNodes: nodes}
+	from, fromOK := findPlan(plans, instance.Plan)
+	if fromOK {
+		change.From = from
+		change.PriceDelta = to.Price - from.Price
+		change.PriceKnown = true
+	} else {
+		change.From = client.Plan{Name: instance.Plan, Backend: instance.Backend}
+		change.Warnings = append(change.Warnings, fmt.Sprintf("current plan %s is not in the plan list, the price change is unknown", instance.Plan))
+	}
+
+	backend := instance.Backend
+	if backend == "" {
+		backend = change.From.Backend
+	}
+	if backend != "" && to.Backend != backend {
+		return nil, fmt.Errorf("plan %s is a %s plan but instance %d runs %s", to.Name, to.Backend, instance.ID, backend)
+	}
+	if fromOK && from.Shared != to.Shared {
+		return nil, fmt.Errorf("cannot change between shared and dedicated plans (%s to %s), use 'cloudamqp instance migrate' instead", from.Name, to.Name)
+	}
+
+	if to.Shared {
+		return change, nil
+	}
+	if change.FromNodes == 0 {
+		change.FromNodes = planNodeCount(instance.Plan)
+	}
+	if change.FromNodes != change.ToNodes {
+		change.Warnings = append(change.Warnings, fmt.Sprintf("the node count changes from %d to %d, check queue replication and client connection settings", change.FromNodes, change.ToNodes))
+	}
+	if change.FromNodes == 1 {
+		change.Warnings = append(change.Warnings, "the instance has a single node and will be unavailable while the node is replaced")
+	} else {
+		change.Warnings = append(change.Warnings, "nodes are replaced one at a time, clients will be disconnected and must reconnect")
+	}
+	return change, nil
+}
+
+func printPlanChange(change *planChange) {
+	price := "price change unknown"
+	if change.PriceKnown {
+		price = fmt.Sprintf("%+.2f USD/month", change.PriceDelta)
+	}
+	fmt.Printf("Changing plan from %s to %s (%s/month, %s).\n", change.From.Name, change.To.Name, formatPrice(change.To.Price), price)
+	for _, warning := range change.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
 func init() {
 	instanceUpdateCmd.Flags().StringVar(&updateInstanceID, "id", "", "Instance ID (required)")
 	instanceUpdateCmd.Flags().StringVar(&updateInstanceName, "name", "", "New instance name")
 	instanceUpdateCmd.Flags().StringVar(&updateInstancePlan, "plan", "", "New subscription plan")
 	instanceUpdateCmd.Flags().StringSliceVar(&updateInstanceTags, "tags", []string{}, "New instance tags")
+	addWaitFlags(instanceUpdateCmd, "30m")
+	addProtectionFlag(instanceUpdateCmd)
 	instanceUpdateCmd.MarkFlagRequired("id")
 	instanceUpdateCmd.RegisterFlagCompletionFunc("id", completeInstances)
 	instanceUpdateCmd.RegisterFlagCompletionFunc("plan", completePlans)
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"cloudamqp-cli/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanChangePreflight(t *testing.T) {
+	plans := []client.Plan{
+		{Name: "lemur", Price: 0, Backend: "rabbitmq", Shared: true},
+		{Name: "bunny-1", Price: 99, Backend: "rabbitmq"},
+		{Name: "rabbit-1", Price: 299, Backend: "rabbitmq"},
+		{Name: "rabbit-3", Price: 897, Backend: "rabbitmq"},
+		{Name: "penguin-1", Price: 199, Backend: "lavinmq"},
+	}
+	instance := &client.Instance{ID: 1234, Plan: "bunny-1", Backend: "rabbitmq"}
+
+	change, err := planChangePreflight(instance, 1, plans, "rabbit-1")
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, change.PriceDelta)
+	assert.True(t, change.PriceKnown)
+	assert.Equal(t, []string{"the instance has a single node and will be unavailable while the node is replaced"}, change.Warnings)
+
+	change, err = planChangePreflight(instance, 1, plans, "rabbit-3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, change.ToNodes)
+	assert.Contains(t, change.Warnings[0], "node count changes from 1 to 3")
+
+	_, err = planChangePreflight(instance, 1, plans, "bunny-1")
+	assert.ErrorContains(t, err, "already on plan")
+
+	_, err = planChangePreflight(instance, 1, plans, "hippo-9")
+	assert.ErrorContains(t, err, "unknown plan")
+
+	_, err = planChangePreflight(instance, 1, plans, "penguin-1")
+	assert.ErrorContains(t, err, "lavinmq plan but instance 1234 runs rabbitmq")
+
+	_, err = planChangePreflight(instance, 1, plans, "lemur")
+	assert.ErrorContains(t, err, "between shared and dedicated")
+
+	retired := &client.Instance{ID: 5, Plan: "retired-1", Backend: "rabbitmq"}
+	change, err = planChangePreflight(retired, 3, plans, "rabbit-3")
+	require.NoError(t, err)
+	assert.False(t, change.PriceKnown)
+	assert.Contains(t, change.Warnings[0], "not in the plan list")
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/internal/wait"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeTargetVersion string
+	upgradeInterval      string
+	upgradeWaitTimeout   string
+)
+
+var instanceUpgradeCmd = &cobra.Command{
+	Use:   "upgrade --id <instance_id> --to <version>",
+	Short: "Upgrade RabbitMQ with preflight checks and wait for completion",
+	Long: `Upgrade RabbitMQ to the given version and wait until every node runs it.
+
+Before the upgrade is triggered the target version is validated against the
+versions available for the instance, the current per-node versions and the
+planned result are shown, and confirmation is requested. After the upgrade has
+been triggered the nodes are polled until all of them report the new version.`,
+	Example: `  cloudamqp instance upgrade --id 1234 --to 3.13.7
+  cloudamqp instance upgrade --id 1234 --to 3.13.7 --force --wait-timeout=45m`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idFlag, _ := cmd.Flags().GetString("id")
+		if idFlag == "" {
+			return fmt.Errorf("instance ID is required. Use --id flag")
+		}
+		if upgradeTargetVersion == "" {
+			return fmt.Errorf("--to is required")
+		}
+
+		interval, err := time.ParseDuration(upgradeInterval)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %v", err)
+		}
+		timeout, err := time.ParseDuration(upgradeWaitTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid wait-timeout value: %v", err)
+		}
+
+		apiKey, err := getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
+
+		// Preflight: validate the target version
+		available, err := c.GetAvailableVersions(idFlag)
+		if err != nil {
+			return commandError("getting available versions", err)
+		}
+		if len(available.LavinMQVersions) > 0 {
+			return fmt.Errorf("instance runs LavinMQ, upgrade only supports RabbitMQ instances")
+		}
+		if !containsString(available.RabbitMQVersions, upgradeTargetVersion) {
+			return fmt.Errorf("version %s is not available for this instance. Available versions: %s",
+				upgradeTargetVersion, strings.Join(available.RabbitMQVersions, ", "))
+		}
+
+		// Preflight: show current and planned versions
+		nodes, err := c.ListNodes(idFlag)
+		if err != nil {
+			return commandError("listing nodes", err)
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("instance has no nodes")
+		}
+
+		fmt.Println("Current versions:")
+		printNodeVersions(nodes)
+
+		planned, err := c.GetUpgradeVersions(idFlag)
+		if err != nil {
+			return commandError("getting upgrade versions", err)
+		}
+
+		expectedErlang := ""
+		if plannedVersion(planned, "rabbitmq") == upgradeTargetVersion {
+			expectedErlang = plannedVersion(planned, "erlang")
+		}
+
+		fmt.Println("\nPlanned result:")
+		fmt.Printf("RabbitMQ version = %s\n", upgradeTargetVersion)
+		if expectedErlang != "" {
+			fmt.Printf("Erlang version = %s\n", expectedErlang)
+		}
+		keys := make([]string, 0, len(planned))
+		for key := range planned {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("Latest compatible %s = %s\n", key, planned[key])
+		}
+
+		fmt.Println()
+		confirmed, err := confirmInstanceAction(cmd, c, idFlag, "upgrade RabbitMQ to "+upgradeTargetVersion+" on")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Upgrade cancelled.")
+			return nil
+		}
+
+		if err := c.UpgradeRabbitMQ(idFlag, upgradeTargetVersion); err != nil {
+			return commandError("performing upgrade-rabbitmq", err)
+		}
+		fmt.Printf("Upgrade to RabbitMQ %s initiated.\n", upgradeTargetVersion)
+
+		if err := waitForNodesVersion(c, idFlag, upgradeTargetVersion, expectedErlang, interval, timeout); err != nil {
+			if nodes, listErr := c.ListNodes(idFlag); listErr == nil {
+				fmt.Println("\nNode versions:")
+				printNodeVersions(nodes)
+			}
+			return fmt.Errorf("wait failed: %w", err)
+		}
+
+		fmt.Printf("All nodes are running RabbitMQ %s.\n", upgradeTargetVersion)
+		return nil
+	},
+}
+
+// plannedVersion returns the first version in the upgrade-versions response
+// whose key mentions the given component, e.g. "rabbitmq" or "erlang".
+func plannedVersion(planned map[string]string, component string) string {
+	keys := make([]string, 0, len(planned))
+	for key := range planned {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.Contains(strings.ToLower(key), component) {
+			return planned[key]
+		}
+	}
+	return ""
+}
+
+// nodesUpgraded counts the nodes that run the expected versions. An empty
+// erlangVersion matches any Erlang version.
+func nodesUpgraded(nodes []client.Node, rabbitMQVersion, erlangVersion string) int {
+	done := 0
+	for _, node := range nodes {
+		if node.RabbitMQVersion != rabbitMQVersion {
+			continue
+		}
+		if erlangVersion != "" && node.ErlangVersion != erlangVersion {
+			continue
+		}
+		done++
+	}
+	return done
+}
+
+func waitForNodesVersion(c *client.Client, instanceID, rabbitMQVersion, erlangVersion string, interval, timeout time.Duration) error {
+	check := func() (bool, string, error) {
+		nodes, err := c.ListNodes(instanceID)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to check node status: %w", err)
+		}
+		done := nodesUpgraded(nodes, rabbitMQVersion, erlangVersion)
+		return len(nodes) > 0 && done == len(nodes), fmt.Sprintf("%d/%d nodes upgraded", done, len(nodes)), nil
+	}
+
+	opts := wait.DefaultOptions(timeout)
+	opts.Interval = interval
+	opts.InitialDelay = interval
+	opts.Progress = printWaitProgress("instance " + instanceID)
+	return wait.Until(context.Background(), "version="+rabbitMQVersion, check, opts)
+}
+
+func printNodeVersions(nodes []client.Node) {
+	t := table.New(os.Stdout, "NAME", "RABBITMQ_VERSION", "ERLANG_VERSION", "RUNNING")
+	for _, node := range nodes {
+		running := "No"
+		if node.Running {
+			running = "Yes"
+		}
+		t.AddRow(node.Name, node.RabbitMQVersion, node.ErlangVersion, running)
+	}
+	t.Print()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	instanceUpgradeCmd.Flags().StringP("id", "", "", "Instance ID (required)")
+	instanceUpgradeCmd.Flags().StringVar(&upgradeTargetVersion, "to", "", "Target RabbitMQ version (required)")
+	addConfirmFlags(instanceUpgradeCmd)
+	instanceUpgradeCmd.Flags().StringVar(&upgradeInterval, "interval", "15s", "Polling interval while waiting for the upgrade")
+	instanceUpgradeCmd.Flags().StringVar(&upgradeWaitTimeout, "wait-timeout", "30m", "Timeout for waiting (e.g., 30m, 1h)")
+	instanceUpgradeCmd.MarkFlagRequired("id")
+	instanceUpgradeCmd.MarkFlagRequired("to")
+	instanceUpgradeCmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+// generatedPasswordLength is the length of passwords generated for new users
+const generatedPasswordLength = 32
+
+const passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var instanceUsersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage RabbitMQ users",
+	Long: `List, create, and delete RabbitMQ users and set their tags through the
+RabbitMQ management API.
+
+The management API is reached with the credentials of the instance URL.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceUsersListCmd = &cobra.Command{
+	Use:     "list --id <instance_id>",
+	Short:   "List users",
+	Long:    `Lists all RabbitMQ users and their tags.`,
+	Example: `  cloudamqp instance users list --id 1234`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		users, err := mgmt.ListUsers()
+		if err != nil {
+			return commandError("listing users", err)
+		}
+
+		if len(users) == 0 {
+			fmt.Println("No users found.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "NAME", "TAGS")
+		for _, u := range users {
+			t.AddRow(u.Name, strings.Join(u.Tags, ","))
+		}
+		t.Print()
+
+		return nil
+	},
+}
+
+var instanceUsersCreateCmd = &cobra.Command{
+	Use:   "create <username> --id <instance_id>",
+	Short: "Create a user",
+	Long: `Creates a RabbitMQ user. Creating a user that already exists fails, unless
+--force is given to replace its password and tags.
+
+With --password-stdin the password is read from stdin. Otherwise a random
+password is generated and printed to stdout. The generated password is shown
+only once, store it right away. All other output goes to stderr, so the
+password can be captured by a script.
+
+The new user has no access to any vhost until permissions are granted with
+'cloudamqp instance permissions set'.`,
+	Example: `  cloudamqp instance users create orders-app --id 1234
+  PASSWORD=$(cloudamqp instance users create orders-app --id 1234 --tags=monitoring)
+  echo "$SECRET" | cloudamqp instance users create orders-app --id 1234 --password-stdin
+  cloudamqp instance users create orders-app --id 1234 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+		passwordStdin, _ := cmd.Flags().GetBool("password-stdin")
+		force, _ := cmd.Flags().GetBool("force")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !force {
+			if err := checkUserAbsent(mgmt, name); err != nil {
+				return err
+			}
+		}
+
+		var password string
+		if passwordStdin {
+			password, err = readPasswordFrom(os.Stdin)
+		} else {
+			password, err = generatePassword(generatedPasswordLength)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := mgmt.CreateUser(name, password, tags); err != nil {
+			return commandError("creating user", err)
+		}
+
+		if passwordStdin {
+			fmt.Printf("User %s created.\n", name)
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "User %s created. The generated password is shown only once:\n", name)
+		fmt.Println(password)
+		return nil
+	},
+}
+
+// checkUserAbsent returns an error when the user exists. Creating a user is a
+// PUT, which would silently replace the password of an existing user and break
+// its clients.
+func checkUserAbsent(mgmt *rabbitmq.Client, name string) error {
+	_, err := mgmt.GetUser(name)
+	if err == nil {
+		return fmt.Errorf("user %s exists, use --force to replace its password and tags", name)
+	}
+	if !rabbitmq.IsNotFound(err) {
+		return fmt.Errorf("failed to check user %s: %w", name, err)
+	}
+	return nil
+}
+
+var instanceUsersDeleteCmd = &cobra.Command{
+	Use:   "delete <username> --id <instance_id>",
+	Short: "Delete a user",
+	Long: `Deletes a RabbitMQ user and its permissions. Open connections of the user
+are closed.`,
+	Example: `  cloudamqp instance users delete orders-app --id 1234
+  cloudamqp instance users delete orders-app --id 1234 --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		confirmed, err := confirmAction(cmd, fmt.Sprintf("delete user %s and close its connections", name), "user", name)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Delete operation cancelled.")
+			return nil
+		}
+
+		if err := mgmt.DeleteUser(name); err != nil {
+			return commandError("deleting user", err)
+		}
+
+		fmt.Printf("User %s deleted.\n", name)
+		return nil
+	},
+}
+
+var instanceUsersSetTagsCmd = &cobra.Command{
+	Use:   "set-tags <username> --id <instance_id> --tags <tags>",
+	Short: "Set the tags of a user",
+	Long: `Replaces the tags of a RabbitMQ user. The password of the user is kept.
+
+Common tags are administrator, monitoring, policymaker and management. Pass
+an empty --tags to remove all tags.`,
+	Example: `  cloudamqp instance users set-tags orders-app --id 1234 --tags=monitoring
+  cloudamqp instance users set-tags orders-app --id 1234 --tags=management,policymaker
+  cloudamqp instance users set-tags orders-app --id 1234 --tags=""`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := mgmt.SetUserTags(name, tags); err != nil {
+			return commandError("setting user tags", err)
+		}
+
+		fmt.Printf("Tags of user %s set to: %s\n", name, strings.Join(tags, ","))
+		return nil
+	},
+}
+
+// generatePassword returns a random password of letters and digits
+func generatePassword(length int) (string, error) {
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %v", err)
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// readPasswordFrom reads a password from r, without the trailing newline
+func readPasswordFrom(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password from stdin is empty")
+	}
+	return password, nil
+}
+
+func completeUserTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"administrator", "monitoring", "policymaker", "management", "impersonator"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		instanceUsersListCmd, instanceUsersCreateCmd,
+		instanceUsersDeleteCmd, instanceUsersSetTagsCmd,
+	} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instanceUsersCreateCmd.Flags().StringSlice("tags", []string{}, "Comma-separated user tags")
+	instanceUsersCreateCmd.Flags().Bool("password-stdin", false, "Read the password from stdin instead of generating one")
+	instanceUsersCreateCmd.Flags().Bool("force", false, "Replace the password and tags of an existing user")
+	instanceUsersCreateCmd.RegisterFlagCompletionFunc("tags", completeUserTags)
+
+	instanceUsersSetTagsCmd.Flags().StringSlice("tags", []string{}, "Comma-separated user tags (required)")
+	instanceUsersSetTagsCmd.MarkFlagRequired("tags")
+	instanceUsersSetTagsCmd.RegisterFlagCompletionFunc("tags", completeUserTags)
+
+	addConfirmFlags(instanceUsersDeleteCmd)
+
+	instanceUsersCmd.AddCommand(instanceUsersListCmd)
+	instanceUsersCmd.AddCommand(instanceUsersCreateCmd)
+	instanceUsersCmd.AddCommand(instanceUsersDeleteCmd)
+	instanceUsersCmd.AddCommand(instanceUsersSetTagsCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloudamqp-cli/rabbitmq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	first, err := generatePassword(32)
+	assert.NoError(t, err)
+	assert.Len(t, first, 32)
+	for _, r := range first {
+		assert.True(t, strings.ContainsRune(passwordAlphabet, r), "unexpected character %q", r)
+	}
+
+	second, err := generatePassword(32)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+}
+
+func TestReadPassword(t *testing.T) {
+	password, err := readPasswordFrom(strings.NewReader("s3cret pass\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret pass", password)
+
+	_, err = readPasswordFrom(strings.NewReader("\n"))
+	assert.Error(t, err)
+}
+
+func TestCheckUserAbsent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users/orders-app":
+			w.Write([]byte(`{"name":"orders-app","tags":["monitoring"]}`))
+		case "/api/users/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Object Not Found","reason":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	mgmt := rabbitmq.New(server.URL, "user", "secret", "test")
+
+	err := checkUserAbsent(mgmt, "orders-app")
+	assert.ErrorContains(t, err, "user orders-app exists")
+	assert.ErrorContains(t, err, "--force")
+
+	assert.NoError(t, checkUserAbsent(mgmt, "new-app"))
+	assert.Error(t, checkUserAbsent(mgmt, "broken"))
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+var instanceVhostsCmd = &cobra.Command{
+	Use:   "vhosts",
+	Short: "Manage vhosts",
+	Long: `List, create, and delete vhosts through the RabbitMQ management API.
+
+The management API is reached with the credentials of the instance URL.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Help()
+		cmd.SilenceUsage = true
+		return fmt.Errorf("subcommand required")
+	},
+}
+
+var instanceVhostsListCmd = &cobra.Command{
+	Use:     "list --id <instance_id>",
+	Short:   "List vhosts",
+	Long:    `Lists all vhosts with their description, default queue type and message count.`,
+	Example: `  cloudamqp instance vhosts list --id 1234`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		vhosts, err := mgmt.ListVhosts()
+		if err != nil {
+			return commandError("listing vhosts", err)
+		}
+
+		if len(vhosts) == 0 {
+			fmt.Println("No vhosts found.")
+			return nil
+		}
+
+		t := table.New(os.Stdout, "NAME", "DESCRIPTION", "DEFAULT_QUEUE_TYPE", "MESSAGES")
+		for _, v := range vhosts {
+			t.AddRow(v.Name, v.Description, v.DefaultQueueType, fmt.Sprintf("%d", v.Messages))
+		}
+		t.Print()
+
+		return nil
+	},
+}
+
+var instanceVhostsCreateCmd = &cobra.Command{
+	Use:   "create <vhost> --id <instance_id>",
+	Short: "Create a vhost",
+	Long: `Creates a vhost. Creating a vhost that already exists updates its description
+and default queue type.
+
+The instance user is not granted access to the new vhost automatically, use
+'cloudamqp instance permissions set' to grant access.`,
+	Example: `  cloudamqp instance vhosts create orders --id 1234
+  cloudamqp instance vhosts create orders --id 1234 --description="Order service" --default-queue-type=quorum`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		description, _ := cmd.Flags().GetString("description")
+		queueType, _ := cmd.Flags().GetString("default-queue-type")
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		settings := rabbitmq.VhostSettings{Description: description, DefaultQueueType: queueType}
+		if err := mgmt.CreateVhost(name, settings); err != nil {
+			return commandError("creating vhost", err)
+		}
+
+		fmt.Printf("Vhost %s created.\n", name)
+		return nil
+	},
+}
+
+var instanceVhostsDeleteCmd = &cobra.Command{
+	Use:   "delete <vhost> --id <instance_id>",
+	Short: "Delete a vhost",
+	Long: `Deletes a vhost with all its queues, exchanges, bindings, policies and
+messages. This cannot be undone.`,
+	Example: `  cloudamqp instance vhosts delete orders --id 1234
+  cloudamqp instance vhosts delete orders --id 1234 --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		mgmt, err := instanceManagementClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		confirmed, err := confirmAction(cmd, fmt.Sprintf("delete vhost %s with all its queues and messages", name), "vhost", name)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Delete operation cancelled.")
+			return nil
+		}
+
+		if err := mgmt.DeleteVhost(name); err != nil {
+			return commandError("deleting vhost", err)
+		}
+
+		fmt.Printf("Vhost %s deleted.\n", name)
+		return nil
+	},
+}
+
+func completeQueueTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"classic", "quorum", "stream"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		instanceVhostsListCmd, instanceVhostsCreateCmd, instanceVhostsDeleteCmd,
+	} {
+		cmd.Flags().StringP("id", "", "", "Instance ID (required)")
+		cmd.MarkFlagRequired("id")
+		cmd.RegisterFlagCompletionFunc("id", completeInstanceIDFlag)
+	}
+
+	instanceVhostsCreateCmd.Flags().String("description", "", "Description of the vhost")
+	instanceVhostsCreateCmd.Flags().String("default-queue-type", "", "Default queue type: classic, quorum or stream")
+	instanceVhostsCreateCmd.RegisterFlagCompletionFunc("default-queue-type", completeQueueTypes)
+
+	addConfirmFlags(instanceVhostsDeleteCmd)
+
+	instanceVhostsCmd.AddCommand(instanceVhostsListCmd)
+	instanceVhostsCmd.AddCommand(instanceVhostsCreateCmd)
+	instanceVhostsCmd.AddCommand(instanceVhostsDeleteCmd)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/wait"
+	"cloudamqp-cli/rabbitmq"
+	"github.com/spf13/cobra"
+)
+
+// newRabbitMQClient creates a management API client for an instance using
+// the credentials of the instance URL
+func newRabbitMQClient(c *client.Client, instanceID string) (*rabbitmq.Client, error) {
+	id, err := strconv.Atoi(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance ID: %v", err)
+	}
+
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	return managementClientFor(instance)
+}
+
+// managementClientFor creates a management API client for an instance that
+// has already been fetched
+func managementClientFor(instance *client.Instance) (*rabbitmq.Client, error) {
+	mgmt, err := rabbitmq.NewFromURL(instance.URL, Version)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		mgmt.SetDryRun(os.Stdout)
+	}
+	return mgmt, nil
+}
+
+// isDryRun reports whether err is the result of a request that was printed
+// instead of sent
+func isDryRun(err error) bool {
+	return errors.Is(err, client.ErrDryRun) || errors.Is(err, rabbitmq.ErrDryRun)
+}
+
+// ensurePlugins enables the plugins of names that are not enabled on the
+// instance yet and waits until they are
+func ensurePlugins(c *client.Client, instanceID int, timeout time.Duration, names ...string) error {
+	plugins, err := c.ListPlugins(strconv.Itoa(instanceID))
+	if err != nil {
+		return fmt.Errorf("failed to list plugins: %w", err)
+	}
+
+	enabled := make(map[string]bool)
+	for _, plugin := range plugins {
+		enabled[plugin.Name] = plugin.Enabled
+	}
+
+	for _, name := range names {
+		if enabled[name] {
+			continue
+		}
+
+		fmt.Printf("Enabling plugin '%s' on instance %d...\n", name, instanceID)
+		if err := c.EnablePlugin(strconv.Itoa(instanceID), name); err != nil {
+			if isDryRun(err) {
+				continue
+			}
+			return fmt.Errorf("failed to enable plugin '%s': %w", name, err)
+		}
+
+		cond, err := wait.Parse("plugin-enabled=" + name)
+		if err != nil {
+			return err
+		}
+		if err := waitForCondition(c, instanceID, cond, timeout); err != nil {
+			return fmt.Errorf("plugin '%s' was not enabled: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// instanceManagementClient returns a management API client for the instance
+// given by the --id flag of cmd
+func instanceManagementClient(cmd *cobra.Command) (*rabbitmq.Client, error) {
+	idFlag, _ := cmd.Flags().GetString("id")
+	if idFlag == "" {
+		return nil, fmt.Errorf("instance ID is required. Use --id flag")
+	}
+
+	apiKey, err := getAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return newRabbitMQClient(newClient(apiKey), idFlag)
+}
+
+// managementVhost returns the --vhost flag, or the vhost of the instance URL
+func managementVhost(cmd *cobra.Command, mgmt *rabbitmq.Client) string {
+	if vhost, _ := cmd.Flags().GetString("vhost"); vhost != "" {
+		return vhost
+	}
+	return mgmt.DefaultVhost()
+}
+
+// parseArguments parses a JSON object given to an --arguments flag
+func parseArguments(value string) (map[string]any, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(value), &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments, must be a JSON object: %v", err)
+	}
+	return args, nil
+}
+
+// instanceAMQPClient connects over AMQP to the instance given by the --id
+// flag of cmd, using the instance URL. Connecting must finish before ctx ends.
+func instanceAMQPClient(ctx context.Context, cmd *cobra.Command) (*rabbitmq.AMQPClient, error) {
+	idFlag, _ := cmd.Flags().GetString("id")
+	id, err := strconv.Atoi(idFlag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance ID: %v", err)
+	}
+
+	apiKey, err := getAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	instance, err := newClient(apiKey).GetInstance(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	conn, err := rabbitmq.DialAMQPContext(ctx, instance.URL, Version)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		conn.SetDryRun(os.Stdout)
+	}
+	return conn, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/wait"
+	"cloudamqp-cli/rabbitmq"
+)
+
+// nodeRestarts tells when nodes have restarted since it was created. Right
+// after a restart request the nodes still report running, so waiting for
+// nodes-running alone passes before the restart has begun. A node counts as
+// restarted once it has been seen not running, or once the management API
+// reports a lower uptime than before the restart, which catches restarts
+// quicker than the polling interval.
+type nodeRestarts struct {
+	c          *client.Client
+	mgmt       *rabbitmq.Client
+	instanceID string
+	uptimes    map[string]int64
+	restarted  map[string]bool
+}
+
+// newNodeRestarts records the uptime of every node. Without management API
+// access only nodes seen down count as restarted.
+func newNodeRestarts(c *client.Client, instanceID string) *nodeRestarts {
+	r := &nodeRestarts{
+		c:          c,
+		instanceID: instanceID,
+		uptimes:    make(map[string]int64),
+		restarted:  make(map[string]bool),
+	}
+
+	mgmt, err := newRabbitMQClient(c, instanceID)
+	if err != nil {
+		return r
+	}
+	stats, err := mgmt.ListNodes()
+	if err != nil {
+		return r
+	}
+
+	r.mgmt = mgmt
+	for _, s := range stats {
+		r.uptimes[s.Hostname()] = s.Uptime
+	}
+	return r
+}
+
+// Check returns a check that is met when the named nodes, or all nodes when
+// nodes is empty, have restarted. Restarts are remembered between polls, so a
+// node that went down and came back counts.
+func (r *nodeRestarts) Check(nodes []string) wait.CheckFunc {
+	selected := make(map[string]bool, len(nodes))
+	for _, name := range nodes {
+		selected[name] = true
+	}
+
+	return func() (bool, string, error) {
+		current, err := r.c.ListNodes(r.instanceID)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to check node status: %w", err)
+		}
+
+		// The management API is unreachable while some restarts happen, which
+		// is no reason to stop waiting
+		uptimes := make(map[string]int64)
+		if r.mgmt != nil {
+			if stats, err := r.mgmt.ListNodes(); err == nil {
+				for _, s := range stats {
+					if s.Running {
+						uptimes[s.Hostname()] = s.Uptime
+					}
+				}
+			}
+		}
+
+		total, done := 0, 0
+		for _, node := range current {
+			if len(selected) > 0 && !selected[node.Name] {
+				continue
+			}
+			total++
+
+			if !node.Running || !node.Configured {
+				r.restarted[node.Name] = true
+			}
+			before, known := r.uptimes[node.Name]
+			if now, ok := uptimes[node.Name]; known && ok && now < before {
+				r.restarted[node.Name] = true
+			}
+			if r.restarted[node.Name] {
+				done++
+			}
+		}
+
+		if total == 0 {
+			return false, "no matching nodes", nil
+		}
+		return done == total, fmt.Sprintf("%d/%d nodes restarted", done, total), nil
+	}
+}
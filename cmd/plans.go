@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
 	"github.com/spf13/cobra"
 )
@@ -24,12 +23,11 @@ var plansCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
-		plans, err := c.ListPlans(backendFilter)
+		plans, err := c.Cached().ListPlans(backendFilter)
 		if err != nil {
-			fmt.Printf("Error listing plans: %v\n", err)
-			return err
+			return commandError("listing plans", err)
 		}
 
 		if len(plans) == 0 {
@@ -44,13 +42,9 @@ var plansCmd = &cobra.Command{
 			if plan.Shared {
 				shared = "Yes"
 			}
-			price := fmt.Sprintf("$%.2f", plan.Price)
-			if plan.Price == 0 {
-				price = "Free"
-			}
 			t.AddRow(
 				plan.Name,
-				price,
+				formatPrice(plan.Price),
 				plan.Backend,
 				shared,
 			)
@@ -60,6 +54,14 @@ var plansCmd = &cobra.Command{
 	},
 }
 
+// formatPrice formats a monthly plan price in USD
+func formatPrice(price float64) string {
+	if price == 0 {
+		return "Free"
+	}
+	return fmt.Sprintf("$%.2f", price)
+}
+
 func init() {
 	plansCmd.Flags().StringVar(&backendFilter, "backend", "", "Filter by specific backend software")
 }
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"cloudamqp-cli/client"
+	"github.com/spf13/cobra"
+)
+
+const defaultProtectedTag = "protected"
+
+// protectionPolicy is the local deletion protection policy. Resources carrying
+// the protected tag or listed by ID refuse destructive operations unless
+// --override-protection is given and the resource name is typed to confirm.
+type protectionPolicy struct {
+	ProtectedTag string `json:"protected_tag"`
+	Instances    []int  `json:"instances"`
+	VPCs         []int  `json:"vpcs"`
+}
+
+// getPolicyPath returns the policy file path, CLOUDAMQP_POLICY_FILE or
+// ~/.cloudamqp-policy.json
+func getPolicyPath() (string, error) {
+	if path := os.Getenv("CLOUDAMQP_POLICY_FILE"); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".cloudamqp-policy.json"), nil
+}
+
+// loadProtectionPolicy reads the policy file. A missing file yields the
+// default policy, which only protects resources tagged "protected".
+func loadProtectionPolicy() (*protectionPolicy, error) {
+	policy := &protectionPolicy{ProtectedTag: defaultProtectedTag}
+
+	path, err := getPolicyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return policy, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protection policy: %w", err)
+	}
+
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("invalid protection policy %s: %w", path, err)
+	}
+	if policy.ProtectedTag == "" {
+		policy.ProtectedTag = defaultProtectedTag
+	}
+	return policy, nil
+}
+
+// protectionReason returns why a resource is protected, or "" if it is not
+func (p *protectionPolicy) protectionReason(id int, tags []string, listed []int) string {
+	for _, tag := range tags {
+		if tag == p.ProtectedTag {
+			return fmt.Sprintf("tagged %q", p.ProtectedTag)
+		}
+	}
+	for _, protectedID := range listed {
+		if protectedID == id {
+			return "listed in the local protection policy"
+		}
+	}
+	return ""
+}
+
+// addProtectionFlag adds the --override-protection flag checked by
+// checkInstanceProtection and checkVPCProtection
+func addProtectionFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("override-protection", false, "Allow the operation on a protected resource (requires typing the resource name)")
+}
+
+// checkInstanceProtection refuses operation on a protected instance unless
+// --override-protection is set and the user types the instance name.
+func checkInstanceProtection(cmd *cobra.Command, c *client.Client, instanceID int, operation string) error {
+	policy, err := loadProtectionPolicy()
+	if err != nil {
+		return err
+	}
+
+	instance, err := c.GetInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to check instance protection: %w", err)
+	}
+
+	reason := policy.protectionReason(instance.ID, instance.Tags, policy.Instances)
+	return enforceProtection(cmd, "instance", instanceID, instance.Name, reason, operation)
+}
+
+// checkVPCProtection refuses operation on a protected VPC unless
+// --override-protection is set and the user types the VPC name.
+func checkVPCProtection(cmd *cobra.Command, c *client.Client, vpcID int, operation string) error {
+	policy, err := loadProtectionPolicy()
+	if err != nil {
+		return err
+	}
+
+	vpc, err := c.GetVPC(vpcID)
+	if err != nil {
+		return fmt.Errorf("failed to check VPC protection: %w", err)
+	}
+
+	reason := policy.protectionReason(vpc.ID, vpc.Tags, policy.VPCs)
+	return enforceProtection(cmd, "VPC", vpcID, vpc.Name, reason, operation)
+}
+
+func enforceProtection(cmd *cobra.Command, kind string, id int, name, reason, operation string) error {
+	if reason == "" {
+		return nil
+	}
+
+	override, _ := cmd.Flags().GetBool("override-protection")
+	if !override {
+		return fmt.Errorf("%s %d (%s) is protected (%s). Use --override-protection to %s it anyway", kind, id, name, reason, operation)
+	}
+
+	fmt.Printf("%s %d (%s) is protected (%s).\n", kind, id, name, reason)
+	if dryRun {
+		return nil
+	}
+	return confirmTypedName(kind, name)
+}
+
+// checkInstanceProtectionByID is checkInstanceProtection for commands taking
+// the instance ID as a string flag
+func checkInstanceProtectionByID(cmd *cobra.Command, c *client.Client, instanceID, operation string) error {
+	id, err := strconv.Atoi(instanceID)
+	if err != nil {
+		return fmt.Errorf("invalid instance ID: %v", err)
+	}
+	return checkInstanceProtection(cmd, c, id, operation)
+}
+
+// isPlanDowngrade reports whether changing from one plan to another lowers the
+// price. Unknown plans are treated as a downgrade.
+func isPlanDowngrade(c *client.Client, from, to string) (bool, error) {
+	plans, err := c.Cached().ListPlans("")
+	if err != nil {
+		return false, fmt.Errorf("failed to list plans: %w", err)
+	}
+
+	prices := make(map[string]float64, len(plans))
+	for _, plan := range plans {
+		prices[plan.Name] = plan.Price
+	}
+
+	fromPrice, fromOK := prices[from]
+	toPrice, toOK := prices[to]
+	if !fromOK || !toOK {
+		return true, nil
+	}
+	return toPrice < fromPrice, nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloudamqp-cli/client"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProtectionPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	policyPath := filepath.Join(tempDir, "policy.json")
+	t.Setenv("CLOUDAMQP_POLICY_FILE", policyPath)
+
+	// Missing file yields the default policy
+	policy, err := loadProtectionPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, "protected", policy.ProtectedTag)
+
+	err = os.WriteFile(policyPath, []byte(`{"protected_tag": "production", "instances": [1234], "vpcs": [5678]}`), 0600)
+	assert.NoError(t, err)
+
+	policy, err = loadProtectionPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, "production", policy.ProtectedTag)
+	assert.Equal(t, `tagged "production"`, policy.protectionReason(1, []string{"web", "production"}, policy.Instances))
+	assert.Equal(t, "listed in the local protection policy", policy.protectionReason(1234, nil, policy.Instances))
+	assert.Equal(t, "", policy.protectionReason(1, []string{"protected"}, policy.Instances))
+	assert.Equal(t, "", policy.protectionReason(1234, nil, policy.VPCs))
+
+	err = os.WriteFile(policyPath, []byte(`not json`), 0600)
+	assert.NoError(t, err)
+	_, err = loadProtectionPolicy()
+	assert.Error(t, err)
+}
+
+func TestCheckInstanceProtection(t *testing.T) {
+	t.Setenv("CLOUDAMQP_POLICY_FILE", filepath.Join(t.TempDir(), "missing.json"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/instances/1":
+			json.NewEncoder(w).Encode(client.Instance{ID: 1, Name: "prod", Tags: []string{"protected"}})
+		case "/instances/2":
+			json.NewEncoder(w).Encode(client.Instance{ID: 2, Name: "dev"})
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL("test-api-key", server.URL, "test")
+	cmd := &cobra.Command{}
+	addProtectionFlag(cmd)
+
+	err := checkInstanceProtection(cmd, c, 1, "delete")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "instance 1 (prod) is protected")
+	assert.Contains(t, err.Error(), "--override-protection")
+
+	assert.NoError(t, checkInstanceProtection(cmd, c, 2, "delete"))
+}
+
+func TestIsPlanDowngrade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]client.Plan{
+			{Name: "bunny-1", Price: 99},
+			{Name: "rabbit-1", Price: 299},
+		})
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL("test-api-key", server.URL, "test")
+
+	downgrade, err := isPlanDowngrade(c, "rabbit-1", "bunny-1")
+	assert.NoError(t, err)
+	assert.True(t, downgrade)
+
+	downgrade, err = isPlanDowngrade(c, "bunny-1", "rabbit-1")
+	assert.NoError(t, err)
+	assert.False(t, downgrade)
+
+	downgrade, err = isPlanDowngrade(c, "bunny-1", "unknown")
+	assert.NoError(t, err)
+	assert.True(t, downgrade)
+}
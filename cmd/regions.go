@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
 	"github.com/spf13/cobra"
 )
@@ -24,12 +23,11 @@ var regionsCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
-		regions, err := c.ListRegions(providerFilter)
+		regions, err := c.Cached().ListRegions(providerFilter)
 		if err != nil {
-			fmt.Printf("Error listing regions: %v\n", err)
-			return err
+			return commandError("listing regions", err)
 		}
 
 		if len(regions) == 0 {
@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
+	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
-var apiKey string
+var (
+	apiKey  string
+	dryRun  bool
+	noCache bool
+)
 
 func getVersionString() string {
 	if Version == "dev" {
@@ -30,23 +36,62 @@ The CLI will look for your API key in the following order:
 2. ~/.cloudamqprc file (JSON format)
 3. If neither exists, you will be prompted to enter it
 
-Instance API keys are automatically saved when using 'instance get' command.`,
+Instance API keys are automatically saved when using 'instance get' command.
+
+Use --dry-run with any command to print the requests that would change
+resources instead of sending them.`,
 	Version: getVersionString(),
+	// Errors are printed by Execute, which leaves out dry-run results
+	SilenceErrors: true,
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err == nil || isDryRun(err) {
+		return nil
+	}
+	rootCmd.PrintErrln(rootCmd.ErrPrefix(), err.Error())
+	return err
+}
+
+// commandError prints a failed API call as "Error <doing>: <err>" and returns
+// err. A request printed instead of sent by --dry-run is not a failure, so
+// nothing is printed and nil is returned.
+func commandError(doing string, err error) error {
+	if isDryRun(err) {
+		return nil
+	}
+	fmt.Printf("Error %s: %v\n", doing, err)
+	return err
+}
+
+// newClient creates an API client configured from the global flags
+func newClient(apiKey string) *client.Client {
+	c := client.New(apiKey, Version)
+	if dryRun {
+		c.SetDryRun(os.Stdout)
+	}
+	c.SetCache(fileCache{apiKey: apiKey})
+	if noCache {
+		c.DisableCacheReads()
+	}
+	return c
 }
 
 func init() {
 	// Set custom version template to match gh style
 	rootCmd.SetVersionTemplate("cloudamqp version {{.Version}}\n")
 
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print mutating API requests instead of sending them")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always fetch lists from the API instead of the cache")
+
 	rootCmd.AddCommand(instanceCmd)
 	rootCmd.AddCommand(vpcCmd)
 	rootCmd.AddCommand(regionsCmd)
 	rootCmd.AddCommand(plansCmd)
+	rootCmd.AddCommand(costCmd)
 	rootCmd.AddCommand(teamCmd)
 	rootCmd.AddCommand(auditCmd)
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(waitCmd)
 }
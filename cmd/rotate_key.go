@@ -4,15 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
 var rotateKeyCmd = &cobra.Command{
-	Use:     "rotate-key",
-	Short:   "Rotate API key",
-	Long:    `Removes the current API key and creates a new one with matching permissions.`,
-	Example: `  cloudamqp rotate-key`,
+	Use:   "rotate-key",
+	Short: "Rotate API key",
+	Long:  `Removes the current API key and creates a new one with matching permissions.`,
+	Example: `  cloudamqp rotate-key
+  cloudamqp rotate-key --force`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
 		apiKey, err = getAPIKey()
@@ -20,13 +20,21 @@ var rotateKeyCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
-		resp, err := c.RotateAPIKey()
+		confirmed, err := confirmAction(cmd, "revoke the current API key", "command", "rotate-key")
 		if err != nil {
-			fmt.Printf("Error rotating API key: %v\n", err)
 			return err
 		}
+		if !confirmed {
+			fmt.Println("Key rotation cancelled.")
+			return nil
+		}
+
+		resp, err := c.RotateAPIKey()
+		if err != nil {
+			return commandError("rotating API key", err)
+		}
 
 		output, err := json.MarshalIndent(resp, "", "  ")
 		if err != nil {
@@ -45,3 +53,7 @@ var rotateKeyCmd = &cobra.Command{
 		return nil
 	},
 }
+
+func init() {
+	addConfirmFlags(rotateKeyCmd)
+}
@@ -30,7 +30,7 @@ Default role: member`,
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		req := &client.TeamInviteRequest{
 			Email: inviteEmail,
@@ -40,8 +40,7 @@ Default role: member`,
 
 		resp, err := c.InviteTeamMember(req)
 		if err != nil {
-			fmt.Printf("Error inviting team member: %v\n", err)
-			return err
+			return commandError("inviting team member", err)
 		}
 
 		output, err := json.MarshalIndent(resp, "", "  ")
@@ -5,7 +5,6 @@ import (
 	"os"
 	"strings"
 
-	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
 	"github.com/spf13/cobra"
 )
@@ -22,12 +21,11 @@ var teamListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		members, err := c.ListTeamMembers()
 		if err != nil {
-			fmt.Printf("Error listing team members: %v\n", err)
-			return err
+			return commandError("listing team members", err)
 		}
 
 		if len(members) == 0 {
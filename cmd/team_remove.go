@@ -4,17 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
 var removeEmail string
 
 var teamRemoveCmd = &cobra.Command{
-	Use:     "remove",
-	Short:   "Remove a user from the team",
-	Long:    `Removes a user from the team.`,
-	Example: `  cloudamqp team remove --email=user@example.com`,
+	Use:   "remove",
+	Short: "Remove a user from the team",
+	Long:  `Removes a user from the team.`,
+	Example: `  cloudamqp team remove --email=user@example.com
+  cloudamqp team remove --email=user@example.com --yes`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var err error
 		apiKey, err = getAPIKey()
@@ -22,13 +22,21 @@ var teamRemoveCmd = &cobra.Command{
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
-		resp, err := c.RemoveTeamMember(removeEmail)
+		confirmed, err := confirmAction(cmd, "remove "+removeEmail+" from the team", "email", removeEmail)
 		if err != nil {
-			fmt.Printf("Error removing team member: %v\n", err)
 			return err
 		}
+		if !confirmed {
+			fmt.Println("Remove operation cancelled.")
+			return nil
+		}
+
+		resp, err := c.RemoveTeamMember(removeEmail)
+		if err != nil {
+			return commandError("removing team member", err)
+		}
 
 		output, err := json.MarshalIndent(resp, "", "  ")
 		if err != nil {
@@ -42,5 +50,6 @@ var teamRemoveCmd = &cobra.Command{
 
 func init() {
 	teamRemoveCmd.Flags().StringVar(&removeEmail, "email", "", "Email address of the user to remove (required)")
+	addConfirmFlags(teamRemoveCmd)
 	teamRemoveCmd.MarkFlagRequired("email")
 }
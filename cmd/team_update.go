@@ -29,7 +29,7 @@ Available roles: admin, devops, member, monitor, billing manager`,
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		req := &client.TeamUpdateRequest{
 			Role: updateRole,
@@ -42,8 +42,7 @@ Available roles: admin, devops, member, monitor, billing manager`,
 
 		resp, err := c.UpdateTeamMember(updateUserID, req)
 		if err != nil {
-			fmt.Printf("Error updating team member: %v\n", err)
-			return err
+			return commandError("updating team member", err)
 		}
 
 		output, err := json.MarshalIndent(resp, "", "  ")
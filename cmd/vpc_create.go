@@ -36,7 +36,7 @@ Optional flags:
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		req := &client.VPCCreateRequest{
 			Name:   vpcName,
@@ -47,8 +47,7 @@ Optional flags:
 
 		resp, err := c.CreateVPC(req)
 		if err != nil {
-			fmt.Printf("Error creating VPC: %v\n", err)
-			return err
+			return commandError("creating VPC", err)
 		}
 
 		output, err := json.MarshalIndent(resp, "", "  ")
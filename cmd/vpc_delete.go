@@ -1,20 +1,13 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strconv"
-	"strings"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
-var (
-	deleteVPCID    string
-	forceDeleteVPC bool
-)
+var deleteVPCID string
 
 var vpcDeleteCmd = &cobra.Command{
 	Use:   "delete --id <id>",
@@ -41,27 +34,24 @@ WARNING: This action cannot be undone. All instances in the VPC must be deleted
 			return fmt.Errorf("invalid VPC ID: %v", err)
 		}
 
-		if !forceDeleteVPC {
-			fmt.Printf("Are you sure you want to delete VPC %d? This action cannot be undone. (y/N): ", vpcID)
-			reader := bufio.NewReader(os.Stdin)
-			response, err := reader.ReadString('\n')
-			if err != nil {
-				return fmt.Errorf("failed to read confirmation: %v", err)
-			}
+		c := newClient(apiKey)
 
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Delete operation cancelled.")
-				return nil
-			}
+		if err := checkVPCProtection(cmd, c, vpcID, "delete"); err != nil {
+			return err
 		}
 
-		c := client.New(apiKey, Version)
+		confirmed, err := confirmVPCAction(cmd, c, vpcID, "permanently delete")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Delete operation cancelled.")
+			return nil
+		}
 
 		err = c.DeleteVPC(vpcID)
 		if err != nil {
-			fmt.Printf("Error deleting VPC: %v\n", err)
-			return err
+			return commandError("deleting VPC", err)
 		}
 
 		fmt.Printf("VPC %d deleted successfully.\n", vpcID)
@@ -71,7 +61,8 @@ WARNING: This action cannot be undone. All instances in the VPC must be deleted
 
 func init() {
 	vpcDeleteCmd.Flags().StringVar(&deleteVPCID, "id", "", "VPC ID (required)")
-	vpcDeleteCmd.Flags().BoolVar(&forceDeleteVPC, "force", false, "Skip confirmation prompt")
+	addConfirmFlags(vpcDeleteCmd)
+	addProtectionFlag(vpcDeleteCmd)
 	vpcDeleteCmd.MarkFlagRequired("id")
 	vpcDeleteCmd.RegisterFlagCompletionFunc("id", completeVPCArgs)
 }
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"cloudamqp-cli/client"
 	"github.com/spf13/cobra"
 )
 
@@ -31,12 +30,11 @@ var vpcGetCmd = &cobra.Command{
 			return fmt.Errorf("invalid VPC ID: %v", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		vpc, err := c.GetVPC(vpcID)
 		if err != nil {
-			fmt.Printf("Error getting VPC: %v\n", err)
-			return err
+			return commandError("getting VPC", err)
 		}
 
 		output, err := json.MarshalIndent(vpc, "", "  ")
@@ -7,46 +7,75 @@ import (
 
 	"cloudamqp-cli/client"
 	"cloudamqp-cli/internal/table"
+	"cloudamqp-cli/internal/watch"
 	"github.com/spf13/cobra"
 )
 
 var vpcListCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List all CloudAMQP VPCs",
-	Long:    `Retrieves and displays all CloudAMQP VPCs in your account.`,
-	Example: `  cloudamqp vpc list`,
+	Use:   "list",
+	Short: "List all CloudAMQP VPCs",
+	Long: `Retrieves and displays all CloudAMQP VPCs in your account.
+
+With --watch the list is polled every --watch-interval and VPCs that are added,
+removed or changed are printed.`,
+	Example: `  cloudamqp vpc list
+  cloudamqp vpc list --watch --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var err error
+		opts, watching, err := watchOptions(cmd, "vpc")
+		if err != nil {
+			return err
+		}
+
 		apiKey, err = getAPIKey()
 		if err != nil {
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
-		vpcs, err := c.ListVPCs()
+		// A watch starts from the current state, not a cached one
+		lister := c
+		if !watching {
+			lister = c.Cached()
+		}
+		vpcs, err := lister.ListVPCs()
 		if err != nil {
-			fmt.Printf("Error listing VPCs: %v\n", err)
-			return err
+			return commandError("listing VPCs", err)
 		}
 
-		if len(vpcs) == 0 {
-			fmt.Println("No VPCs found.")
-			return nil
+		if !opts.JSON {
+			printVPCList(vpcs)
 		}
-
-		// Create table and populate data
-		t := table.New(os.Stdout, "ID", "NAME", "SUBNET", "REGION")
-		for _, vpc := range vpcs {
-			t.AddRow(
-				strconv.Itoa(vpc.ID),
-				vpc.Name,
-				vpc.Subnet,
-				vpc.Region,
-			)
+		if !watching {
+			return nil
 		}
-		t.Print()
 
-		return nil
+		return runWatch(opts, vpcResources(vpcs), func() ([]watch.Resource, error) {
+			vpcs, err := c.ListVPCs()
+			return vpcResources(vpcs), err
+		})
 	},
 }
+
+func printVPCList(vpcs []client.VPC) {
+	if len(vpcs) == 0 {
+		fmt.Println("No VPCs found.")
+		return
+	}
+
+	// Create table and populate data
+	t := table.New(os.Stdout, "ID", "NAME", "SUBNET", "REGION")
+	for _, vpc := range vpcs {
+		t.AddRow(
+			strconv.Itoa(vpc.ID),
+			vpc.Name,
+			vpc.Subnet,
+			vpc.Region,
+		)
+	}
+	t.Print()
+}
+
+func init() {
+	addWatchFlags(vpcListCmd)
+}
@@ -41,7 +41,7 @@ You can update the following fields:
 			return fmt.Errorf("invalid VPC ID: %v", err)
 		}
 
-		c := client.New(apiKey, Version)
+		c := newClient(apiKey)
 
 		req := &client.VPCUpdateRequest{
 			Name: updateVPCName,
@@ -54,8 +54,7 @@ You can update the following fields:
 
 		err = c.UpdateVPC(vpcID, req)
 		if err != nil {
-			fmt.Printf("Error updating VPC: %v\n", err)
-			return err
+			return commandError("updating VPC", err)
 		}
 
 		fmt.Printf("VPC %d updated successfully.\n", vpcID)
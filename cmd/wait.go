@@ -2,52 +2,270 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/wait"
+	"github.com/spf13/cobra"
 )
 
-func waitForInstanceReady(c *client.Client, instanceID int, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+var (
+	waitID          string
+	waitConditions  []string
+	waitTimeout     string
+	waitInterval    string
+	waitMaxInterval string
+	waitBackoff     float64
+	waitJSON        bool
+	waitNodes       string
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait --id <id> --for <condition>",
+	Short: "Wait for an instance or VPC to reach a condition",
+	Long: `Poll the API until an instance or VPC reaches the given condition.
+
+The --id flag is a VPC ID for the vpc-ready condition and an instance ID for
+all other conditions. When --for is given multiple times all conditions must be
+met at the same time. Node conditions can be restricted to some nodes with
+--nodes.
+
+Conditions:
+  instance-ready           Instance reports ready
+  instance-deleted         Instance no longer exists
+  nodes-running            All nodes are running and configured
+  nodes-stopped            All nodes are stopped
+  plugin-enabled=<name>    Plugin is enabled
+  plugin-disabled=<name>   Plugin is disabled
+  plan=<plan>              Instance is ready on the given plan
+  hipe=<true|false>        HiPE is enabled or disabled on all nodes
+  version=<version>        All nodes run the given RabbitMQ version
+  vpc-ready                VPC exists and has been provisioned
+  disk-size>=<GB>          All nodes have at least the given total disk size
+
+The delay between polls starts at --interval and is multiplied by --backoff
+after every poll, up to --max-interval. Use --json to print progress events as
+JSON lines.`,
+	Example: `  cloudamqp wait --id 1234 --for instance-ready
+  cloudamqp wait --id 1234 --for nodes-running --for version=3.13.7 --timeout=30m
+  cloudamqp wait --id 1234 --for plugin-enabled=rabbitmq_shovel --interval=5s --backoff=1
+  cloudamqp wait --id 5678 --for vpc-ready --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(waitID)
+		if err != nil {
+			return fmt.Errorf("invalid ID: %v", err)
+		}
+		if len(waitConditions) == 0 {
+			return fmt.Errorf("at least one --for condition is required")
+		}
+
+		var nodes []string
+		if waitNodes != "" {
+			nodes = strings.Split(waitNodes, ",")
+		}
+
+		var conditions []*wait.Condition
+		for _, spec := range waitConditions {
+			cond, err := wait.Parse(spec)
+			if err != nil {
+				return err
+			}
+			conditions = append(conditions, cond.OnNodes(nodes))
+		}
+
+		opts, err := parseWaitOptions(waitTimeout, waitInterval, waitMaxInterval, waitBackoff)
+		if err != nil {
+			return err
+		}
+
+		apiKey, err = getAPIKey()
+		if err != nil {
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+
+		c := newClient(apiKey)
 
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+		if waitJSON {
+			opts.Progress = printWaitEventJSON
+		} else {
+			opts.Progress = printWaitProgress(fmt.Sprintf("%d", id))
+		}
+
+		name := strings.Join(waitConditions, ",")
+		return wait.Until(context.Background(), name, allConditions(c, id, conditions), opts)
+	},
+}
+
+// allConditions returns a check that is met when every condition is met
+func allConditions(c *client.Client, id int, conditions []*wait.Condition) wait.CheckFunc {
+	return func() (bool, string, error) {
+		var pending []string
+		for _, cond := range conditions {
+			done, status, err := cond.Check(c, id)()
+			if err != nil {
+				return false, "", err
+			}
+			if !done {
+				pending = append(pending, status)
+			}
+		}
+		return len(pending) == 0, strings.Join(pending, "; "), nil
+	}
+}
 
-	startTime := time.Now()
+func parseWaitOptions(timeout, interval, maxInterval string, backoff float64) (wait.Options, error) {
+	var opts wait.Options
+	var err error
 
-	// Check immediately first
-	instance, err := c.GetInstance(instanceID)
+	if opts.Timeout, err = time.ParseDuration(timeout); err != nil {
+		return opts, fmt.Errorf("invalid timeout value: %v", err)
+	}
+	if opts.Interval, err = time.ParseDuration(interval); err != nil {
+		return opts, fmt.Errorf("invalid interval value: %v", err)
+	}
+	if opts.MaxInterval, err = time.ParseDuration(maxInterval); err != nil {
+		return opts, fmt.Errorf("invalid max-interval value: %v", err)
+	}
+	if backoff < 1 {
+		return opts, fmt.Errorf("backoff must be at least 1")
+	}
+	opts.Backoff = backoff
+
+	return opts, nil
+}
+
+// printWaitProgress returns a progress callback printing human readable lines
+// to stderr. Nothing is printed when the condition is met on the first poll.
+func printWaitProgress(resource string) func(wait.Event) {
+	return func(e wait.Event) {
+		elapsed := (time.Duration(e.Elapsed) * time.Second).String()
+		switch e.State {
+		case wait.StateWaiting:
+			if e.Attempt == 1 {
+				fmt.Fprintf(os.Stderr, "Waiting for %s: %s (%s)...\n", resource, e.Condition, e.Message)
+			} else {
+				fmt.Fprintf(os.Stderr, "Still waiting... (%s, elapsed: %s)\n", e.Message, elapsed)
+			}
+		case wait.StateDone:
+			if e.Attempt > 1 {
+				fmt.Fprintf(os.Stderr, "Done: %s (took %s)\n", e.Message, elapsed)
+			}
+		}
+	}
+}
+
+func printWaitEventJSON(e wait.Event) {
+	json.NewEncoder(os.Stdout).Encode(e)
+}
+
+func waitForInstanceReady(c *client.Client, instanceID int, timeout time.Duration) error {
+	cond, err := wait.Parse("instance-ready")
 	if err != nil {
-		return fmt.Errorf("failed to check instance status: %w", err)
+		return err
 	}
-	if instance.Ready {
+	return waitForCondition(c, instanceID, cond, timeout)
+}
+
+// waitForCondition waits for cond with the default options used by commands
+// offering --wait
+func waitForCondition(c *client.Client, instanceID int, cond *wait.Condition, timeout time.Duration) error {
+	opts := wait.DefaultOptions(timeout)
+	opts.Progress = printWaitProgress(fmt.Sprintf("instance %d", instanceID))
+	return wait.Until(context.Background(), cond.Spec, cond.Check(c, instanceID), opts)
+}
+
+// addWaitFlags adds the --wait and --wait-timeout flags used by waitIfRequested
+func addWaitFlags(cmd *cobra.Command, defaultTimeout string) {
+	cmd.Flags().Bool("wait", false, "Wait for the operation to complete")
+	cmd.Flags().String("wait-timeout", defaultTimeout, "Timeout for waiting (e.g., 15m, 30m)")
+}
+
+// trackRestarts returns a nodeRestarts for a restarting action run with
+// --wait, to be created before the restart is requested, and nil otherwise
+func trackRestarts(cmd *cobra.Command, c *client.Client, instanceID string) *nodeRestarts {
+	if shouldWait, _ := cmd.Flags().GetBool("wait"); !shouldWait {
 		return nil
 	}
+	return newNodeRestarts(c, instanceID)
+}
 
-	fmt.Fprintf(os.Stderr, "Waiting for instance %d to be ready...\n", instanceID)
+// waitIfRequested waits for the condition spec when the command was run with
+// --wait. Node conditions are restricted to nodes when any are given. With
+// restarts the nodes must first have restarted, so that a condition that held
+// before the restart does not pass early; both share the timeout.
+func waitIfRequested(cmd *cobra.Command, c *client.Client, instanceID, spec string, nodes []string, restarts *nodeRestarts) error {
+	shouldWait, _ := cmd.Flags().GetBool("wait")
+	if !shouldWait {
+		return nil
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			elapsed := time.Since(startTime)
-			return fmt.Errorf("timeout after %s waiting for instance to be ready", elapsed.Round(time.Second))
-		case <-ticker.C:
-			instance, err := c.GetInstance(instanceID)
-			if err != nil {
-				return fmt.Errorf("failed to check instance status: %w", err)
-			}
+	timeoutFlag, _ := cmd.Flags().GetString("wait-timeout")
+	timeout, err := time.ParseDuration(timeoutFlag)
+	if err != nil {
+		return fmt.Errorf("invalid wait-timeout value: %v", err)
+	}
 
-			if instance.Ready {
-				elapsed := time.Since(startTime)
-				fmt.Fprintf(os.Stderr, "Instance is ready! (took %s)\n", elapsed.Round(time.Second))
-				return nil
-			}
+	id, err := strconv.Atoi(instanceID)
+	if err != nil {
+		return fmt.Errorf("invalid instance ID: %v", err)
+	}
+
+	cond, err := wait.Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	if restarts != nil {
+		start := time.Now()
+		opts := wait.DefaultOptions(timeout)
+		opts.Backoff = 1
+		opts.Progress = printWaitProgress(fmt.Sprintf("instance %d", id))
+		if err := wait.Until(context.Background(), "restart", restarts.Check(nodes), opts); err != nil {
+			return fmt.Errorf("wait failed: %w", err)
+		}
+		if timeout > 0 {
+			timeout = max(timeout-time.Since(start), time.Second)
+		}
+	}
+
+	if err := waitForCondition(c, id, cond.OnNodes(nodes), timeout); err != nil {
+		return fmt.Errorf("wait failed: %w", err)
+	}
+	return nil
+}
 
-			elapsed := time.Since(startTime)
-			fmt.Fprintf(os.Stderr, "Still waiting... (elapsed: %s)\n", elapsed.Round(time.Second))
+// completeWaitID completes VPC IDs when waiting for vpc-ready and instance
+// IDs otherwise, matching how --id is read
+func completeWaitID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	for _, spec := range waitConditions {
+		if spec == "vpc-ready" {
+			return completeVPCs(cmd, args, toComplete)
 		}
 	}
+	return completeInstances(cmd, args, toComplete)
+}
+
+func completeWaitConditions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return wait.ConditionHelp, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	waitCmd.Flags().StringVar(&waitID, "id", "", "Instance ID, or VPC ID for vpc-ready (required)")
+	waitCmd.Flags().StringArrayVar(&waitConditions, "for", []string{}, "Condition to wait for (can be specified multiple times)")
+	waitCmd.Flags().StringVar(&waitTimeout, "timeout", "15m", "Maximum time to wait (e.g., 15m, 1h)")
+	waitCmd.Flags().StringVar(&waitInterval, "interval", "10s", "Initial delay between polls")
+	waitCmd.Flags().StringVar(&waitMaxInterval, "max-interval", "1m", "Maximum delay between polls")
+	waitCmd.Flags().Float64Var(&waitBackoff, "backoff", 1.5, "Multiplier applied to the delay after every poll (1 disables backoff)")
+	waitCmd.Flags().BoolVar(&waitJSON, "json", false, "Print progress events as JSON lines")
+	waitCmd.Flags().StringVar(&waitNodes, "nodes", "", "Comma-separated list of node names for node conditions (default: all nodes)")
+	waitCmd.MarkFlagRequired("id")
+	waitCmd.MarkFlagRequired("for")
+	waitCmd.RegisterFlagCompletionFunc("id", completeWaitID)
+	waitCmd.RegisterFlagCompletionFunc("for", completeWaitConditions)
 }
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudamqp-cli/client"
+	"cloudamqp-cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// addWatchFlags adds the --watch, --watch-interval and --json flags used by
+// watchOptions
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("watch", "w", false, "Keep polling and print changes")
+	cmd.Flags().String("watch-interval", "10s", "Delay between polls with --watch")
+	cmd.Flags().Bool("json", false, "Print changes as JSON events (requires --watch)")
+}
+
+// watchOptions returns the watch options for a command with watch flags and
+// whether --watch was given
+func watchOptions(cmd *cobra.Command, kind string) (watch.Options, bool, error) {
+	watching, _ := cmd.Flags().GetBool("watch")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	intervalFlag, _ := cmd.Flags().GetString("watch-interval")
+
+	opts := watch.Options{Kind: kind, JSON: jsonOutput, Out: os.Stdout}
+	if jsonOutput && !watching {
+		return opts, false, fmt.Errorf("--json requires --watch")
+	}
+
+	interval, err := time.ParseDuration(intervalFlag)
+	if err != nil {
+		return opts, false, fmt.Errorf("invalid watch-interval value: %v", err)
+	}
+	if interval < time.Second {
+		return opts, false, fmt.Errorf("watch-interval must be at least 1s")
+	}
+	opts.Interval = interval
+
+	return opts, watching, nil
+}
+
+// runWatch prints the changes to the resources returned by poll until
+// interrupted. With JSON output the initial resources are printed as added
+// events first, otherwise the caller has already shown them.
+func runWatch(opts watch.Options, initial []watch.Resource, poll watch.PollFunc) error {
+	if opts.JSON {
+		for _, e := range watch.Initial(opts.Kind, initial, time.Now()) {
+			watch.Print(opts, e)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return watch.Run(ctx, initial, poll, opts)
+}
+
+func instanceResource(instance client.Instance) watch.Resource {
+	return watch.Resource{
+		Key: strconv.Itoa(instance.ID),
+		Fields: []watch.Field{
+			{Name: "name", Value: instance.Name},
+			{Name: "plan", Value: instance.Plan},
+			{Name: "region", Value: instance.Region},
+			{Name: "tags", Value: strings.Join(instance.Tags, ",")},
+			{Name: "hostname", Value: instance.HostnameExternal},
+			{Name: "ready", Value: strconv.FormatBool(instance.Ready)},
+		},
+	}
+}
+
+func instanceResources(instances []client.Instance) []watch.Resource {
+	resources := make([]watch.Resource, 0, len(instances))
+	for _, instance := range instances {
+		resources = append(resources, instanceResource(instance))
+	}
+	return resources
+}
+
+func nodeResources(nodes []client.Node) []watch.Resource {
+	resources := make([]watch.Resource, 0, len(nodes))
+	for _, node := range nodes {
+		resources = append(resources, watch.Resource{
+			Key: node.Name,
+			Fields: []watch.Field{
+				{Name: "configured", Value: strconv.FormatBool(node.Configured)},
+				{Name: "running", Value: strconv.FormatBool(node.Running)},
+				{Name: "disk_size", Value: fmt.Sprintf("%d GB", node.DiskSize+node.AdditionalDiskSize)},
+				{Name: "rabbitmq_version", Value: node.RabbitMQVersion},
+			},
+		})
+	}
+	return resources
+}
+
+func vpcResources(vpcs []client.VPC) []watch.Resource {
+	resources := make([]watch.Resource, 0, len(vpcs))
+	for _, vpc := range vpcs {
+		resources = append(resources, watch.Resource{
+			Key: strconv.Itoa(vpc.ID),
+			Fields: []watch.Field{
+				{Name: "name", Value: vpc.Name},
+				{Name: "subnet", Value: vpc.Subnet},
+				{Name: "region", Value: vpc.Region},
+			},
+		})
+	}
+	return resources
+}
@@ -0,0 +1,270 @@
+// Package audit parses the CloudAMQP audit log CSV into typed events and
+// filters, groups and exports them.
+package audit
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event is one entry of the audit log. Columns that are not recognised are
+// kept in Extra, keyed by their header.
+type Event struct {
+	Time         time.Time         `json:"time"`
+	User         string            `json:"user"`
+	Action       string            `json:"action"`
+	ResourceType string            `json:"resource_type"`
+	ResourceID   string            `json:"resource_id"`
+	Details      string            `json:"details,omitempty"`
+	Extra        map[string]string `json:"extra,omitempty"`
+}
+
+// columnAliases maps normalised CSV headers to event fields
+var columnAliases = map[string]string{
+	"time":          "time",
+	"timestamp":     "time",
+	"created_at":    "time",
+	"date":          "time",
+	"user":          "user",
+	"user_email":    "user",
+	"email":         "user",
+	"actor":         "user",
+	"action":        "action",
+	"event":         "action",
+	"resource_type": "resource_type",
+	"resource":      "resource_type",
+	"type":          "resource_type",
+	"resource_id":   "resource_id",
+	"id":            "resource_id",
+	"instance_id":   "resource_id",
+	"details":       "details",
+	"description":   "details",
+	"message":       "details",
+}
+
+// timeLayouts are the time formats accepted in the time column
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// Parse reads an audit log CSV with a header row. Events are returned in the
+// order of the file.
+func Parse(r io.Reader) ([]Event, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	fields := make([]string, len(header))
+	hasTime := false
+	for i, name := range header {
+		fields[i] = columnAliases[normaliseHeader(name)]
+		hasTime = hasTime || fields[i] == "time"
+	}
+	if !hasTime {
+		return nil, fmt.Errorf("audit log has no time column (columns: %s)", strings.Join(header, ", "))
+	}
+
+	var events []Event
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		var event Event
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch fields[i] {
+			case "time":
+				if event.Time, err = parseTime(value); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+			case "user":
+				event.User = value
+			case "action":
+				event.Action = value
+			case "resource_type":
+				event.ResourceType = value
+			case "resource_id":
+				event.ResourceID = value
+			case "details":
+				event.Details = value
+			default:
+				if value == "" {
+					continue
+				}
+				if event.Extra == nil {
+					event.Extra = map[string]string{}
+				}
+				event.Extra[header[i]] = value
+			}
+		}
+		events = append(events, event)
+	}
+}
+
+func normaliseHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// Filter selects events. Empty fields match everything; text fields are
+// compared case-insensitively. Since is inclusive and Until exclusive.
+type Filter struct {
+	User         string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Event) bool {
+	switch {
+	case !matchText(f.User, e.User),
+		!matchText(f.Action, e.Action),
+		!matchText(f.ResourceType, e.ResourceType),
+		!matchText(f.ResourceID, e.ResourceID):
+		return false
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+func matchText(want, value string) bool {
+	return want == "" || strings.EqualFold(want, value)
+}
+
+// Apply returns the events matching the filter, sorted by time
+func (f Filter) Apply(events []Event) []Event {
+	var matched []Event
+	for _, e := range events {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Time.Before(matched[j].Time)
+	})
+	return matched
+}
+
+// Months returns the months from since to until as YYYY-MM timestamps, the
+// format the audit log API takes. until is exclusive.
+func Months(since, until time.Time) []string {
+	var months []string
+	month := time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for month.Before(until) {
+		months = append(months, month.Format("2006-01"))
+		month = month.AddDate(0, 1, 0)
+	}
+	return months
+}
+
+// GroupFields are the fields events can be grouped by
+var GroupFields = []string{"user", "action", "resource_type", "resource_id"}
+
+// Group counts the events sharing a value of the grouped field
+type Group struct {
+	Key   string    `json:"key"`
+	Count int       `json:"count"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// GroupBy counts events per value of field, largest groups first
+func GroupBy(events []Event, field string) ([]Group, error) {
+	value, ok := groupValue[field]
+	if !ok {
+		return nil, fmt.Errorf("invalid group-by field %q, must be one of: %s", field, strings.Join(GroupFields, ", "))
+	}
+
+	index := map[string]int{}
+	var groups []Group
+	for _, e := range events {
+		key := value(e)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Key: key, First: e.Time, Last: e.Time})
+		}
+		g := &groups[i]
+		g.Count++
+		if e.Time.Before(g.First) {
+			g.First = e.Time
+		}
+		if e.Time.After(g.Last) {
+			g.Last = e.Time
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+var groupValue = map[string]func(Event) string{
+	"user":          func(e Event) string { return e.User },
+	"action":        func(e Event) string { return e.Action },
+	"resource_type": func(e Event) string { return e.ResourceType },
+	"resource_id":   func(e Event) string { return e.ResourceID },
+}
+
+// csvHeader is the header written by WriteCSV
+var csvHeader = []string{"time", "user", "action", "resource_type", "resource_id", "details"}
+
+// WriteCSV writes events as CSV with a normalised header
+func WriteCSV(w io.Writer, events []Event) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range events {
+		record := []string{e.Time.Format(time.RFC3339), e.User, e.Action, e.ResourceType, e.ResourceID, e.Details}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
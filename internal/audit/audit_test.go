@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCSV = `Time,User,Action,Resource Type,Resource ID,Description,IP
+2024-01-03 10:00:00 +0000,alice@example.com,create,instance,1234,"Created instance ""orders""",10.0.0.1
+2024-01-05T08:30:00Z,bob@example.com,delete,vpc,55,,
+2024-01-04 12:00:00,Alice@example.com,update,instance,1234,Changed plan,
+`
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(sampleCSV))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if !first.Time.Equal(time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time %v", first.Time)
+	}
+	if first.User != "alice@example.com" || first.Action != "create" || first.ResourceType != "instance" || first.ResourceID != "1234" {
+		t.Errorf("unexpected event %+v", first)
+	}
+	if first.Details != `Created instance "orders"` {
+		t.Errorf("unexpected details %q", first.Details)
+	}
+	if first.Extra["IP"] != "10.0.0.1" {
+		t.Errorf("expected unknown column in extra, got %v", first.Extra)
+	}
+	if events[1].Extra != nil {
+		t.Errorf("expected no extra for empty columns, got %v", events[1].Extra)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if events, err := Parse(strings.NewReader("")); err != nil || events != nil {
+		t.Errorf("expected no events for empty log, got %v, %v", events, err)
+	}
+	if _, err := Parse(strings.NewReader("User,Action\nalice,create\n")); err == nil {
+		t.Error("expected error for log without time column")
+	}
+	_, err := Parse(strings.NewReader("Time,User\nyesterday,alice\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error with line number, got %v", err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	events, err := Parse(strings.NewReader(sampleCSV))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	matched := Filter{User: "ALICE@example.com"}.Apply(events)
+	if len(matched) != 2 || matched[0].Action != "create" || matched[1].Action != "update" {
+		t.Errorf("expected alice's events sorted by time, got %+v", matched)
+	}
+
+	matched = Filter{
+		Since: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 1, 5, 8, 30, 0, 0, time.UTC),
+	}.Apply(events)
+	if len(matched) != 1 || matched[0].Action != "update" {
+		t.Errorf("expected only the event inside the range, got %+v", matched)
+	}
+
+	matched = Filter{ResourceType: "vpc", ResourceID: "55"}.Apply(events)
+	if len(matched) != 1 || matched[0].User != "bob@example.com" {
+		t.Errorf("expected the vpc event, got %+v", matched)
+	}
+}
+
+func TestMonths(t *testing.T) {
+	since := time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	got := strings.Join(Months(since, until), ",")
+	if got != "2023-11,2023-12,2024-01" {
+		t.Errorf("unexpected months %s", got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	events, err := Parse(strings.NewReader(sampleCSV))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups, err := GroupBy(events, "resource_type")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Key != "instance" || groups[0].Count != 2 {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+	if !groups[0].First.Before(groups[0].Last) {
+		t.Errorf("expected first before last, got %+v", groups[0])
+	}
+
+	if _, err := GroupBy(events, "ip"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	events := []Event{{
+		Time:    time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC),
+		User:    "alice@example.com",
+		Action:  "create",
+		Details: "a, b",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "time,user,action,resource_type,resource_id,details\n2024-01-03T10:00:00Z,alice@example.com,create,,,\"a, b\"\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Cursor tracks which audit events have been seen. The audit log has no event
+// IDs, so the cursor keeps the time of the latest seen event and the keys of
+// all seen events with that time. Identical events in the same second are
+// only reported once.
+type Cursor struct {
+	Time time.Time `json:"time"`
+	Seen []string  `json:"seen,omitempty"`
+}
+
+// Key returns a stable key identifying e
+func Key(e Event) string {
+	extra := make([]string, 0, len(e.Extra))
+	for name, value := range e.Extra {
+		extra = append(extra, name+"="+value)
+	}
+	sort.Strings(extra)
+
+	data, _ := json.Marshal([]any{e.Time.Unix(), e.User, e.Action, e.ResourceType, e.ResourceID, e.Details, extra})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Unseen returns the events after the cursor, sorted by time
+func (c *Cursor) Unseen(events []Event) []Event {
+	var unseen []Event
+	for _, e := range events {
+		if e.Time.Before(c.Time) {
+			continue
+		}
+		if e.Time.Equal(c.Time) && c.seen(Key(e)) {
+			continue
+		}
+		unseen = append(unseen, e)
+	}
+	sort.SliceStable(unseen, func(i, j int) bool {
+		return unseen[i].Time.Before(unseen[j].Time)
+	})
+	return unseen
+}
+
+// Advance marks e as seen. Events must be advanced in time order.
+func (c *Cursor) Advance(e Event) {
+	if e.Time.After(c.Time) {
+		c.Time = e.Time
+		c.Seen = nil
+	}
+	if key := Key(e); !c.seen(key) {
+		c.Seen = append(c.Seen, key)
+	}
+}
+
+func (c *Cursor) seen(key string) bool {
+	for _, seen := range c.Seen {
+		if seen == key {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadCursor reads a cursor saved with Save. It returns nil without error when
+// path does not exist.
+func LoadCursor(path string) (*Cursor, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit cursor: %w", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid audit cursor %s: %w", path, err)
+	}
+	return &cursor, nil
+}
+
+// Save writes the cursor to path
+func (c *Cursor) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to save audit cursor: %w", err)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save audit cursor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	at := func(minute int, action string) Event {
+		return Event{Time: time.Date(2024, 1, 3, 10, minute, 0, 0, time.UTC), User: "alice", Action: action}
+	}
+	events := []Event{at(2, "update"), at(1, "create"), at(2, "delete")}
+
+	cursor := &Cursor{}
+	unseen := cursor.Unseen(events)
+	if len(unseen) != 3 || unseen[0].Action != "create" {
+		t.Fatalf("expected all events sorted by time, got %+v", unseen)
+	}
+
+	cursor.Advance(unseen[0])
+	cursor.Advance(unseen[1])
+	unseen = cursor.Unseen(events)
+	if len(unseen) != 1 || unseen[0].Action != "delete" {
+		t.Fatalf("expected the event with the same time not yet seen, got %+v", unseen)
+	}
+
+	cursor.Advance(unseen[0])
+	if len(cursor.Seen) != 2 {
+		t.Errorf("expected the keys of both events at the cursor time, got %v", cursor.Seen)
+	}
+
+	events = append(events, at(3, "restart"))
+	unseen = cursor.Unseen(events)
+	if len(unseen) != 1 || unseen[0].Action != "restart" {
+		t.Errorf("expected only the new event, got %+v", unseen)
+	}
+}
+
+func TestCursorSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursor.json")
+
+	cursor, err := LoadCursor(path)
+	if err != nil || cursor != nil {
+		t.Fatalf("expected no cursor, got %v, %v", cursor, err)
+	}
+
+	event := Event{Time: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), Action: "create"}
+	saved := &Cursor{}
+	saved.Advance(event)
+	if err := saved.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cursor, err = LoadCursor(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cursor.Unseen([]Event{event})) != 0 {
+		t.Error("expected the saved event to be seen")
+	}
+}
@@ -0,0 +1,258 @@
+// Package doctor runs connectivity checks against a broker hostname and
+// reports each result with a remediation hint.
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Check statuses
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// CertificateExpiryWarning is how long before expiry a certificate is reported
+const CertificateExpiryWarning = 14 * 24 * time.Hour
+
+// Result is the outcome of one check
+type Result struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Message  string  `json:"message"`
+	Hint     string  `json:"hint,omitempty"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// Port is a TCP port to test. Failures on optional ports are warnings.
+type Port struct {
+	Name     string
+	Port     int
+	Optional bool
+}
+
+// DefaultPorts are the ports CloudAMQP instances listen on
+var DefaultPorts = []Port{
+	{Name: "AMQP", Port: 5672},
+	{Name: "AMQPS", Port: 5671},
+	{Name: "MQTT", Port: 1883, Optional: true},
+	{Name: "MQTTS", Port: 8883, Optional: true},
+	{Name: "HTTPS", Port: 443},
+}
+
+// Checker runs the checks. AMQP and Management are optional callbacks that
+// test the protocol level with credentials and return a message describing
+// success; nil callbacks are skipped. Their context ends at the timeout, and
+// they must close any connection they opened when it does.
+type Checker struct {
+	Hostname string
+	Ports    []Port
+	// TLSPort is the port whose certificate is validated
+	TLSPort int
+	// TLSConfig overrides the TLS configuration, e.g. the root CAs in tests
+	TLSConfig *tls.Config
+	Timeout   time.Duration
+
+	AMQP       func(ctx context.Context) (string, error)
+	Management func(ctx context.Context) (string, error)
+
+	Now func() time.Time
+}
+
+// New returns a checker for hostname with the default ports
+func New(hostname string) *Checker {
+	return &Checker{
+		Hostname: hostname,
+		Ports:    DefaultPorts,
+		TLSPort:  5671,
+		Timeout:  5 * time.Second,
+		Now:      time.Now,
+	}
+}
+
+// Run runs all checks in order. When the hostname does not resolve the
+// remaining checks are skipped.
+func (c *Checker) Run(ctx context.Context) []Result {
+	var results []Result
+
+	dns := c.run("dns", func() (string, string, error) { return c.checkDNS(ctx) })
+	results = append(results, dns)
+
+	names := []string{}
+	for _, p := range c.Ports {
+		names = append(names, fmt.Sprintf("tcp/%d", p.Port))
+	}
+	names = append(names, "tls", "amqp", "management")
+
+	if dns.Status == StatusFail {
+		for _, name := range names {
+			results = append(results, Result{Name: name, Status: StatusSkip, Message: "skipped, hostname does not resolve"})
+		}
+		return results
+	}
+
+	reachable := make(map[int]bool)
+	for _, p := range c.Ports {
+		result := c.run(fmt.Sprintf("tcp/%d", p.Port), func() (string, string, error) { return c.checkTCP(ctx, p) })
+		if result.Status == StatusFail && p.Optional {
+			result.Status = StatusWarn
+		}
+		reachable[p.Port] = result.Status == StatusPass
+		results = append(results, result)
+	}
+
+	if reachable[c.TLSPort] {
+		results = append(results, c.checkTLS(ctx))
+	} else {
+		results = append(results, Result{Name: "tls", Status: StatusSkip, Message: fmt.Sprintf("skipped, port %d is not reachable", c.TLSPort)})
+	}
+
+	results = append(results, c.runCallback(ctx, "amqp", c.AMQP,
+		"Check the username, password and vhost of the instance URL. If the password was rotated, fetch the new URL with 'cloudamqp instance get'"))
+	results = append(results, c.runCallback(ctx, "management", c.Management,
+		"Check that outbound HTTPS (443) is allowed and that the instance URL credentials are valid"))
+
+	return results
+}
+
+// Failed returns the number of failed checks
+func Failed(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if r.Status == StatusFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (c *Checker) ctx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// run times a check returning (message, hint, err). A non-nil error fails
+// the check with the error as message.
+func (c *Checker) run(name string, check func() (string, string, error)) Result {
+	start := c.Now()
+	message, hint, err := check()
+	result := Result{Name: name, Status: StatusPass, Message: message}
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		result.Hint = hint
+	}
+	result.Duration = float64(c.Now().Sub(start).Microseconds()) / 1000
+	return result
+}
+
+// runCallback runs a protocol check, giving up after the timeout. The
+// callback's context is cancelled then, so that it stops and cleans up.
+func (c *Checker) runCallback(ctx context.Context, name string, callback func(context.Context) (string, error), hint string) Result {
+	if callback == nil {
+		return Result{Name: name, Status: StatusSkip, Message: "skipped"}
+	}
+	return c.run(name, func() (string, string, error) {
+		ctx, cancel := c.ctx(ctx)
+		defer cancel()
+
+		type outcome struct {
+			message string
+			err     error
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			message, err := callback(ctx)
+			done <- outcome{message, err}
+		}()
+
+		select {
+		case o := <-done:
+			return o.message, hint, o.err
+		case <-ctx.Done():
+			return "", hint, fmt.Errorf("no answer within %s", c.Timeout)
+		}
+	})
+}
+
+func (c *Checker) checkDNS(ctx context.Context) (string, string, error) {
+	ctx, cancel := c.ctx(ctx)
+	defer cancel()
+
+	hint := "Check that the hostname is correct and that your DNS resolver can resolve public hostnames"
+	addrs, err := net.DefaultResolver.LookupHost(ctx, c.Hostname)
+	if err != nil {
+		return "", hint, fmt.Errorf("failed to resolve %s: %v", c.Hostname, err)
+	}
+	return fmt.Sprintf("%s resolves to %s", c.Hostname, strings.Join(addrs, ", ")), "", nil
+}
+
+func (c *Checker) checkTCP(ctx context.Context, p Port) (string, string, error) {
+	ctx, cancel := c.ctx(ctx)
+	defer cancel()
+
+	addr := net.JoinHostPort(c.Hostname, strconv.Itoa(p.Port))
+	hint := fmt.Sprintf("Allow outbound TCP to %s in your firewall, proxy or security group", addr)
+	if p.Optional {
+		hint += fmt.Sprintf(". %s may also not be enabled on this instance", p.Name)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", hint, fmt.Errorf("%s port %d is not reachable: %v", p.Name, p.Port, err)
+	}
+	conn.Close()
+	return fmt.Sprintf("%s port %d is reachable", p.Name, p.Port), "", nil
+}
+
+func (c *Checker) checkTLS(ctx context.Context) Result {
+	var cert *x509.Certificate
+	result := c.run("tls", func() (string, string, error) {
+		ctx, cancel := c.ctx(ctx)
+		defer cancel()
+
+		config := &tls.Config{ServerName: c.Hostname}
+		if c.TLSConfig != nil {
+			config = c.TLSConfig.Clone()
+			if config.ServerName == "" {
+				config.ServerName = c.Hostname
+			}
+		}
+
+		dialer := tls.Dialer{Config: config}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.Hostname, strconv.Itoa(c.TLSPort)))
+		if err != nil {
+			hint := "The certificate chain could not be verified. Check that your system CA certificates are up to date and that no proxy intercepts TLS"
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				hint = "The TLS handshake timed out. Check for proxies or firewalls that interfere with TLS"
+			}
+			return "", hint, fmt.Errorf("TLS handshake failed: %v", err)
+		}
+		defer conn.Close()
+
+		cert = conn.(*tls.Conn).ConnectionState().PeerCertificates[0]
+		return fmt.Sprintf("certificate for %s issued by %s, valid until %s",
+			cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.UTC().Format("2006-01-02")), "", nil
+	})
+
+	if cert != nil {
+		remaining := cert.NotAfter.Sub(c.Now())
+		if remaining < CertificateExpiryWarning {
+			result.Status = StatusWarn
+			result.Message += fmt.Sprintf(" (expires in %d days)", int(remaining.Hours()/24))
+			result.Hint = "The certificate expires soon. Certificates are renewed automatically, contact support if it is not renewed"
+		}
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// closedPort returns a local port that nothing listens on
+func closedPort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
+
+func newTestChecker(t *testing.T) (*Checker, *httptest.Server) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	_, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	tlsPort, _ := strconv.Atoi(portStr)
+
+	checker := New("127.0.0.1")
+	checker.Timeout = time.Second
+	checker.TLSPort = tlsPort
+	checker.TLSConfig = &tls.Config{RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs}
+	checker.Ports = []Port{
+		{Name: "AMQPS", Port: tlsPort},
+		{Name: "MQTT", Port: closedPort(t), Optional: true},
+	}
+	return checker, server
+}
+
+func resultsByName(results []Result) map[string]Result {
+	byName := make(map[string]Result)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	return byName
+}
+
+func TestRun(t *testing.T) {
+	checker, server := newTestChecker(t)
+	defer server.Close()
+
+	checker.AMQP = func(context.Context) (string, error) { return "handshake ok", nil }
+	checker.Management = func(context.Context) (string, error) { return "", errors.New("401 Unauthorized") }
+
+	results := checker.Run(context.Background())
+	byName := resultsByName(results)
+
+	assert.Len(t, results, 6)
+	assert.Equal(t, StatusPass, byName["dns"].Status)
+	assert.Equal(t, StatusPass, byName["tcp/"+strconv.Itoa(checker.TLSPort)].Status)
+	assert.Equal(t, StatusWarn, byName["tcp/"+strconv.Itoa(checker.Ports[1].Port)].Status)
+	assert.NotEmpty(t, byName["tcp/"+strconv.Itoa(checker.Ports[1].Port)].Hint)
+	assert.Equal(t, StatusPass, byName["tls"].Status)
+	assert.Contains(t, byName["tls"].Message, "valid until")
+	assert.Equal(t, "handshake ok", byName["amqp"].Message)
+	assert.Equal(t, StatusFail, byName["management"].Status)
+	assert.Equal(t, "401 Unauthorized", byName["management"].Message)
+	assert.NotEmpty(t, byName["management"].Hint)
+	assert.Equal(t, 1, Failed(results))
+}
+
+func TestRun_RequiredPortClosed(t *testing.T) {
+	checker, server := newTestChecker(t)
+	defer server.Close()
+
+	checker.TLSPort = closedPort(t)
+	checker.Ports = []Port{{Name: "AMQPS", Port: checker.TLSPort}}
+
+	byName := resultsByName(checker.Run(context.Background()))
+	assert.Equal(t, StatusFail, byName["tcp/"+strconv.Itoa(checker.TLSPort)].Status)
+	assert.Contains(t, byName["tcp/"+strconv.Itoa(checker.TLSPort)].Hint, "firewall")
+	assert.Equal(t, StatusSkip, byName["tls"].Status)
+	assert.Equal(t, StatusSkip, byName["amqp"].Status)
+}
+
+func TestRun_UntrustedCertificate(t *testing.T) {
+	checker, server := newTestChecker(t)
+	defer server.Close()
+
+	checker.TLSConfig = nil
+
+	byName := resultsByName(checker.Run(context.Background()))
+	assert.Equal(t, StatusFail, byName["tls"].Status)
+	assert.Contains(t, byName["tls"].Hint, "CA certificates")
+}
+
+func TestRun_CertificateExpiresSoon(t *testing.T) {
+	checker, server := newTestChecker(t)
+	defer server.Close()
+
+	notAfter := server.Certificate().NotAfter
+	checker.Now = func() time.Time { return notAfter.Add(-72 * time.Hour) }
+
+	byName := resultsByName(checker.Run(context.Background()))
+	assert.Equal(t, StatusWarn, byName["tls"].Status)
+	assert.Contains(t, byName["tls"].Message, "expires in 3 days")
+}
+
+func TestRun_CallbackTimeout(t *testing.T) {
+	checker, server := newTestChecker(t)
+	defer server.Close()
+
+	checker.Timeout = 50 * time.Millisecond
+	cancelled := make(chan error, 1)
+	checker.AMQP = func(ctx context.Context) (string, error) {
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			cancelled <- nil
+			return "too late", nil
+		}
+	}
+
+	byName := resultsByName(checker.Run(context.Background()))
+	assert.Equal(t, StatusFail, byName["amqp"].Status)
+	assert.Contains(t, byName["amqp"].Message, "no answer within")
+
+	// The callback is told to stop instead of being left running
+	assert.ErrorIs(t, <-cancelled, context.DeadlineExceeded)
+}
+
+func TestRun_DNSFailure(t *testing.T) {
+	checker := New("does-not-exist.invalid")
+	checker.Timeout = time.Second
+
+	results := checker.Run(context.Background())
+	assert.Equal(t, StatusFail, results[0].Status)
+	for _, r := range results[1:] {
+		assert.Equal(t, StatusSkip, r.Status, r.Name)
+	}
+	assert.Equal(t, 1, Failed(results))
+}
@@ -0,0 +1,166 @@
+// Package migrate keeps the local state of an instance migration, so that an
+// interrupted migration can be resumed where it stopped.
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Migration steps, in the order they run
+const (
+	StepCreateTarget = "create-target"
+	StepTargetReady  = "target-ready"
+	StepDefinitions  = "definitions"
+	StepShovels      = "shovels"
+	StepDrained      = "drained"
+)
+
+// Steps lists all steps in order
+var Steps = []string{StepCreateTarget, StepTargetReady, StepDefinitions, StepShovels, StepDrained}
+
+// Shovel is a shovel created for a migrated queue
+type Shovel struct {
+	Vhost string `json:"vhost"`
+	Queue string `json:"queue"`
+	Name  string `json:"name"`
+}
+
+// State is the progress of the migration of one source instance. Vhosts maps
+// each migrated source vhost to its vhost on the target.
+type State struct {
+	SourceID     int               `json:"source_id"`
+	TargetID     int               `json:"target_id,omitempty"`
+	TargetName   string            `json:"target_name"`
+	TargetPlan   string            `json:"target_plan"`
+	TargetRegion string            `json:"target_region"`
+	Completed    []string          `json:"completed"`
+	Vhosts       map[string]string `json:"vhosts,omitempty"`
+	Shovels      []Shovel          `json:"shovels,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+
+	path string
+}
+
+// Dir returns the directory migration state is kept in,
+// CLOUDAMQP_MIGRATION_DIR or ~/.cloudamqp-migrations
+func Dir() (string, error) {
+	if dir := os.Getenv("CLOUDAMQP_MIGRATION_DIR"); dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".cloudamqp-migrations"), nil
+}
+
+// New returns the state of a migration that has not started yet
+func New(dir string, sourceID int, targetName, targetPlan, targetRegion string) *State {
+	return &State{
+		SourceID:     sourceID,
+		TargetName:   targetName,
+		TargetPlan:   targetPlan,
+		TargetRegion: targetRegion,
+		StartedAt:    time.Now().UTC(),
+		path:         statePath(dir, sourceID),
+	}
+}
+
+// Load reads the state of the migration of sourceID from dir. It returns nil
+// without error when there is no migration in progress.
+func Load(dir string, sourceID int) (*State, error) {
+	path := statePath(dir, sourceID)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid migration state %s: %w", path, err)
+	}
+	state.path = path
+	return &state, nil
+}
+
+// Path returns the file the state is saved to
+func (s *State) Path() string {
+	return s.path
+}
+
+// Save writes the state to its file
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to save migration state: %w", err)
+	}
+
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save migration state: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the state file
+func (s *State) Remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove migration state: %w", err)
+	}
+	return nil
+}
+
+// Done reports whether step has completed
+func (s *State) Done(step string) bool {
+	for _, completed := range s.Completed {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete marks step as completed and saves the state
+func (s *State) Complete(step string) error {
+	if !s.Done(step) {
+		s.Completed = append(s.Completed, step)
+	}
+	return s.Save()
+}
+
+// HasShovel reports whether a shovel was created for queue in vhost
+func (s *State) HasShovel(vhost, queue string) bool {
+	for _, shovel := range s.Shovels {
+		if shovel.Vhost == vhost && shovel.Queue == queue {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the first step that has not completed, or "" when all have
+func (s *State) Next() string {
+	for _, step := range Steps {
+		if !s.Done(step) {
+			return step
+		}
+	}
+	return ""
+}
+
+func statePath(dir string, sourceID int) string {
+	return filepath.Join(dir, "instance-"+strconv.Itoa(sourceID)+".json")
+}
@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	state, err := Load(t.TempDir(), 1234)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	state := New(dir, 1234, "app-migrated", "bunny-1", "amazon-web-services::eu-west-1")
+	assert.Equal(t, StepCreateTarget, state.Next())
+
+	state.TargetID = 5678
+	assert.NoError(t, state.Complete(StepCreateTarget))
+	assert.NoError(t, state.Complete(StepTargetReady))
+	state.Vhosts = map[string]string{"/": "/"}
+	state.Shovels = append(state.Shovels, Shovel{Vhost: "/", Queue: "orders", Name: "migrate-orders"})
+	assert.NoError(t, state.Save())
+
+	info, err := os.Stat(filepath.Join(dir, "instance-1234.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := Load(dir, 1234)
+	assert.NoError(t, err)
+	assert.Equal(t, 5678, loaded.TargetID)
+	assert.Equal(t, "bunny-1", loaded.TargetPlan)
+	assert.True(t, loaded.Done(StepTargetReady))
+	assert.False(t, loaded.Done(StepDefinitions))
+	assert.Equal(t, StepDefinitions, loaded.Next())
+	assert.True(t, loaded.HasShovel("/", "orders"))
+	assert.False(t, loaded.HasShovel("other", "orders"))
+
+	assert.NoError(t, loaded.Remove())
+	state, err = Load(dir, 1234)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestComplete_Idempotent(t *testing.T) {
+	state := New(t.TempDir(), 1, "a", "b", "c")
+	for _, step := range Steps {
+		assert.NoError(t, state.Complete(step))
+	}
+	assert.NoError(t, state.Complete(StepShovels))
+	assert.Len(t, state.Completed, len(Steps))
+	assert.Equal(t, "", state.Next())
+}
+
+func TestDir_Env(t *testing.T) {
+	t.Setenv("CLOUDAMQP_MIGRATION_DIR", "/tmp/migrations")
+	dir, err := Dir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/migrations", dir)
+}
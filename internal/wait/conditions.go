@@ -0,0 +1,251 @@
+package wait
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloudamqp-cli/client"
+)
+
+// checkFunc evaluates a condition. Node conditions only consider the named
+// nodes, or all nodes when nodes is empty; other conditions ignore it.
+type checkFunc func(c *client.Client, id int, nodes []string) (bool, string, error)
+
+// Condition is a state of an instance or VPC that can be waited for
+type Condition struct {
+	// Spec is the condition as given by the user, e.g. "plugin-enabled=rabbitmq_top"
+	Spec  string
+	check checkFunc
+	nodes []string
+}
+
+// Check returns a CheckFunc evaluating the condition for the given resource ID.
+// The ID is a VPC ID for VPC conditions and an instance ID otherwise.
+func (cond *Condition) Check(c *client.Client, id int) CheckFunc {
+	return func() (bool, string, error) {
+		return cond.check(c, id, cond.nodes)
+	}
+}
+
+// OnNodes restricts node conditions to the named nodes. An empty list means
+// all nodes.
+func (cond *Condition) OnNodes(nodes []string) *Condition {
+	cond.nodes = nodes
+	return cond
+}
+
+// ConditionHelp lists the supported condition specs with a short description
+var ConditionHelp = []string{
+	"instance-ready\tInstance reports ready",
+	"instance-deleted\tInstance no longer exists",
+	"nodes-running\tAll nodes are running and configured",
+	"nodes-stopped\tAll nodes are stopped",
+	"plugin-enabled=<name>\tPlugin is enabled",
+	"plugin-disabled=<name>\tPlugin is disabled",
+	"plan=<plan>\tInstance is ready on the given plan",
+	"hipe=<true|false>\tHiPE is enabled or disabled on all nodes",
+	"version=<version>\tAll nodes run the given RabbitMQ version",
+	"vpc-ready\tVPC exists and has been provisioned",
+	"disk-size>=<GB>\tAll nodes have at least the given total disk size",
+}
+
+// Parse parses a condition spec such as "instance-ready", "version=3.13.7"
+// or "disk-size>=100".
+func Parse(spec string) (*Condition, error) {
+	spec = strings.TrimSpace(spec)
+
+	if value, ok := strings.CutPrefix(spec, "disk-size>="); ok {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid disk size in condition %q", spec)
+		}
+		return &Condition{Spec: spec, check: diskSizeAtLeast(size)}, nil
+	}
+
+	name, value, hasValue := strings.Cut(spec, "=")
+	if check, ok := stateConditions[name]; ok {
+		if hasValue {
+			return nil, fmt.Errorf("condition %s does not take a value", name)
+		}
+		return &Condition{Spec: spec, check: check}, nil
+	}
+	if newCheck, ok := valueConditions[name]; ok {
+		if value == "" {
+			return nil, fmt.Errorf("condition %s requires a value, e.g. %s=<value>", name, name)
+		}
+		check, err := newCheck(value)
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Spec: spec, check: check}, nil
+	}
+
+	return nil, fmt.Errorf("unknown condition: %s", spec)
+}
+
+// stateConditions are conditions without a value
+var stateConditions = map[string]checkFunc{
+	"instance-ready":   instanceReady,
+	"instance-deleted": instanceDeleted,
+	"nodes-running":    nodesRunning,
+	"nodes-stopped":    nodesStopped,
+	"vpc-ready":        vpcReady,
+}
+
+// valueConditions are conditions of the form name=value
+var valueConditions = map[string]func(value string) (checkFunc, error){
+	"plugin-enabled":  pluginState(true),
+	"plugin-disabled": pluginState(false),
+	"version":         nodesVersion,
+	"plan":            instancePlan,
+	"hipe":            nodesHiPE,
+}
+
+func instanceReady(c *client.Client, id int, _ []string) (bool, string, error) {
+	instance, err := c.GetInstance(id)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check instance status: %w", err)
+	}
+	if instance.Ready {
+		return true, "instance is ready", nil
+	}
+	return false, "instance is not ready", nil
+}
+
+func instanceDeleted(c *client.Client, id int, _ []string) (bool, string, error) {
+	_, err := c.GetInstance(id)
+	if client.IsNotFound(err) {
+		return true, "instance is deleted", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check instance status: %w", err)
+	}
+	return false, "instance still exists", nil
+}
+
+func vpcReady(c *client.Client, id int, _ []string) (bool, string, error) {
+	vpc, err := c.GetVPC(id)
+	if client.IsNotFound(err) {
+		return false, "VPC not found yet", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check VPC status: %w", err)
+	}
+	if vpc.ProviderID != "" {
+		return true, "VPC is ready", nil
+	}
+	return false, "VPC is being provisioned", nil
+}
+
+func instancePlan(plan string) (checkFunc, error) {
+	return func(c *client.Client, id int, _ []string) (bool, string, error) {
+		instance, err := c.GetInstance(id)
+		if err != nil {
+			return false, "", fmt.Errorf("failed to check instance status: %w", err)
+		}
+		if instance.Plan != plan {
+			return false, fmt.Sprintf("instance is on plan %s", instance.Plan), nil
+		}
+		if !instance.Ready {
+			return false, fmt.Sprintf("instance is on plan %s but not ready", plan), nil
+		}
+		return true, fmt.Sprintf("instance is ready on plan %s", plan), nil
+	}, nil
+}
+
+// nodeCheck evaluates match against the selected nodes and is met when all of
+// them match
+func nodeCheck(c *client.Client, id int, nodeNames []string, what string, match func(client.Node) bool) (bool, string, error) {
+	nodes, err := c.ListNodes(strconv.Itoa(id))
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check node status: %w", err)
+	}
+
+	selected := make(map[string]bool, len(nodeNames))
+	for _, name := range nodeNames {
+		selected[name] = true
+	}
+
+	total, matched := 0, 0
+	for _, node := range nodes {
+		if len(selected) > 0 && !selected[node.Name] {
+			continue
+		}
+		total++
+		if match(node) {
+			matched++
+		}
+	}
+
+	status := fmt.Sprintf("%d/%d nodes %s", matched, total, what)
+	return total > 0 && matched == total, status, nil
+}
+
+func nodesRunning(c *client.Client, id int, nodes []string) (bool, string, error) {
+	return nodeCheck(c, id, nodes, "running", func(node client.Node) bool {
+		return node.Running && node.Configured
+	})
+}
+
+func nodesStopped(c *client.Client, id int, nodes []string) (bool, string, error) {
+	return nodeCheck(c, id, nodes, "stopped", func(node client.Node) bool {
+		return !node.Running
+	})
+}
+
+func nodesVersion(version string) (checkFunc, error) {
+	return func(c *client.Client, id int, nodes []string) (bool, string, error) {
+		return nodeCheck(c, id, nodes, "on version "+version, func(node client.Node) bool {
+			return node.RabbitMQVersion == version
+		})
+	}, nil
+}
+
+func nodesHiPE(value string) (checkFunc, error) {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for hipe: %s (expected true or false)", value)
+	}
+	what := "with HiPE disabled"
+	if enabled {
+		what = "with HiPE enabled"
+	}
+	return func(c *client.Client, id int, nodes []string) (bool, string, error) {
+		return nodeCheck(c, id, nodes, what, func(node client.Node) bool {
+			return node.HiPE == enabled
+		})
+	}, nil
+}
+
+func diskSizeAtLeast(size int) checkFunc {
+	return func(c *client.Client, id int, nodes []string) (bool, string, error) {
+		return nodeCheck(c, id, nodes, fmt.Sprintf("with at least %d GB disk", size), func(node client.Node) bool {
+			return node.DiskSize+node.AdditionalDiskSize >= size
+		})
+	}
+}
+
+func pluginState(enabled bool) func(name string) (checkFunc, error) {
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	return func(name string) (checkFunc, error) {
+		return func(c *client.Client, id int, _ []string) (bool, string, error) {
+			plugins, err := c.ListPlugins(strconv.Itoa(id))
+			if err != nil {
+				return false, "", fmt.Errorf("failed to check plugin status: %w", err)
+			}
+			for _, plugin := range plugins {
+				if plugin.Name == name {
+					if plugin.Enabled == enabled {
+						return true, fmt.Sprintf("plugin %s is %s", name, state), nil
+					}
+					return false, fmt.Sprintf("plugin %s is not %s", name, state), nil
+				}
+			}
+			return false, "", fmt.Errorf("plugin %s not found", name)
+		}, nil
+	}
+}
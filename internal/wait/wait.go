@@ -0,0 +1,142 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is returned (wrapped) when a condition is not met before the timeout
+var ErrTimeout = errors.New("timed out")
+
+// Event states
+const (
+	StateWaiting = "waiting"
+	StateDone    = "done"
+	StateTimeout = "timeout"
+	StateError   = "error"
+)
+
+// Event describes the progress of a wait
+type Event struct {
+	Time      time.Time `json:"time"`
+	Condition string    `json:"condition"`
+	State     string    `json:"state"`
+	Attempt   int       `json:"attempt"`
+	Elapsed   float64   `json:"elapsed_seconds"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// CheckFunc reports whether a condition is met, with a short status message
+type CheckFunc func() (done bool, status string, err error)
+
+// Options controls polling behaviour
+type Options struct {
+	// Interval is the delay before the second poll
+	Interval time.Duration
+	// MaxInterval caps the delay between polls when backing off
+	MaxInterval time.Duration
+	// Backoff multiplies the delay after every poll, 1 keeps it fixed
+	Backoff float64
+	// Timeout is the total time to wait, zero waits forever
+	Timeout time.Duration
+	// InitialDelay postpones the first poll, e.g. to let an action take effect
+	InitialDelay time.Duration
+	// Progress is called for every poll result, if set
+	Progress func(Event)
+}
+
+// DefaultOptions returns the options used by commands offering --wait
+func DefaultOptions(timeout time.Duration) Options {
+	return Options{
+		Interval:    10 * time.Second,
+		MaxInterval: time.Minute,
+		Backoff:     1.5,
+		Timeout:     timeout,
+	}
+}
+
+// nextInterval returns the delay to use after current
+func (o Options) nextInterval(current time.Duration) time.Duration {
+	if o.Backoff <= 1 {
+		return current
+	}
+	next := time.Duration(float64(current) * o.Backoff)
+	if o.MaxInterval > 0 && next > o.MaxInterval {
+		next = o.MaxInterval
+	}
+	return next
+}
+
+// Until polls check until it reports done, returns an error, or the timeout
+// or context expires. The condition name is only used for events and errors.
+func Until(ctx context.Context, condition string, check CheckFunc, opts Options) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	startTime := time.Now()
+	emit := func(state string, attempt int, message string) {
+		if opts.Progress == nil {
+			return
+		}
+		opts.Progress(Event{
+			Time:      time.Now(),
+			Condition: condition,
+			State:     state,
+			Attempt:   attempt,
+			Elapsed:   time.Since(startTime).Round(time.Second).Seconds(),
+			Message:   message,
+		})
+	}
+	timeout := func(attempt int) error {
+		elapsed := time.Since(startTime).Round(time.Second)
+		emit(StateTimeout, attempt, "")
+		return fmt.Errorf("timeout after %s waiting for %s: %w", elapsed, condition, ErrTimeout)
+	}
+
+	if opts.InitialDelay > 0 {
+		if !sleep(ctx, opts.InitialDelay) {
+			return timeout(0)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		done, status, err := check()
+		if err != nil {
+			emit(StateError, attempt, err.Error())
+			return err
+		}
+		if done {
+			emit(StateDone, attempt, status)
+			return nil
+		}
+		emit(StateWaiting, attempt, status)
+
+		if !sleep(ctx, interval) {
+			return timeout(attempt)
+		}
+		interval = opts.nextInterval(interval)
+	}
+}
+
+// sleep waits for d and reports false if ctx expired first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,176 @@
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cloudamqp-cli/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func fastOptions() Options {
+	return Options{
+		Interval:    time.Millisecond,
+		MaxInterval: 4 * time.Millisecond,
+		Backoff:     2,
+		Timeout:     time.Second,
+	}
+}
+
+func TestUntil_Done(t *testing.T) {
+	calls := 0
+	var events []Event
+
+	opts := fastOptions()
+	opts.Progress = func(e Event) { events = append(events, e) }
+
+	err := Until(context.Background(), "test", func() (bool, string, error) {
+		calls++
+		return calls == 3, "checking", nil
+	}, opts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, events, 3)
+	assert.Equal(t, StateWaiting, events[0].State)
+	assert.Equal(t, StateDone, events[2].State)
+	assert.Equal(t, 3, events[2].Attempt)
+	assert.Equal(t, "test", events[2].Condition)
+}
+
+func TestUntil_Timeout(t *testing.T) {
+	opts := fastOptions()
+	opts.Timeout = 20 * time.Millisecond
+
+	var last Event
+	opts.Progress = func(e Event) { last = e }
+
+	err := Until(context.Background(), "never", func() (bool, string, error) {
+		return false, "", nil
+	}, opts)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Contains(t, err.Error(), "waiting for never")
+	assert.Equal(t, StateTimeout, last.State)
+}
+
+func TestUntil_CheckError(t *testing.T) {
+	checkErr := errors.New("boom")
+
+	err := Until(context.Background(), "test", func() (bool, string, error) {
+		return false, "", checkErr
+	}, fastOptions())
+
+	assert.ErrorIs(t, err, checkErr)
+}
+
+func TestNextInterval(t *testing.T) {
+	opts := Options{Backoff: 2, MaxInterval: 30 * time.Second}
+	assert.Equal(t, 20*time.Second, opts.nextInterval(10*time.Second))
+	assert.Equal(t, 30*time.Second, opts.nextInterval(20*time.Second))
+
+	opts = Options{Backoff: 1}
+	assert.Equal(t, 10*time.Second, opts.nextInterval(10*time.Second))
+}
+
+func TestParse(t *testing.T) {
+	valid := []string{
+		"instance-ready",
+		"instance-deleted",
+		"nodes-running",
+		"plugin-enabled=rabbitmq_top",
+		"version=3.13.7",
+		"vpc-ready",
+		"disk-size>=100",
+	}
+	for _, spec := range valid {
+		cond, err := Parse(spec)
+		assert.NoError(t, err, spec)
+		assert.Equal(t, spec, cond.Spec)
+	}
+
+	invalid := []string{
+		"unknown",
+		"instance-ready=yes",
+		"plugin-enabled",
+		"version=",
+		"disk-size>=lots",
+		"hipe=maybe",
+	}
+	for _, spec := range invalid {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/instances/1":
+			json.NewEncoder(w).Encode(client.Instance{ID: 1, Plan: "bunny-1", Ready: true})
+		case "/instances/2":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Not found"}`))
+		case "/instances/1/nodes":
+			json.NewEncoder(w).Encode([]client.Node{
+				{Name: "node-01", Running: true, Configured: true, HiPE: true, RabbitMQVersion: "3.13.7", DiskSize: 50, AdditionalDiskSize: 50},
+				{Name: "node-02", Running: false, Configured: true, HiPE: true, RabbitMQVersion: "3.12.0", DiskSize: 50, AdditionalDiskSize: 50},
+			})
+		case "/instances/1/plugins":
+			json.NewEncoder(w).Encode([]client.Plugin{{Name: "rabbitmq_top", Enabled: true}})
+		case "/vpcs/1":
+			json.NewEncoder(w).Encode(client.VPC{ID: 1, ProviderID: "vpc-123"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c := client.NewWithBaseURL("test-api-key", server.URL, "test")
+
+	tests := []struct {
+		spec string
+		id   int
+		done bool
+	}{
+		{"instance-ready", 1, true},
+		{"instance-deleted", 1, false},
+		{"instance-deleted", 2, true},
+		{"nodes-running", 1, false},
+		{"nodes-stopped", 1, false},
+		{"plan=bunny-1", 1, true},
+		{"plan=rabbit-1", 1, false},
+		{"hipe=true", 1, true},
+		{"hipe=false", 1, false},
+		{"version=3.13.7", 1, false},
+		{"disk-size>=100", 1, true},
+		{"disk-size>=250", 1, false},
+		{"plugin-enabled=rabbitmq_top", 1, true},
+		{"plugin-disabled=rabbitmq_top", 1, false},
+		{"vpc-ready", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			cond, err := Parse(tt.spec)
+			assert.NoError(t, err)
+
+			done, _, err := cond.Check(c, tt.id)()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.done, done)
+		})
+	}
+
+	cond, _ := Parse("nodes-running")
+	_, status, _ := cond.Check(c, 1)()
+	assert.Equal(t, "1/2 nodes running", status)
+
+	done, status, _ := cond.OnNodes([]string{"node-01"}).Check(c, 1)()
+	assert.True(t, done)
+	assert.Equal(t, "1/1 nodes running", status)
+}
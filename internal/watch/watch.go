@@ -0,0 +1,201 @@
+// Package watch polls a list of resources and reports what changed between
+// polls, for commands offering --watch.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event types
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+	Error    = "error"
+)
+
+// Field is a named value of a resource, as shown to the user
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Resource is one watched item. Key identifies it between polls and Fields
+// are compared to detect changes, in the order they are listed.
+type Resource struct {
+	Key    string
+	Fields []Field
+}
+
+// Change is a field whose value changed between two polls
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Event describes one change to the watched resources
+type Event struct {
+	Time    time.Time         `json:"time"`
+	Type    string            `json:"type"`
+	Kind    string            `json:"kind"`
+	Key     string            `json:"key,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Changes []Change          `json:"changes,omitempty"`
+	Message string            `json:"message,omitempty"`
+
+	order []string
+}
+
+// String formats the event as a human readable line
+func (e Event) String() string {
+	prefix := e.Time.Format("15:04:05") + " " + strings.ToUpper(e.Type) + " " + e.Kind
+	switch e.Type {
+	case Added:
+		parts := make([]string, 0, len(e.order))
+		for _, name := range e.order {
+			parts = append(parts, name+"="+e.Fields[name])
+		}
+		return fmt.Sprintf("%s %s %s", prefix, e.Key, strings.Join(parts, " "))
+	case Modified:
+		parts := make([]string, 0, len(e.Changes))
+		for _, change := range e.Changes {
+			parts = append(parts, fmt.Sprintf("%s: %s -> %s", change.Field, change.Old, change.New))
+		}
+		return fmt.Sprintf("%s %s %s", prefix, e.Key, strings.Join(parts, ", "))
+	case Error:
+		return prefix + ": " + e.Message
+	default:
+		return prefix + " " + e.Key
+	}
+}
+
+// Diff returns the events turning old into current, for resources of kind.
+// Removed resources are reported first, then added and modified resources in
+// the order of current.
+func Diff(kind string, old, current []Resource, now time.Time) []Event {
+	previous := make(map[string]Resource, len(old))
+	for _, r := range old {
+		previous[r.Key] = r
+	}
+	seen := make(map[string]bool, len(current))
+	for _, r := range current {
+		seen[r.Key] = true
+	}
+
+	var events []Event
+	var removed []string
+	for key := range previous {
+		if !seen[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		events = append(events, Event{Time: now, Type: Removed, Kind: kind, Key: key})
+	}
+
+	for _, r := range current {
+		before, ok := previous[r.Key]
+		if !ok {
+			events = append(events, added(kind, r, now))
+			continue
+		}
+		if changes := compare(before, r); len(changes) > 0 {
+			events = append(events, Event{Time: now, Type: Modified, Kind: kind, Key: r.Key, Changes: changes})
+		}
+	}
+	return events
+}
+
+func added(kind string, r Resource, now time.Time) Event {
+	e := Event{Time: now, Type: Added, Kind: kind, Key: r.Key, Fields: make(map[string]string, len(r.Fields))}
+	for _, f := range r.Fields {
+		e.Fields[f.Name] = f.Value
+		e.order = append(e.order, f.Name)
+	}
+	return e
+}
+
+func compare(old, current Resource) []Change {
+	before := make(map[string]string, len(old.Fields))
+	for _, f := range old.Fields {
+		before[f.Name] = f.Value
+	}
+
+	var changes []Change
+	for _, f := range current.Fields {
+		if value, ok := before[f.Name]; !ok || value != f.Value {
+			changes = append(changes, Change{Field: f.Name, Old: value, New: f.Value})
+		}
+	}
+	return changes
+}
+
+// PollFunc returns the current resources
+type PollFunc func() ([]Resource, error)
+
+// Options controls how Run polls and prints events
+type Options struct {
+	// Kind names the watched resources in events, e.g. "instance"
+	Kind string
+	// Interval is the delay between polls
+	Interval time.Duration
+	// JSON prints events as JSON lines instead of human readable lines
+	JSON bool
+	// Out receives the events
+	Out io.Writer
+}
+
+// Run polls every interval until ctx is cancelled and prints the changes
+// since initial, the resources the caller already showed. Poll errors are
+// printed as events and do not stop the watch.
+func Run(ctx context.Context, initial []Resource, poll PollFunc, opts Options) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	last := initial
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := poll()
+		if err != nil {
+			Print(opts, Event{Time: time.Now(), Type: Error, Kind: opts.Kind, Message: err.Error()})
+			continue
+		}
+		for _, e := range Diff(opts.Kind, last, current, time.Now()) {
+			Print(opts, e)
+		}
+		last = current
+	}
+}
+
+// Initial returns added events for resources, used to print the first poll
+// as JSON events
+func Initial(kind string, resources []Resource, now time.Time) []Event {
+	return Diff(kind, nil, resources, now)
+}
+
+// Print writes e to opts.Out in the format selected by opts
+func Print(opts Options, e Event) {
+	if opts.JSON {
+		json.NewEncoder(opts.Out).Encode(e)
+		return
+	}
+	fmt.Fprintln(opts.Out, e.String())
+}
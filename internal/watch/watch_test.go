@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func resource(key string, fields ...string) Resource {
+	r := Resource{Key: key}
+	for i := 0; i+1 < len(fields); i += 2 {
+		r.Fields = append(r.Fields, Field{Name: fields[i], Value: fields[i+1]})
+	}
+	return r
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	old := []Resource{
+		resource("1", "name", "a", "ready", "false"),
+		resource("2", "name", "b", "ready", "true"),
+		resource("3", "name", "c", "ready", "true"),
+	}
+	current := []Resource{
+		resource("1", "name", "a", "ready", "true"),
+		resource("3", "name", "c", "ready", "true"),
+		resource("4", "name", "d", "ready", "false"),
+	}
+
+	events := Diff("instance", old, current, now)
+
+	var lines []string
+	for _, e := range events {
+		lines = append(lines, e.String())
+	}
+	want := []string{
+		"12:30:00 REMOVED instance 2",
+		"12:30:00 MODIFIED instance 1 ready: false -> true",
+		"12:30:00 ADDED instance 4 name=d ready=false",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got events:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	resources := []Resource{resource("1", "name", "a")}
+	if events := Diff("vpc", resources, resources, time.Now()); len(events) != 0 {
+		t.Errorf("expected no events, got %v", events)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	polls := []func() ([]Resource, error){
+		func() ([]Resource, error) { return nil, errors.New("boom") },
+		func() ([]Resource, error) { return []Resource{resource("n1", "running", "true")}, nil },
+	}
+
+	var out bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempt := 0
+	poll := func() ([]Resource, error) {
+		if attempt >= len(polls) {
+			cancel()
+			return []Resource{resource("n1", "running", "true")}, nil
+		}
+		attempt++
+		return polls[attempt-1]()
+	}
+
+	initial := []Resource{resource("n1", "running", "false")}
+	opts := Options{Kind: "node", Interval: time.Millisecond, JSON: true, Out: &out}
+	if err := Run(ctx, initial, poll, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var events []Event
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var e Event
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("invalid JSON event: %v", err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %s", len(events), out.String())
+	}
+	if events[0].Type != Error || events[0].Message != "boom" {
+		t.Errorf("expected error event, got %+v", events[0])
+	}
+	if events[1].Type != Modified || events[1].Key != "n1" || len(events[1].Changes) != 1 || events[1].Changes[0].New != "true" {
+		t.Errorf("expected modified event, got %+v", events[1])
+	}
+}
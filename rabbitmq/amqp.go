@@ -0,0 +1,271 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"time"
+	"unicode/utf8"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Ack modes for consumed messages
+const (
+	// AckModeRequeue returns messages to the queue, leaving it unchanged
+	AckModeRequeue = "requeue"
+	// AckModeAck acknowledges and thereby removes messages
+	AckModeAck = "ack"
+	// AckModeReject rejects messages without requeueing, dead-lettering them
+	// if the queue has a dead letter exchange
+	AckModeReject = "reject"
+)
+
+// amqpHandshakeTimeout bounds connecting and the TLS and AMQP handshakes
+const amqpHandshakeTimeout = 30 * time.Second
+
+// amqpChannel is the part of *amqp.Channel used by AMQPClient
+type amqpChannel interface {
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(c chan amqp.Return) chan amqp.Return
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+}
+
+// AMQPClient publishes and consumes messages over a single AMQP channel
+type AMQPClient struct {
+	conn   *amqp.Connection
+	ch     amqpChannel
+	dryRun io.Writer
+}
+
+// DialAMQPContext connects to an instance with its AMQP or AMQPS URL. The TCP
+// connection, TLS and AMQP handshakes must finish before the deadline of ctx,
+// and the connection is closed if ctx ends before the client is returned.
+func DialAMQPContext(ctx context.Context, instanceURL, version string) (*AMQPClient, error) {
+	properties := amqp.NewConnectionProperties()
+	properties.SetClientConnectionName("cloudamqp-cli/" + version)
+
+	// Until stop is called, the dialed connection is closed when ctx ends
+	stop := func() bool { return true }
+	defer func() { stop() }()
+
+	conn, err := amqp.DialConfig(instanceURL, amqp.Config{
+		Properties: properties,
+		Heartbeat:  10 * time.Second,
+		Dial: func(network, addr string) (net.Conn, error) {
+			// Like amqp.DefaultDial, the handshakes get 30 seconds unless ctx
+			// ends sooner; the deadline is cleared once the connection is open
+			deadline := time.Now().Add(amqpHandshakeTimeout)
+			if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+				deadline = d
+			}
+			d := net.Dialer{Deadline: deadline}
+			conn, err := d.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if err := conn.SetDeadline(deadline); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			stop = context.AfterFunc(ctx, func() { conn.Close() })
+			return conn, nil
+		},
+	})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("failed to connect: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	if !stop() {
+		// ctx ended and closed the connection before the channel was used
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect: %w", ctx.Err())
+	}
+
+	return &AMQPClient{conn: conn, ch: ch}, nil
+}
+
+// SetDryRun enables dry-run mode. Publishing, and consuming with an ack mode
+// that changes the queue, is described on w instead and returns ErrDryRun.
+func (a *AMQPClient) SetDryRun(w io.Writer) {
+	a.dryRun = w
+}
+
+// Close closes the connection
+func (a *AMQPClient) Close() error {
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.Close()
+}
+
+// Publish publishes a message as mandatory and waits for the broker to
+// confirm it. A message that could not be routed to any queue is an error.
+func (a *AMQPClient) Publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	if a.dryRun != nil {
+		fmt.Fprintf(a.dryRun, "DRY RUN: publish %d bytes to exchange %q with routing key %q\n", len(msg.Body), exchange, routingKey)
+		return ErrDryRun
+	}
+
+	if err := a.ch.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	returns := a.ch.NotifyReturn(make(chan amqp.Return, 1))
+	confirms := a.ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	if err := a.ch.PublishWithContext(ctx, exchange, routingKey, true, false, msg); err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+
+	select {
+	case r := <-returns:
+		return fmt.Errorf("message was returned: %s (%d)", r.ReplyText, r.ReplyCode)
+	case confirm := <-confirms:
+		// A return is sent before the confirm, but both may be ready by now
+		select {
+		case r := <-returns:
+			return fmt.Errorf("message was returned: %s (%d)", r.ReplyText, r.ReplyCode)
+		default:
+		}
+		if !confirm.Ack {
+			return fmt.Errorf("message was rejected by the broker")
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("no publisher confirm received: %w", ctx.Err())
+	}
+}
+
+// Consume receives up to count messages from queue and passes them to handle.
+// Messages are acknowledged according to mode. It returns the number of
+// messages received, and an error if ctx ends before count messages arrived.
+func (a *AMQPClient) Consume(ctx context.Context, queue string, count int, mode string, handle func(amqp.Delivery) error) (int, error) {
+	if mode != AckModeRequeue && mode != AckModeAck && mode != AckModeReject {
+		return 0, fmt.Errorf("invalid ack mode %q, must be one of: requeue, ack, reject", mode)
+	}
+	if a.dryRun != nil && mode != AckModeRequeue {
+		fmt.Fprintf(a.dryRun, "DRY RUN: consume %d messages from queue %q with ack mode %s\n", count, queue, mode)
+		return 0, ErrDryRun
+	}
+
+	// With a prefetch of count the broker sends no more than count messages,
+	// so requeued messages are not delivered again before we are done
+	if err := a.ch.Qos(count, 0, false); err != nil {
+		return 0, fmt.Errorf("failed to set prefetch: %w", err)
+	}
+	deliveries, err := a.ch.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume: %w", err)
+	}
+
+	received := 0
+	var last *amqp.Delivery
+	defer func() {
+		if mode == AckModeRequeue && last != nil {
+			last.Nack(true, true)
+		}
+	}()
+
+	for received < count {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				return received, fmt.Errorf("channel closed after %d of %d messages", received, count)
+			}
+			received++
+			last = &d
+
+			if err := handle(d); err != nil {
+				return received, err
+			}
+
+			switch mode {
+			case AckModeAck:
+				err = d.Ack(false)
+			case AckModeReject:
+				err = d.Nack(false, false)
+			}
+			if err != nil {
+				return received, fmt.Errorf("failed to acknowledge message: %w", err)
+			}
+		case <-ctx.Done():
+			return received, fmt.Errorf("received %d of %d messages: %w", received, count, ctx.Err())
+		}
+	}
+	return received, nil
+}
+
+// MessageProperties are the AMQP properties of a consumed message
+type MessageProperties struct {
+	ContentType     string         `json:"content_type,omitempty"`
+	ContentEncoding string         `json:"content_encoding,omitempty"`
+	Headers         map[string]any `json:"headers,omitempty"`
+	DeliveryMode    uint8          `json:"delivery_mode,omitempty"`
+	Priority        uint8          `json:"priority,omitempty"`
+	CorrelationID   string         `json:"correlation_id,omitempty"`
+	ReplyTo         string         `json:"reply_to,omitempty"`
+	Expiration      string         `json:"expiration,omitempty"`
+	MessageID       string         `json:"message_id,omitempty"`
+	Timestamp       *time.Time     `json:"timestamp,omitempty"`
+	Type            string         `json:"type,omitempty"`
+	UserID          string         `json:"user_id,omitempty"`
+	AppID           string         `json:"app_id,omitempty"`
+}
+
+// ConsumedMessage is a consumed message in a form suitable for JSON output.
+// Bodies that are not valid UTF-8 are base64 encoded.
+type ConsumedMessage struct {
+	Exchange     string            `json:"exchange"`
+	RoutingKey   string            `json:"routing_key"`
+	Redelivered  bool              `json:"redelivered"`
+	Properties   MessageProperties `json:"properties"`
+	Body         string            `json:"body"`
+	BodyEncoding string            `json:"body_encoding"`
+}
+
+// NewConsumedMessage converts a delivery for output
+func NewConsumedMessage(d amqp.Delivery) ConsumedMessage {
+	msg := ConsumedMessage{
+		Exchange:    d.Exchange,
+		RoutingKey:  d.RoutingKey,
+		Redelivered: d.Redelivered,
+		Properties: MessageProperties{
+			ContentType:     d.ContentType,
+			ContentEncoding: d.ContentEncoding,
+			Headers:         d.Headers,
+			DeliveryMode:    d.DeliveryMode,
+			Priority:        d.Priority,
+			CorrelationID:   d.CorrelationId,
+			ReplyTo:         d.ReplyTo,
+			Expiration:      d.Expiration,
+			MessageID:       d.MessageId,
+			Type:            d.Type,
+			UserID:          d.UserId,
+			AppID:           d.AppId,
+		},
+		Body:         string(d.Body),
+		BodyEncoding: "string",
+	}
+	if !d.Timestamp.IsZero() {
+		ts := d.Timestamp
+		msg.Properties.Timestamp = &ts
+	}
+	if !utf8.Valid(d.Body) {
+		msg.Body = base64.StdEncoding.EncodeToString(d.Body)
+		msg.BodyEncoding = "base64"
+	}
+	return msg
+}
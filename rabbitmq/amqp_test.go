@@ -0,0 +1,222 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChannel is an in-process stand-in for an AMQP channel. Published
+// messages are confirmed, or returned when routed to returnKey, and
+// deliveries are served from a buffered channel.
+type fakeChannel struct {
+	published  []amqp.Publishing
+	returnKey  string
+	nack       bool
+	confirms   chan amqp.Confirmation
+	returns    chan amqp.Return
+	deliveries chan amqp.Delivery
+	prefetch   int
+}
+
+func (f *fakeChannel) Confirm(noWait bool) error { return nil }
+
+func (f *fakeChannel) NotifyPublish(c chan amqp.Confirmation) chan amqp.Confirmation {
+	f.confirms = c
+	return c
+}
+
+func (f *fakeChannel) NotifyReturn(c chan amqp.Return) chan amqp.Return {
+	f.returns = c
+	return c
+}
+
+func (f *fakeChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	f.published = append(f.published, msg)
+	if key == f.returnKey {
+		f.returns <- amqp.Return{ReplyCode: 312, ReplyText: "NO_ROUTE"}
+	}
+	f.confirms <- amqp.Confirmation{DeliveryTag: uint64(len(f.published)), Ack: !f.nack}
+	return nil
+}
+
+func (f *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	f.prefetch = prefetchCount
+	return nil
+}
+
+func (f *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	return f.deliveries, nil
+}
+
+// fakeAcknowledger records how deliveries were acknowledged
+type fakeAcknowledger struct {
+	acks     []uint64
+	nacks    []uint64
+	requeued []bool
+	multiple []bool
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acks = append(a.acks, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacks = append(a.nacks, tag)
+	a.requeued = append(a.requeued, requeue)
+	a.multiple = append(a.multiple, multiple)
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func newFakeDeliveries(ack amqp.Acknowledger, bodies ...string) chan amqp.Delivery {
+	deliveries := make(chan amqp.Delivery, len(bodies))
+	for i, body := range bodies {
+		deliveries <- amqp.Delivery{Acknowledger: ack, DeliveryTag: uint64(i + 1), RoutingKey: "q", Body: []byte(body)}
+	}
+	return deliveries
+}
+
+func TestPublish(t *testing.T) {
+	ch := &fakeChannel{returnKey: "unroutable"}
+	a := &AMQPClient{ch: ch}
+	ctx := context.Background()
+
+	err := a.Publish(ctx, "", "orders", amqp.Publishing{Body: []byte("hello")})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(ch.published[0].Body))
+
+	err = a.Publish(ctx, "", "unroutable", amqp.Publishing{Body: []byte("hello")})
+	assert.EqualError(t, err, "message was returned: NO_ROUTE (312)")
+
+	ch.nack = true
+	err = a.Publish(ctx, "", "orders", amqp.Publishing{Body: []byte("hello")})
+	assert.EqualError(t, err, "message was rejected by the broker")
+}
+
+func TestPublish_DryRun(t *testing.T) {
+	ch := &fakeChannel{}
+	a := &AMQPClient{ch: ch}
+	var out bytes.Buffer
+	a.SetDryRun(&out)
+
+	err := a.Publish(context.Background(), "orders", "eu.new", amqp.Publishing{Body: []byte("hello")})
+	assert.ErrorIs(t, err, ErrDryRun)
+	assert.Equal(t, "DRY RUN: publish 5 bytes to exchange \"orders\" with routing key \"eu.new\"\n", out.String())
+	assert.Empty(t, ch.published)
+}
+
+func TestConsume_AckModes(t *testing.T) {
+	ack := &fakeAcknowledger{}
+	a := &AMQPClient{ch: &fakeChannel{deliveries: newFakeDeliveries(ack, "one", "two", "three")}}
+
+	var bodies []string
+	received, err := a.Consume(context.Background(), "q", 2, AckModeAck, func(d amqp.Delivery) error {
+		bodies = append(bodies, string(d.Body))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, received)
+	assert.Equal(t, []string{"one", "two"}, bodies)
+	assert.Equal(t, []uint64{1, 2}, ack.acks)
+
+	// Requeue mode returns all messages at once when done
+	ack = &fakeAcknowledger{}
+	ch := &fakeChannel{deliveries: newFakeDeliveries(ack, "one", "two")}
+	a = &AMQPClient{ch: ch}
+	received, err = a.Consume(context.Background(), "q", 2, AckModeRequeue, func(d amqp.Delivery) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, received)
+	assert.Equal(t, 2, ch.prefetch)
+	assert.Equal(t, []uint64{2}, ack.nacks)
+	assert.Equal(t, []bool{true}, ack.requeued)
+	assert.Equal(t, []bool{true}, ack.multiple)
+
+	ack = &fakeAcknowledger{}
+	a = &AMQPClient{ch: &fakeChannel{deliveries: newFakeDeliveries(ack, "one")}}
+	_, err = a.Consume(context.Background(), "q", 1, AckModeReject, func(d amqp.Delivery) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false}, ack.requeued)
+
+	_, err = a.Consume(context.Background(), "q", 1, "drop", func(d amqp.Delivery) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestConsume_Timeout(t *testing.T) {
+	ack := &fakeAcknowledger{}
+	a := &AMQPClient{ch: &fakeChannel{deliveries: newFakeDeliveries(ack, "one")}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	received, err := a.Consume(ctx, "q", 3, AckModeRequeue, func(d amqp.Delivery) error { return nil })
+	assert.Equal(t, 1, received)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []uint64{1}, ack.nacks)
+}
+
+func TestNewConsumedMessage(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	msg := NewConsumedMessage(amqp.Delivery{
+		Exchange:     "orders",
+		RoutingKey:   "eu.new",
+		Headers:      amqp.Table{"source": "ci"},
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    ts,
+		Body:         []byte(`{"id": 1}`),
+	})
+	assert.Equal(t, "orders", msg.Exchange)
+	assert.Equal(t, `{"id": 1}`, msg.Body)
+	assert.Equal(t, "string", msg.BodyEncoding)
+	assert.Equal(t, "ci", msg.Properties.Headers["source"])
+	assert.Equal(t, uint8(2), msg.Properties.DeliveryMode)
+	assert.Equal(t, ts, *msg.Properties.Timestamp)
+
+	msg = NewConsumedMessage(amqp.Delivery{Body: []byte{0xff, 0xfe}})
+	assert.Equal(t, "base64", msg.BodyEncoding)
+	assert.Equal(t, "//4=", msg.Body)
+	assert.Nil(t, msg.Properties.Timestamp)
+}
+
+func TestDialAMQPContext_ClosesConnectionAtDeadline(t *testing.T) {
+	// A server that accepts connections but never answers the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = DialAMQPContext(ctx, "amqp://guest:guest@"+listener.Addr().String()+"/", "test")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was left open after the deadline")
+	}
+}
@@ -0,0 +1,95 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Destination types of a binding
+const (
+	DestinationQueue    = "queue"
+	DestinationExchange = "exchange"
+)
+
+type Binding struct {
+	Source          string         `json:"source"`
+	Vhost           string         `json:"vhost"`
+	Destination     string         `json:"destination"`
+	DestinationType string         `json:"destination_type"`
+	RoutingKey      string         `json:"routing_key"`
+	Arguments       map[string]any `json:"arguments"`
+	PropertiesKey   string         `json:"properties_key"`
+}
+
+// ListBindings lists the bindings in vhost, or in all vhosts if vhost is empty
+func (c *Client) ListBindings(vhost string) ([]Binding, error) {
+	endpoint := path("bindings")
+	if vhost != "" {
+		endpoint = path("bindings", vhost)
+	}
+	return c.listBindings(endpoint)
+}
+
+// ListBindingsBetween lists the bindings from the source exchange to a queue
+// or exchange destination
+func (c *Client) ListBindingsBetween(vhost, source, destinationType, destination string) ([]Binding, error) {
+	endpoint, err := bindingPath(vhost, source, destinationType, destination)
+	if err != nil {
+		return nil, err
+	}
+	return c.listBindings(endpoint)
+}
+
+// CreateBinding binds a queue or exchange destination to the source exchange
+func (c *Client) CreateBinding(vhost, source, destinationType, destination, routingKey string, arguments map[string]any) error {
+	endpoint, err := bindingPath(vhost, source, destinationType, destination)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{"routing_key": routingKey}
+	if len(arguments) > 0 {
+		body["arguments"] = arguments
+	}
+
+	_, err = c.makeRequest("POST", endpoint, body)
+	return err
+}
+
+// DeleteBinding deletes the binding identified by its properties key, as
+// returned by ListBindingsBetween
+func (c *Client) DeleteBinding(vhost, source, destinationType, destination, propertiesKey string) error {
+	endpoint, err := bindingPath(vhost, source, destinationType, destination)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.makeRequest("DELETE", endpoint+"/"+url.PathEscape(propertiesKey), nil)
+	return err
+}
+
+func (c *Client) listBindings(endpoint string) ([]Binding, error) {
+	respBody, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var bindings []Binding
+	if err := json.Unmarshal(respBody, &bindings); err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
+
+func bindingPath(vhost, source, destinationType, destination string) (string, error) {
+	switch destinationType {
+	case DestinationQueue:
+		return path("bindings", vhost, "e", exchangePathName(source), "q", destination), nil
+	case DestinationExchange:
+		return path("bindings", vhost, "e", exchangePathName(source), "e", exchangePathName(destination)), nil
+	default:
+		return "", fmt.Errorf("invalid destination type %q, must be queue or exchange", destinationType)
+	}
+}
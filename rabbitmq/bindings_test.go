@@ -0,0 +1,60 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListBindings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/bindings", r.URL.EscapedPath())
+		w.Write([]byte(`[{"source": "orders", "vhost": "/", "destination": "orders.eu",
+			"destination_type": "queue", "routing_key": "eu.#", "properties_key": "eu.%23"}]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	bindings, err := c.ListBindings("")
+	assert.NoError(t, err)
+	assert.Len(t, bindings, 1)
+	assert.Equal(t, "eu.#", bindings[0].RoutingKey)
+	assert.Equal(t, DestinationQueue, bindings[0].DestinationType)
+}
+
+func TestCreateBinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/bindings/%2F/e/orders/e/audit", r.URL.EscapedPath())
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "eu.#", body["routing_key"])
+		assert.NotContains(t, body, "arguments")
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	assert.NoError(t, c.CreateBinding("/", "orders", DestinationExchange, "audit", "eu.#", nil))
+	assert.Error(t, c.CreateBinding("/", "orders", "topic", "audit", "eu.#", nil))
+}
+
+func TestDeleteBinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/bindings/%2F/e/amq.default/q/orders/orders", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	assert.NoError(t, c.DeleteBinding("/", "", DestinationQueue, "orders", "orders"))
+}
@@ -0,0 +1,292 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Definitions is the broker topology as exported by the definitions endpoint.
+// Objects are kept as generic maps so that fields unknown to this client
+// survive an export and import round trip.
+type Definitions struct {
+	RabbitVersion    string           `json:"rabbit_version,omitempty"`
+	RabbitMQVersion  string           `json:"rabbitmq_version,omitempty"`
+	ProductName      string           `json:"product_name,omitempty"`
+	ProductVersion   string           `json:"product_version,omitempty"`
+	Users            []map[string]any `json:"users,omitempty"`
+	Vhosts           []map[string]any `json:"vhosts,omitempty"`
+	Permissions      []map[string]any `json:"permissions,omitempty"`
+	TopicPermissions []map[string]any `json:"topic_permissions,omitempty"`
+	Parameters       []map[string]any `json:"parameters,omitempty"`
+	GlobalParameters []map[string]any `json:"global_parameters,omitempty"`
+	Policies         []map[string]any `json:"policies,omitempty"`
+	Queues           []map[string]any `json:"queues,omitempty"`
+	Exchanges        []map[string]any `json:"exchanges,omitempty"`
+	Bindings         []map[string]any `json:"bindings,omitempty"`
+}
+
+// DefinitionsFilter selects the parts of the definitions to export or import
+type DefinitionsFilter struct {
+	// Vhosts limits vhost scoped objects to these vhosts; empty means all
+	Vhosts             []string
+	ExcludeUsers       bool
+	ExcludePermissions bool
+}
+
+// GetDefinitions exports the definitions of the whole broker
+func (c *Client) GetDefinitions() (*Definitions, error) {
+	respBody, err := c.makeRequest("GET", path("definitions"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs Definitions
+	if err := json.Unmarshal(respBody, &defs); err != nil {
+		return nil, err
+	}
+
+	return &defs, nil
+}
+
+// ImportDefinitions imports definitions. Existing objects are created or
+// updated, objects missing from the definitions are left untouched.
+func (c *Client) ImportDefinitions(defs *Definitions) error {
+	_, err := c.makeRequest("POST", path("definitions"), defs)
+	return err
+}
+
+// GetVhostDefinitions exports the definitions of a single vhost. It does not
+// need the administrator tag, but leaves out users, vhosts and permissions.
+func (c *Client) GetVhostDefinitions(vhost string) (*Definitions, error) {
+	respBody, err := c.makeRequest("GET", path("definitions", vhost), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs Definitions
+	if err := json.Unmarshal(respBody, &defs); err != nil {
+		return nil, err
+	}
+
+	return &defs, nil
+}
+
+// ImportVhostDefinitions imports definitions into a single vhost. The vhost
+// of each object in the definitions is ignored.
+func (c *Client) ImportVhostDefinitions(vhost string, defs *Definitions) error {
+	_, err := c.makeRequest("POST", path("definitions", vhost), defs)
+	return err
+}
+
+// VhostNames returns the names of the vhosts in the definitions
+func (d *Definitions) VhostNames() []string {
+	var names []string
+	for _, v := range d.Vhosts {
+		if name, ok := v["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Filter returns a copy of the definitions with only the selected objects
+func (d *Definitions) Filter(filter DefinitionsFilter) *Definitions {
+	inVhost := func(obj map[string]any, key string) bool {
+		if len(filter.Vhosts) == 0 {
+			return true
+		}
+		vhost, _ := obj[key].(string)
+		for _, v := range filter.Vhosts {
+			if v == vhost {
+				return true
+			}
+		}
+		return false
+	}
+	keep := func(objs []map[string]any, key string) []map[string]any {
+		var kept []map[string]any
+		for _, obj := range objs {
+			if inVhost(obj, key) {
+				kept = append(kept, obj)
+			}
+		}
+		return kept
+	}
+
+	filtered := *d
+	filtered.Vhosts = keep(d.Vhosts, "name")
+	filtered.Permissions = keep(d.Permissions, "vhost")
+	filtered.TopicPermissions = keep(d.TopicPermissions, "vhost")
+	filtered.Parameters = keep(d.Parameters, "vhost")
+	filtered.Policies = keep(d.Policies, "vhost")
+	filtered.Queues = keep(d.Queues, "vhost")
+	filtered.Exchanges = keep(d.Exchanges, "vhost")
+	filtered.Bindings = keep(d.Bindings, "vhost")
+	if filter.ExcludeUsers {
+		filtered.Users = nil
+	}
+	if filter.ExcludePermissions {
+		filtered.Permissions = nil
+		filtered.TopicPermissions = nil
+	}
+	return &filtered
+}
+
+// ScrubPasswordHashes removes the password hashes of all users
+func (d *Definitions) ScrubPasswordHashes() {
+	users := make([]map[string]any, len(d.Users))
+	for i, user := range d.Users {
+		users[i] = make(map[string]any, len(user))
+		for k, v := range user {
+			if k != "password_hash" {
+				users[i][k] = v
+			}
+		}
+	}
+	d.Users = users
+}
+
+// UsersWithoutPasswordHash returns the names of users that have no password
+// hash, e.g. because they were exported with scrubbing
+func (d *Definitions) UsersWithoutPasswordHash() []string {
+	var names []string
+	for _, user := range d.Users {
+		if hash, _ := user["password_hash"].(string); hash == "" {
+			name, _ := user["name"].(string)
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// WithoutUsers returns a copy of the definitions without the named users and
+// their permissions, which an import rejects for users that do not exist
+func (d *Definitions) WithoutUsers(names []string) *Definitions {
+	skip := make(map[string]bool, len(names))
+	for _, name := range names {
+		skip[name] = true
+	}
+	drop := func(objs []map[string]any, key string) []map[string]any {
+		var kept []map[string]any
+		for _, obj := range objs {
+			if name, _ := obj[key].(string); !skip[name] {
+				kept = append(kept, obj)
+			}
+		}
+		return kept
+	}
+
+	filtered := *d
+	filtered.Users = drop(d.Users, "name")
+	filtered.Permissions = drop(d.Permissions, "user")
+	filtered.TopicPermissions = drop(d.TopicPermissions, "user")
+	return &filtered
+}
+
+// Change actions of a DefinitionChange
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+)
+
+// DefinitionChange is an object that an import would create or update
+type DefinitionChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+}
+
+func (c DefinitionChange) String() string {
+	sign := "+"
+	if c.Action == ChangeUpdate {
+		sign = "~"
+	}
+	return fmt.Sprintf("%s %s %s", sign, c.Kind, c.Name)
+}
+
+// definitionKinds lists the object kinds in import order with the fields that
+// identify an object of the kind
+var definitionKinds = []struct {
+	kind    string
+	objects func(*Definitions) []map[string]any
+	key     []string
+}{
+	{"vhost", func(d *Definitions) []map[string]any { return d.Vhosts }, []string{"name"}},
+	{"user", func(d *Definitions) []map[string]any { return d.Users }, []string{"name"}},
+	{"permission", func(d *Definitions) []map[string]any { return d.Permissions }, []string{"vhost", "user"}},
+	{"topic_permission", func(d *Definitions) []map[string]any { return d.TopicPermissions }, []string{"vhost", "user", "exchange"}},
+	{"global_parameter", func(d *Definitions) []map[string]any { return d.GlobalParameters }, []string{"name"}},
+	{"parameter", func(d *Definitions) []map[string]any { return d.Parameters }, []string{"vhost", "component", "name"}},
+	{"policy", func(d *Definitions) []map[string]any { return d.Policies }, []string{"vhost", "name"}},
+	{"exchange", func(d *Definitions) []map[string]any { return d.Exchanges }, []string{"vhost", "name"}},
+	{"queue", func(d *Definitions) []map[string]any { return d.Queues }, []string{"vhost", "name"}},
+	{"binding", func(d *Definitions) []map[string]any { return d.Bindings }, []string{"vhost", "source", "destination_type", "destination", "routing_key", "arguments"}},
+}
+
+// DiffDefinitions returns the changes importing desired into a broker with the
+// current definitions would make. Imports never delete, so objects only in
+// current are not reported.
+func DiffDefinitions(current, desired *Definitions) []DefinitionChange {
+	var changes []DefinitionChange
+	for _, k := range definitionKinds {
+		existing := make(map[string]map[string]any)
+		for _, obj := range k.objects(current) {
+			existing[objectKey(obj, k.key)] = obj
+		}
+
+		var kindChanges []DefinitionChange
+		for _, obj := range k.objects(desired) {
+			key := objectKey(obj, k.key)
+			old, ok := existing[key]
+			switch {
+			case !ok:
+				kindChanges = append(kindChanges, DefinitionChange{Action: ChangeCreate, Kind: k.kind, Name: key})
+			case !objectsEqual(old, obj):
+				kindChanges = append(kindChanges, DefinitionChange{Action: ChangeUpdate, Kind: k.kind, Name: key})
+			}
+		}
+		sort.SliceStable(kindChanges, func(i, j int) bool { return kindChanges[i].Name < kindChanges[j].Name })
+		changes = append(changes, kindChanges...)
+	}
+	return changes
+}
+
+// objectKey joins the identifying fields of an object, e.g. "/ orders" for a
+// queue named orders in vhost /
+func objectKey(obj map[string]any, fields []string) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := obj[field]
+		switch v := value.(type) {
+		case string:
+			parts = append(parts, v)
+		case nil:
+		default:
+			data, _ := json.Marshal(v)
+			if string(data) != "{}" {
+				parts = append(parts, string(data))
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// objectsEqual compares two objects after a JSON round trip so that numbers
+// and nested values compare equal regardless of their Go types. A missing
+// password hash in desired does not count as a difference.
+func objectsEqual(current, desired map[string]any) bool {
+	normalize := func(obj map[string]any) map[string]any {
+		data, _ := json.Marshal(obj)
+		var normalized map[string]any
+		json.Unmarshal(data, &normalized)
+		return normalized
+	}
+	a, b := normalize(current), normalize(desired)
+	if _, ok := b["password_hash"]; !ok {
+		delete(a, "password_hash")
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,134 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDefinitions = `{
+  "rabbit_version": "3.13.7",
+  "users": [{"name": "app", "password_hash": "secret-hash", "hashing_algorithm": "rabbit_password_hashing_sha256", "tags": []}],
+  "vhosts": [{"name": "/"}, {"name": "orders"}],
+  "permissions": [{"user": "app", "vhost": "orders", "configure": ".*", "write": ".*", "read": ".*"}],
+  "policies": [{"vhost": "orders", "name": "ttl", "pattern": ".*", "apply-to": "queues", "definition": {"message-ttl": 60000}, "priority": 0}],
+  "queues": [{"name": "orders.eu", "vhost": "orders", "durable": true, "auto_delete": false, "arguments": {}},
+             {"name": "jobs", "vhost": "/", "durable": true, "auto_delete": false, "arguments": {}}],
+  "exchanges": [{"name": "orders", "vhost": "orders", "type": "topic", "durable": true, "auto_delete": false, "internal": false, "arguments": {}}],
+  "bindings": [{"source": "orders", "vhost": "orders", "destination": "orders.eu", "destination_type": "queue", "routing_key": "eu.#", "arguments": {}}]
+}`
+
+func parseTestDefinitions(t *testing.T) *Definitions {
+	var defs Definitions
+	assert.NoError(t, json.Unmarshal([]byte(testDefinitions), &defs))
+	return &defs
+}
+
+func TestGetAndImportDefinitions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/definitions", r.URL.Path)
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(testDefinitions))
+		case "POST":
+			var defs Definitions
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&defs))
+			assert.Len(t, defs.Queues, 2)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	defs, err := c.GetDefinitions()
+	assert.NoError(t, err)
+	assert.Equal(t, "3.13.7", defs.RabbitVersion)
+	assert.Len(t, defs.Users, 1)
+
+	assert.NoError(t, c.ImportDefinitions(defs))
+}
+
+func TestDefinitionsFilter(t *testing.T) {
+	defs := parseTestDefinitions(t)
+
+	filtered := defs.Filter(DefinitionsFilter{Vhosts: []string{"orders"}, ExcludeUsers: true})
+	assert.Empty(t, filtered.Users)
+	assert.Len(t, filtered.Vhosts, 1)
+	assert.Len(t, filtered.Permissions, 1)
+	assert.Len(t, filtered.Queues, 1)
+	assert.Equal(t, "orders.eu", filtered.Queues[0]["name"])
+
+	filtered = defs.Filter(DefinitionsFilter{ExcludePermissions: true})
+	assert.Len(t, filtered.Users, 1)
+	assert.Empty(t, filtered.Permissions)
+	assert.Len(t, filtered.Queues, 2)
+
+	// The original is not modified
+	assert.Len(t, defs.Permissions, 1)
+}
+
+func TestScrubPasswordHashes(t *testing.T) {
+	defs := parseTestDefinitions(t)
+	original := defs.Users[0]
+
+	defs.ScrubPasswordHashes()
+	assert.NotContains(t, defs.Users[0], "password_hash")
+	assert.Equal(t, "app", defs.Users[0]["name"])
+	assert.Contains(t, original, "password_hash")
+	assert.Equal(t, []string{"app"}, defs.UsersWithoutPasswordHash())
+	assert.Empty(t, defs.WithoutUsers([]string{"app"}).Users)
+}
+
+func TestWithoutUsers(t *testing.T) {
+	defs := parseTestDefinitions(t)
+	defs.Users = append(defs.Users, map[string]any{"name": "ops", "password_hash": "other-hash"})
+	defs.Permissions = append(defs.Permissions, map[string]any{"user": "ops", "vhost": "/", "configure": "", "write": "", "read": ".*"})
+	defs.TopicPermissions = []map[string]any{
+		{"user": "app", "vhost": "orders", "exchange": "orders", "write": ".*", "read": ".*"},
+		{"user": "ops", "vhost": "/", "exchange": "amq.topic", "write": "", "read": ".*"},
+	}
+
+	filtered := defs.WithoutUsers([]string{"app"})
+	assert.Len(t, filtered.Users, 1)
+	assert.Equal(t, "ops", filtered.Users[0]["name"])
+	assert.Len(t, filtered.Permissions, 1)
+	assert.Equal(t, "ops", filtered.Permissions[0]["user"])
+	assert.Len(t, filtered.TopicPermissions, 1)
+	assert.Equal(t, "ops", filtered.TopicPermissions[0]["user"])
+
+	// The original is not modified
+	assert.Len(t, defs.Permissions, 2)
+	assert.Len(t, defs.TopicPermissions, 2)
+}
+
+func TestDiffDefinitions(t *testing.T) {
+	current := parseTestDefinitions(t)
+	desired := parseTestDefinitions(t)
+
+	assert.Empty(t, DiffDefinitions(current, desired))
+
+	desired.Policies[0]["definition"] = map[string]any{"message-ttl": 30000}
+	desired.Queues = append(desired.Queues, map[string]any{"name": "orders.us", "vhost": "orders", "durable": true})
+	desired.Bindings = append(desired.Bindings, map[string]any{
+		"source": "orders", "vhost": "orders", "destination": "orders.us",
+		"destination_type": "queue", "routing_key": "us.#", "arguments": map[string]any{},
+	})
+	desired.ScrubPasswordHashes()
+	current.Queues = current.Queues[1:]
+
+	changes := DiffDefinitions(current, desired)
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.String())
+	}
+	assert.Equal(t, []string{
+		"~ policy orders ttl",
+		"+ queue orders orders.eu",
+		"+ queue orders orders.us",
+		"+ binding orders orders queue orders.us us.#",
+	}, lines)
+}
@@ -0,0 +1,72 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+)
+
+type Exchange struct {
+	Name         string         `json:"name"`
+	Vhost        string         `json:"vhost"`
+	Type         string         `json:"type"`
+	Durable      bool           `json:"durable"`
+	AutoDelete   bool           `json:"auto_delete"`
+	Internal     bool           `json:"internal"`
+	Arguments    map[string]any `json:"arguments"`
+	MessageStats MessageStats   `json:"message_stats"`
+}
+
+// ExchangeSettings are the properties of an exchange to declare
+type ExchangeSettings struct {
+	Type       string         `json:"type"`
+	Durable    bool           `json:"durable"`
+	AutoDelete bool           `json:"auto_delete"`
+	Internal   bool           `json:"internal"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+}
+
+// ListExchanges lists the exchanges in vhost, or in all vhosts if vhost is empty
+func (c *Client) ListExchanges(vhost string) ([]Exchange, error) {
+	endpoint := path("exchanges")
+	if vhost != "" {
+		endpoint = path("exchanges", vhost)
+	}
+
+	respBody, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchanges []Exchange
+	if err := json.Unmarshal(respBody, &exchanges); err != nil {
+		return nil, err
+	}
+
+	return exchanges, nil
+}
+
+// DeclareExchange creates an exchange. Declaring an existing exchange with
+// different settings fails.
+func (c *Client) DeclareExchange(vhost, name string, settings ExchangeSettings) error {
+	_, err := c.makeRequest("PUT", path("exchanges", vhost, name), settings)
+	return err
+}
+
+// DeleteExchange deletes an exchange. With ifUnused set the broker refuses to
+// delete an exchange that has bindings.
+func (c *Client) DeleteExchange(vhost, name string, ifUnused bool) error {
+	endpoint := path("exchanges", vhost, name)
+	if ifUnused {
+		endpoint += "?if-unused=true"
+	}
+	_, err := c.makeRequest("DELETE", endpoint, nil)
+	return err
+}
+
+// exchangePathName returns the name used for an exchange in API paths, where
+// the default exchange is called amq.default
+func exchangePathName(name string) string {
+	if name == "" {
+		return "amq.default"
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/exchanges/app", r.URL.EscapedPath())
+		w.Write([]byte(`[{"name": "orders", "vhost": "app", "type": "topic", "durable": true}]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	exchanges, err := c.ListExchanges("app")
+	assert.NoError(t, err)
+	assert.Len(t, exchanges, 1)
+	assert.Equal(t, "topic", exchanges[0].Type)
+	assert.True(t, exchanges[0].Durable)
+}
+
+func TestDeclareExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/api/exchanges/%2F/orders", r.URL.EscapedPath())
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "topic", body["type"])
+		assert.Equal(t, true, body["durable"])
+		assert.Equal(t, map[string]any{"alternate-exchange": "unrouted"}, body["arguments"])
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	err := c.DeclareExchange("/", "orders", ExchangeSettings{
+		Type:      "topic",
+		Durable:   true,
+		Arguments: map[string]any{"alternate-exchange": "unrouted"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestDeleteExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/exchanges/%2F/orders", r.URL.EscapedPath())
+		assert.Equal(t, "true", r.URL.Query().Get("if-unused"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	assert.NoError(t, c.DeleteExchange("/", "orders", true))
+}
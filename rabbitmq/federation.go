@@ -0,0 +1,17 @@
+package rabbitmq
+
+// FederationUpstream is the value of a federation upstream parameter
+type FederationUpstream struct {
+	URI      string `json:"uri"`
+	AckMode  string `json:"ack-mode,omitempty"`
+	Exchange string `json:"exchange,omitempty"`
+	Queue    string `json:"queue,omitempty"`
+	MaxHops  int    `json:"max-hops,omitempty"`
+	Expires  int    `json:"expires,omitempty"`
+}
+
+// SetFederationUpstream creates or replaces a federation upstream. It
+// requires the rabbitmq_federation plugin.
+func (c *Client) SetFederationUpstream(vhost, name string, upstream FederationUpstream) error {
+	return c.SetParameter("federation-upstream", vhost, name, upstream)
+}
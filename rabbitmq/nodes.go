@@ -0,0 +1,51 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// NodeStats are the runtime metrics of a cluster node
+type NodeStats struct {
+	Name          string `json:"name"`
+	Running       bool   `json:"running"`
+	Uptime        int64  `json:"uptime"`
+	MemUsed       int64  `json:"mem_used"`
+	MemLimit      int64  `json:"mem_limit"`
+	MemAlarm      bool   `json:"mem_alarm"`
+	DiskFree      int64  `json:"disk_free"`
+	DiskFreeLimit int64  `json:"disk_free_limit"`
+	DiskFreeAlarm bool   `json:"disk_free_alarm"`
+	FDUsed        int    `json:"fd_used"`
+	FDTotal       int    `json:"fd_total"`
+	SocketsUsed   int    `json:"sockets_used"`
+	SocketsTotal  int    `json:"sockets_total"`
+	ProcUsed      int    `json:"proc_used"`
+	ProcTotal     int    `json:"proc_total"`
+	Processors    int    `json:"processors"`
+	RunQueue      int    `json:"run_queue"`
+}
+
+// Hostname returns the host part of the node name, e.g. host for rabbit@host
+func (n NodeStats) Hostname() string {
+	if i := strings.Index(n.Name, "@"); i >= 0 {
+		return n.Name[i+1:]
+	}
+	return n.Name
+}
+
+// ListNodes lists the runtime metrics of all cluster nodes. It requires the
+// monitoring or administrator tag.
+func (c *Client) ListNodes() ([]NodeStats, error) {
+	respBody, err := c.makeRequest("GET", path("nodes"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []NodeStats
+	if err := json.Unmarshal(respBody, &nodes); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,44 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+)
+
+type ObjectTotals struct {
+	Connections int `json:"connections"`
+	Channels    int `json:"channels"`
+	Exchanges   int `json:"exchanges"`
+	Queues      int `json:"queues"`
+	Consumers   int `json:"consumers"`
+}
+
+type QueueTotals struct {
+	Messages               int `json:"messages"`
+	MessagesReady          int `json:"messages_ready"`
+	MessagesUnacknowledged int `json:"messages_unacknowledged"`
+}
+
+// Overview is the cluster wide summary returned by /api/overview
+type Overview struct {
+	ManagementVersion string       `json:"management_version"`
+	RabbitMQVersion   string       `json:"rabbitmq_version"`
+	ErlangVersion     string       `json:"erlang_version"`
+	ClusterName       string       `json:"cluster_name"`
+	ObjectTotals      ObjectTotals `json:"object_totals"`
+	QueueTotals       QueueTotals  `json:"queue_totals"`
+	MessageStats      MessageStats `json:"message_stats"`
+}
+
+func (c *Client) GetOverview() (*Overview, error) {
+	respBody, err := c.makeRequest("GET", path("overview"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var overview Overview
+	if err := json.Unmarshal(respBody, &overview); err != nil {
+		return nil, err
+	}
+
+	return &overview, nil
+}
@@ -0,0 +1,48 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+)
+
+// Parameter is a runtime parameter of a plugin component, such as a dynamic
+// shovel or a federation upstream
+type Parameter struct {
+	Name      string          `json:"name"`
+	Vhost     string          `json:"vhost"`
+	Component string          `json:"component"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// ListParameters lists the parameters of component in vhost, or in all vhosts
+// if vhost is empty
+func (c *Client) ListParameters(component, vhost string) ([]Parameter, error) {
+	endpoint := path("parameters", component)
+	if vhost != "" {
+		endpoint = path("parameters", component, vhost)
+	}
+
+	respBody, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var parameters []Parameter
+	if err := json.Unmarshal(respBody, &parameters); err != nil {
+		return nil, err
+	}
+
+	return parameters, nil
+}
+
+// SetParameter creates or replaces a parameter of component
+func (c *Client) SetParameter(component, vhost, name string, value any) error {
+	body := map[string]any{"value": value}
+	_, err := c.makeRequest("PUT", path("parameters", component, vhost, name), body)
+	return err
+}
+
+// DeleteParameter deletes a parameter of component
+func (c *Client) DeleteParameter(component, vhost, name string) error {
+	_, err := c.makeRequest("DELETE", path("parameters", component, vhost, name), nil)
+	return err
+}
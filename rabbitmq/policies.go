@@ -0,0 +1,221 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Policy kinds, the management API path of each kind of policy
+const (
+	PolicyKindPolicy   = "policies"
+	PolicyKindOperator = "operator-policies"
+)
+
+// PolicyApplyTo are the apply-to values of policies. classic_queues,
+// quorum_queues and streams require RabbitMQ 3.12 or later.
+var PolicyApplyTo = []string{"queues", "exchanges", "all", "classic_queues", "quorum_queues", "streams"}
+
+// OperatorPolicyApplyTo are the apply-to values of operator policies, which
+// only apply to queues
+var OperatorPolicyApplyTo = []string{"queues", "classic_queues", "quorum_queues", "streams"}
+
+// ApplyToValues returns the apply-to values of policies of kind
+func ApplyToValues(kind string) []string {
+	if kind == PolicyKindOperator {
+		return OperatorPolicyApplyTo
+	}
+	return PolicyApplyTo
+}
+
+type Policy struct {
+	Vhost      string         `json:"vhost"`
+	Name       string         `json:"name"`
+	Pattern    string         `json:"pattern"`
+	ApplyTo    string         `json:"apply-to"`
+	Priority   int            `json:"priority"`
+	Definition map[string]any `json:"definition"`
+}
+
+// ListPolicies lists the policies of kind in vhost, or in all vhosts if vhost
+// is empty
+func (c *Client) ListPolicies(kind, vhost string) ([]Policy, error) {
+	endpoint := path(kind)
+	if vhost != "" {
+		endpoint = path(kind, vhost)
+	}
+
+	respBody, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []Policy
+	if err := json.Unmarshal(respBody, &policies); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// SetPolicy creates or replaces a policy of kind
+func (c *Client) SetPolicy(kind string, policy Policy) error {
+	body := map[string]any{
+		"pattern":    policy.Pattern,
+		"apply-to":   policy.ApplyTo,
+		"priority":   policy.Priority,
+		"definition": policy.Definition,
+	}
+	_, err := c.makeRequest("PUT", path(kind, policy.Vhost, policy.Name), body)
+	return err
+}
+
+// DeletePolicy deletes a policy of kind
+func (c *Client) DeletePolicy(kind, vhost, name string) error {
+	_, err := c.makeRequest("DELETE", path(kind, vhost, name), nil)
+	return err
+}
+
+// queueOnlyPolicyKeys are definition keys that have no effect on exchanges
+var queueOnlyPolicyKeys = map[string]bool{
+	"ha-mode": true, "ha-params": true, "ha-sync-mode": true,
+	"max-length": true, "max-length-bytes": true, "message-ttl": true, "expires": true,
+	"dead-letter-exchange": true, "dead-letter-routing-key": true,
+	"delivery-limit": true, "overflow": true,
+}
+
+// operatorPolicyKeys are the definition keys RabbitMQ accepts in operator policies
+var operatorPolicyKeys = map[string]bool{
+	"expires": true, "message-ttl": true, "max-length": true, "max-length-bytes": true,
+	"max-in-memory-length": true, "max-in-memory-bytes": true,
+	"delivery-limit": true, "queue-initial-cluster-size": true,
+}
+
+// ValidatePolicy checks the apply-to value and the known keys of the
+// definition of a policy of kind. Unknown keys of regular policies are left to
+// the broker, since plugins add their own.
+func ValidatePolicy(kind string, policy Policy) error {
+	var problems []string
+
+	applyTo := ApplyToValues(kind)
+	validApplyTo := false
+	for _, a := range applyTo {
+		if policy.ApplyTo == a {
+			validApplyTo = true
+		}
+	}
+	if !validApplyTo {
+		problems = append(problems, fmt.Sprintf("apply-to must be one of: %s", strings.Join(applyTo, ", ")))
+	}
+
+	if len(policy.Definition) == 0 {
+		problems = append(problems, "definition must not be empty")
+	}
+
+	keys := make([]string, 0, len(policy.Definition))
+	for key := range policy.Definition {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := policy.Definition[key]
+
+		if kind == PolicyKindOperator && !operatorPolicyKeys[key] {
+			problems = append(problems, fmt.Sprintf("%s is not supported in operator policies", key))
+			continue
+		}
+		if policy.ApplyTo == "exchanges" && queueOnlyPolicyKeys[key] {
+			problems = append(problems, fmt.Sprintf("%s only applies to queues, but apply-to is exchanges", key))
+			continue
+		}
+
+		switch key {
+		case "max-length", "max-length-bytes", "message-ttl", "delivery-limit",
+			"max-in-memory-length", "max-in-memory-bytes":
+			if !isNonNegativeInteger(value) {
+				problems = append(problems, fmt.Sprintf("%s must be a non-negative integer", key))
+			}
+		case "expires", "queue-initial-cluster-size":
+			if !isNonNegativeInteger(value) || value.(float64) == 0 {
+				problems = append(problems, fmt.Sprintf("%s must be a positive integer", key))
+			}
+		case "dead-letter-exchange", "dead-letter-routing-key":
+			if _, ok := value.(string); !ok {
+				problems = append(problems, fmt.Sprintf("%s must be a string", key))
+			}
+		case "overflow":
+			if !oneOf(value, "drop-head", "reject-publish", "reject-publish-dlx") {
+				problems = append(problems, "overflow must be one of: drop-head, reject-publish, reject-publish-dlx")
+			}
+		case "ha-sync-mode":
+			if !oneOf(value, "manual", "automatic") {
+				problems = append(problems, "ha-sync-mode must be one of: manual, automatic")
+			}
+		case "ha-mode":
+			problems = append(problems, validateHAMode(value, policy.Definition["ha-params"])...)
+		case "ha-params":
+			if _, ok := policy.Definition["ha-mode"]; !ok {
+				problems = append(problems, "ha-params requires ha-mode")
+			}
+		}
+	}
+
+	if _, ok := policy.Definition["dead-letter-routing-key"]; ok {
+		if _, ok := policy.Definition["dead-letter-exchange"]; !ok {
+			problems = append(problems, "dead-letter-routing-key requires dead-letter-exchange")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid policy: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validateHAMode(mode, params any) []string {
+	switch mode {
+	case "all":
+		if params != nil {
+			return []string{"ha-params must not be set when ha-mode is all"}
+		}
+	case "exactly":
+		if !isNonNegativeInteger(params) || params.(float64) < 1 {
+			return []string{"ha-mode exactly requires ha-params to be a positive integer"}
+		}
+	case "nodes":
+		nodes, ok := params.([]any)
+		if !ok || len(nodes) == 0 {
+			return []string{"ha-mode nodes requires ha-params to be a list of node names"}
+		}
+		for _, node := range nodes {
+			if _, ok := node.(string); !ok {
+				return []string{"ha-mode nodes requires ha-params to be a list of node names"}
+			}
+		}
+	default:
+		return []string{"ha-mode must be one of: all, exactly, nodes"}
+	}
+	return nil
+}
+
+// isNonNegativeInteger reports whether a JSON decoded value is a whole number >= 0
+func isNonNegativeInteger(value any) bool {
+	n, ok := value.(float64)
+	return ok && n >= 0 && n == math.Trunc(n)
+}
+
+func oneOf(value any, allowed ...string) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, a := range allowed {
+		if s == a {
+			return true
+		}
+	}
+	return false
+}
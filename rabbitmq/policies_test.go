@@ -0,0 +1,97 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPolicies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/operator-policies/%2F", r.URL.EscapedPath())
+		w.Write([]byte(`[{"vhost": "/", "name": "limits", "pattern": ".*", "apply-to": "queues",
+			"priority": 2, "definition": {"max-length": 1000}}]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	policies, err := c.ListPolicies(PolicyKindOperator, "/")
+	assert.NoError(t, err)
+	assert.Len(t, policies, 1)
+	assert.Equal(t, "queues", policies[0].ApplyTo)
+	assert.Equal(t, 2, policies[0].Priority)
+	assert.Equal(t, float64(1000), policies[0].Definition["max-length"])
+}
+
+func TestSetPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/api/policies/app/ttl", r.URL.EscapedPath())
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "^orders", body["pattern"])
+		assert.Equal(t, "queues", body["apply-to"])
+		assert.Equal(t, float64(1), body["priority"])
+		assert.Equal(t, map[string]any{"message-ttl": float64(60000)}, body["definition"])
+		assert.NotContains(t, body, "vhost")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+	err := c.SetPolicy(PolicyKindPolicy, Policy{
+		Vhost: "app", Name: "ttl", Pattern: "^orders", ApplyTo: "queues", Priority: 1,
+		Definition: map[string]any{"message-ttl": float64(60000)},
+	})
+	assert.NoError(t, err)
+}
+
+func TestValidatePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		kind       string
+		applyTo    string
+		definition string
+		wantErr    string
+	}{
+		{"valid ttl and length", PolicyKindPolicy, "queues", `{"message-ttl": 60000, "max-length": 10}`, ""},
+		{"valid dlx", PolicyKindPolicy, "quorum_queues", `{"dead-letter-exchange": "dlx", "dead-letter-routing-key": "dead", "delivery-limit": 5}`, ""},
+		{"valid ha exactly", PolicyKindPolicy, "queues", `{"ha-mode": "exactly", "ha-params": 2, "ha-sync-mode": "automatic"}`, ""},
+		{"unknown key passed through", PolicyKindPolicy, "exchanges", `{"federation-upstream-set": "all"}`, ""},
+		{"negative ttl", PolicyKindPolicy, "queues", `{"message-ttl": -1}`, "message-ttl must be a non-negative integer"},
+		{"fractional length", PolicyKindPolicy, "queues", `{"max-length": 1.5}`, "max-length must be a non-negative integer"},
+		{"string limit", PolicyKindPolicy, "queues", `{"delivery-limit": "5"}`, "delivery-limit must be a non-negative integer"},
+		{"dlx not string", PolicyKindPolicy, "queues", `{"dead-letter-exchange": 1}`, "dead-letter-exchange must be a string"},
+		{"routing key without dlx", PolicyKindPolicy, "queues", `{"dead-letter-routing-key": "dead"}`, "requires dead-letter-exchange"},
+		{"bad ha mode", PolicyKindPolicy, "queues", `{"ha-mode": "some"}`, "ha-mode must be one of"},
+		{"ha exactly without params", PolicyKindPolicy, "queues", `{"ha-mode": "exactly"}`, "requires ha-params"},
+		{"ha nodes list", PolicyKindPolicy, "queues", `{"ha-mode": "nodes", "ha-params": [1]}`, "list of node names"},
+		{"queue key on exchanges", PolicyKindPolicy, "exchanges", `{"max-length": 10}`, "only applies to queues"},
+		{"bad apply-to", PolicyKindPolicy, "topics", `{"max-length": 10}`, "apply-to must be one of"},
+		{"empty definition", PolicyKindPolicy, "queues", `{}`, "definition must not be empty"},
+		{"operator limits", PolicyKindOperator, "queues", `{"max-length": 1000, "delivery-limit": 20}`, ""},
+		{"operator on quorum queues", PolicyKindOperator, "quorum_queues", `{"delivery-limit": 20}`, ""},
+		{"operator on exchanges", PolicyKindOperator, "exchanges", `{"max-length": 1000}`, "apply-to must be one of: queues, classic_queues"},
+		{"operator on all", PolicyKindOperator, "all", `{"max-length": 1000}`, "apply-to must be one of"},
+		{"operator ha", PolicyKindOperator, "queues", `{"ha-mode": "all"}`, "ha-mode is not supported in operator policies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var definition map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(tt.definition), &definition))
+
+			err := ValidatePolicy(tt.kind, Policy{Pattern: ".*", ApplyTo: tt.applyTo, Definition: definition})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// Rate is a message rate in messages per second
+type Rate struct {
+	Rate float64 `json:"rate"`
+}
+
+type MessageStats struct {
+	Publish           int  `json:"publish"`
+	PublishDetails    Rate `json:"publish_details"`
+	DeliverGet        int  `json:"deliver_get"`
+	DeliverGetDetails Rate `json:"deliver_get_details"`
+	Ack               int  `json:"ack"`
+	AckDetails        Rate `json:"ack_details"`
+	PublishIn         int  `json:"publish_in"`
+	PublishInDetails  Rate `json:"publish_in_details"`
+	PublishOut        int  `json:"publish_out"`
+	PublishOutDetails Rate `json:"publish_out_details"`
+}
+
+type Queue struct {
+	Name                   string         `json:"name"`
+	Vhost                  string         `json:"vhost"`
+	Type                   string         `json:"type"`
+	Durable                bool           `json:"durable"`
+	AutoDelete             bool           `json:"auto_delete"`
+	Exclusive              bool           `json:"exclusive"`
+	Arguments              map[string]any `json:"arguments"`
+	Node                   string         `json:"node"`
+	State                  string         `json:"state"`
+	Messages               int            `json:"messages"`
+	MessagesReady          int            `json:"messages_ready"`
+	MessagesUnacknowledged int            `json:"messages_unacknowledged"`
+	Consumers              int            `json:"consumers"`
+	Memory                 int64          `json:"memory"`
+	MessageStats           MessageStats   `json:"message_stats"`
+	SlaveNodes             []string       `json:"slave_nodes"`
+	SynchronisedSlaveNodes []string       `json:"synchronised_slave_nodes"`
+	Members                []string       `json:"members"`
+	Online                 []string       `json:"online"`
+}
+
+// unavailableQueueStates are queue states in which the queue cannot serve
+// clients
+var unavailableQueueStates = map[string]bool{
+	"down":     true,
+	"crashed":  true,
+	"stopped":  true,
+	"minority": true,
+}
+
+// Synced reports whether the queue is available with all its replicas in
+// sync: every mirror of a classic mirrored queue is synchronised and every
+// member of a quorum queue or stream is online. Queues without replicas only
+// need to be available.
+func (q Queue) Synced() bool {
+	if unavailableQueueStates[q.State] {
+		return false
+	}
+
+	synchronised := make(map[string]bool, len(q.SynchronisedSlaveNodes))
+	for _, node := range q.SynchronisedSlaveNodes {
+		synchronised[node] = true
+	}
+	for _, node := range q.SlaveNodes {
+		if !synchronised[node] {
+			return false
+		}
+	}
+
+	return len(q.Online) >= len(q.Members)
+}
+
+// ListQueues lists the queues in vhost, or in all vhosts if vhost is empty
+func (c *Client) ListQueues(vhost string) ([]Queue, error) {
+	endpoint := path("queues")
+	if vhost != "" {
+		endpoint = path("queues", vhost)
+	}
+
+	respBody, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var queues []Queue
+	if err := json.Unmarshal(respBody, &queues); err != nil {
+		return nil, err
+	}
+
+	return queues, nil
+}
+
+func (c *Client) GetQueue(vhost, name string) (*Queue, error) {
+	respBody, err := c.makeRequest("GET", path("queues", vhost, name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue Queue
+	if err := json.Unmarshal(respBody, &queue); err != nil {
+		return nil, err
+	}
+
+	return &queue, nil
+}
+
+// PurgeQueue removes all ready messages from a queue
+func (c *Client) PurgeQueue(vhost, name string) error {
+	_, err := c.makeRequest("DELETE", path("queues", vhost, name, "contents"), nil)
+	return err
+}
+
+// DeleteQueue deletes a queue. With ifEmpty or ifUnused set the broker refuses
+// to delete a queue that has messages or consumers.
+func (c *Client) DeleteQueue(vhost, name string, ifEmpty, ifUnused bool) error {
+	endpoint := path("queues", vhost, name)
+
+	params := url.Values{}
+	if ifEmpty {
+		params.Set("if-empty", "true")
+	}
+	if ifUnused {
+		params.Set("if-unused", "true")
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	_, err := c.makeRequest("DELETE", endpoint, nil)
+	return err
+}
@@ -0,0 +1,107 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListQueues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/queues", r.URL.Path)
+
+		w.Write([]byte(`[
+			{"name": "orders", "vhost": "/", "type": "quorum", "messages": 12, "consumers": 2,
+			 "message_stats": {"publish_details": {"rate": 4.5}}},
+			{"name": "events", "vhost": "app", "type": "classic", "messages": 0, "consumers": 0}
+		]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	queues, err := c.ListQueues("")
+	assert.NoError(t, err)
+	assert.Len(t, queues, 2)
+	assert.Equal(t, "orders", queues[0].Name)
+	assert.Equal(t, 12, queues[0].Messages)
+	assert.Equal(t, 4.5, queues[0].MessageStats.PublishDetails.Rate)
+	assert.Equal(t, "app", queues[1].Vhost)
+}
+
+func TestListQueues_Vhost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/queues/%2F", r.URL.EscapedPath())
+		json.NewEncoder(w).Encode([]Queue{})
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	queues, err := c.ListQueues("/")
+	assert.NoError(t, err)
+	assert.Empty(t, queues)
+}
+
+func TestGetQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/queues/app/orders%2Fv2", r.URL.EscapedPath())
+		json.NewEncoder(w).Encode(Queue{Name: "orders/v2", Vhost: "app", Durable: true})
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	queue, err := c.GetQueue("app", "orders/v2")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders/v2", queue.Name)
+	assert.True(t, queue.Durable)
+}
+
+func TestPurgeQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/queues/app/orders/contents", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	assert.NoError(t, c.PurgeQueue("app", "orders"))
+}
+
+func TestDeleteQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/queues/app/orders", r.URL.EscapedPath())
+		assert.Equal(t, "true", r.URL.Query().Get("if-empty"))
+		assert.Equal(t, "", r.URL.Query().Get("if-unused"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	assert.NoError(t, c.DeleteQueue("app", "orders", true, false))
+}
+
+func TestQueueSynced(t *testing.T) {
+	assert.True(t, Queue{Name: "plain", State: "running"}.Synced())
+	assert.False(t, Queue{Name: "down", State: "down"}.Synced())
+
+	mirrored := Queue{State: "running", SlaveNodes: []string{"rabbit@b", "rabbit@c"}, SynchronisedSlaveNodes: []string{"rabbit@c"}}
+	assert.False(t, mirrored.Synced())
+	mirrored.SynchronisedSlaveNodes = append(mirrored.SynchronisedSlaveNodes, "rabbit@b")
+	assert.True(t, mirrored.Synced())
+
+	quorum := Queue{State: "running", Members: []string{"rabbit@a", "rabbit@b", "rabbit@c"}, Online: []string{"rabbit@a", "rabbit@c"}}
+	assert.False(t, quorum.Synced())
+	quorum.Online = quorum.Members
+	assert.True(t, quorum.Synced())
+}
@@ -0,0 +1,107 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+)
+
+// Shovel acknowledgement modes
+const (
+	ShovelAckOnConfirm = "on-confirm"
+	ShovelAckOnPublish = "on-publish"
+	ShovelAckNoAck     = "no-ack"
+)
+
+// Shovel src-delete-after values
+const (
+	ShovelDeleteNever       = "never"
+	ShovelDeleteQueueLength = "queue-length"
+)
+
+// ShovelDefinition is the value of a dynamic shovel parameter
+type ShovelDefinition struct {
+	SrcProtocol    string `json:"src-protocol,omitempty"`
+	SrcURI         string `json:"src-uri"`
+	SrcQueue       string `json:"src-queue,omitempty"`
+	SrcExchange    string `json:"src-exchange,omitempty"`
+	SrcDeleteAfter string `json:"src-delete-after,omitempty"`
+	DestProtocol   string `json:"dest-protocol,omitempty"`
+	DestURI        string `json:"dest-uri"`
+	DestQueue      string `json:"dest-queue,omitempty"`
+	DestExchange   string `json:"dest-exchange,omitempty"`
+	AckMode        string `json:"ack-mode,omitempty"`
+}
+
+// Shovel is a configured dynamic shovel
+type Shovel struct {
+	Name       string
+	Vhost      string
+	Definition ShovelDefinition
+}
+
+// ShovelStatus is the runtime status of a shovel. The transfer counters are
+// only reported by RabbitMQ 3.12 and later.
+type ShovelStatus struct {
+	Name             string `json:"name"`
+	Vhost            string `json:"vhost"`
+	Type             string `json:"type"`
+	State            string `json:"state"`
+	Node             string `json:"node"`
+	Timestamp        string `json:"timestamp"`
+	Reason           string `json:"reason,omitempty"`
+	SrcQueue         string `json:"src_queue,omitempty"`
+	DestQueue        string `json:"dest_queue,omitempty"`
+	Forwarded        *int   `json:"forwarded,omitempty"`
+	Remaining        *int   `json:"remaining,omitempty"`
+	RemainingUnacked *int   `json:"remaining_unacked,omitempty"`
+}
+
+// CreateShovel creates or replaces a dynamic shovel. It requires the
+// rabbitmq_shovel plugin.
+func (c *Client) CreateShovel(vhost, name string, definition ShovelDefinition) error {
+	return c.SetParameter("shovel", vhost, name, definition)
+}
+
+// DeleteShovel deletes a dynamic shovel
+func (c *Client) DeleteShovel(vhost, name string) error {
+	return c.DeleteParameter("shovel", vhost, name)
+}
+
+// ListShovels lists the configured dynamic shovels in vhost, or in all vhosts
+// if vhost is empty
+func (c *Client) ListShovels(vhost string) ([]Shovel, error) {
+	parameters, err := c.ListParameters("shovel", vhost)
+	if err != nil {
+		return nil, err
+	}
+
+	shovels := make([]Shovel, 0, len(parameters))
+	for _, p := range parameters {
+		shovel := Shovel{Name: p.Name, Vhost: p.Vhost}
+		if err := json.Unmarshal(p.Value, &shovel.Definition); err != nil {
+			return nil, err
+		}
+		shovels = append(shovels, shovel)
+	}
+	return shovels, nil
+}
+
+// ListShovelStatus lists the status of the shovels in vhost, or in all vhosts
+// if vhost is empty. It requires the rabbitmq_shovel_management plugin.
+func (c *Client) ListShovelStatus(vhost string) ([]ShovelStatus, error) {
+	endpoint := path("shovels")
+	if vhost != "" {
+		endpoint = path("shovels", vhost)
+	}
+
+	respBody, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ShovelStatus
+	if err := json.Unmarshal(respBody, &statuses); err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
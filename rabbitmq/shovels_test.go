@@ -0,0 +1,82 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateShovel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/api/parameters/shovel/app/move-orders", r.URL.EscapedPath())
+
+		var body struct {
+			Value map[string]string `json:"value"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, map[string]string{
+			"src-protocol":     "amqp091",
+			"src-uri":          "amqps://a:p@old/app",
+			"src-queue":        "orders",
+			"src-delete-after": "queue-length",
+			"dest-protocol":    "amqp091",
+			"dest-uri":         "amqps://b:p@new/app",
+			"dest-queue":       "orders",
+			"ack-mode":         "on-confirm",
+		}, body.Value)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+	err := c.CreateShovel("app", "move-orders", ShovelDefinition{
+		SrcProtocol: "amqp091", SrcURI: "amqps://a:p@old/app", SrcQueue: "orders",
+		SrcDeleteAfter: ShovelDeleteQueueLength,
+		DestProtocol:   "amqp091", DestURI: "amqps://b:p@new/app", DestQueue: "orders",
+		AckMode: ShovelAckOnConfirm,
+	})
+	assert.NoError(t, err)
+}
+
+func TestListShovels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/parameters/shovel", r.URL.EscapedPath())
+		w.Write([]byte(`[{"name": "move-orders", "vhost": "app", "component": "shovel",
+			"value": {"src-uri": "amqps://a:p@old/app", "src-queue": "orders", "dest-uri": "amqp://", "dest-queue": "orders"}}]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	shovels, err := c.ListShovels("")
+	assert.NoError(t, err)
+	assert.Len(t, shovels, 1)
+	assert.Equal(t, "move-orders", shovels[0].Name)
+	assert.Equal(t, "orders", shovels[0].Definition.SrcQueue)
+	assert.Equal(t, "amqp://", shovels[0].Definition.DestURI)
+}
+
+func TestListShovelStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/shovels/app", r.URL.EscapedPath())
+		w.Write([]byte(`[
+			{"name": "move-orders", "vhost": "app", "type": "dynamic", "state": "running", "forwarded": 120, "remaining": 30},
+			{"name": "old", "vhost": "app", "type": "dynamic", "state": "terminated", "reason": "needed a restart"}
+		]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	statuses, err := c.ListShovelStatus("app")
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, 120, *statuses[0].Forwarded)
+	assert.Equal(t, 30, *statuses[0].Remaining)
+	assert.Nil(t, statuses[1].Forwarded)
+	assert.Equal(t, "needed a restart", statuses[1].Reason)
+}
@@ -0,0 +1,122 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// UserTags are the tags of a user. Older RabbitMQ versions return them as a
+// comma-separated string, newer versions as a list.
+type UserTags []string
+
+func (t *UserTags) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*t = list
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = nil
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*t = append(*t, tag)
+		}
+	}
+	return nil
+}
+
+type User struct {
+	Name             string   `json:"name"`
+	Tags             UserTags `json:"tags"`
+	PasswordHash     string   `json:"password_hash,omitempty"`
+	HashingAlgorithm string   `json:"hashing_algorithm,omitempty"`
+}
+
+type Permission struct {
+	User      string `json:"user"`
+	Vhost     string `json:"vhost"`
+	Configure string `json:"configure"`
+	Write     string `json:"write"`
+	Read      string `json:"read"`
+}
+
+func (c *Client) ListUsers() ([]User, error) {
+	respBody, err := c.makeRequest("GET", path("users"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if err := json.Unmarshal(respBody, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (c *Client) GetUser(name string) (*User, error) {
+	respBody, err := c.makeRequest("GET", path("users", name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := json.Unmarshal(respBody, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// CreateUser creates a user with a password, or replaces the password and
+// tags of an existing user
+func (c *Client) CreateUser(name, password string, tags []string) error {
+	body := map[string]string{
+		"password": password,
+		"tags":     strings.Join(tags, ","),
+	}
+	_, err := c.makeRequest("PUT", path("users", name), body)
+	return err
+}
+
+// SetUserTags replaces the tags of a user and keeps its password
+func (c *Client) SetUserTags(name string, tags []string) error {
+	user, err := c.GetUser(name)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{
+		"password_hash":     user.PasswordHash,
+		"hashing_algorithm": user.HashingAlgorithm,
+		"tags":              strings.Join(tags, ","),
+	}
+	_, err = c.makeRequest("PUT", path("users", name), body)
+	return err
+}
+
+func (c *Client) DeleteUser(name string) error {
+	_, err := c.makeRequest("DELETE", path("users", name), nil)
+	return err
+}
+
+// SetPermissions sets the configure, write and read patterns of a user in a vhost
+func (c *Client) SetPermissions(vhost, user, configure, write, read string) error {
+	body := map[string]string{
+		"configure": configure,
+		"write":     write,
+		"read":      read,
+	}
+	_, err := c.makeRequest("PUT", path("permissions", vhost, user), body)
+	return err
+}
+
+// ClearPermissions removes all permissions of a user in a vhost
+func (c *Client) ClearPermissions(vhost, user string) error {
+	_, err := c.makeRequest("DELETE", path("permissions", vhost, user), nil)
+	return err
+}
@@ -0,0 +1,98 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListUsers_Tags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/users", r.URL.Path)
+		w.Write([]byte(`[
+			{"name": "admin", "tags": ["administrator", "monitoring"]},
+			{"name": "legacy", "tags": "management, policymaker"},
+			{"name": "app", "tags": ""}
+		]`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+
+	users, err := c.ListUsers()
+	assert.NoError(t, err)
+	assert.Len(t, users, 3)
+	assert.Equal(t, UserTags{"administrator", "monitoring"}, users[0].Tags)
+	assert.Equal(t, UserTags{"management", "policymaker"}, users[1].Tags)
+	assert.Empty(t, users[2].Tags)
+}
+
+func TestCreateUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/api/users/orders-app", r.URL.EscapedPath())
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, map[string]string{"password": "pw", "tags": "monitoring,management"}, body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+	assert.NoError(t, c.CreateUser("orders-app", "pw", []string{"monitoring", "management"}))
+}
+
+func TestSetUserTags_KeepsPassword(t *testing.T) {
+	var put map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/users/app", r.URL.EscapedPath())
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"name": "app", "tags": [], "password_hash": "abc123", "hashing_algorithm": "rabbit_password_hashing_sha256"}`))
+		case "PUT":
+			json.NewDecoder(r.Body).Decode(&put)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+	assert.NoError(t, c.SetUserTags("app", []string{"monitoring"}))
+	assert.Equal(t, "abc123", put["password_hash"])
+	assert.Equal(t, "rabbit_password_hashing_sha256", put["hashing_algorithm"])
+	assert.Equal(t, "monitoring", put["tags"])
+	_, hasPassword := put["password"]
+	assert.False(t, hasPassword)
+}
+
+func TestSetPermissions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/api/permissions/%2F/app", r.URL.EscapedPath())
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, map[string]string{"configure": "^app\\.", "write": ".*", "read": ""}, body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+	assert.NoError(t, c.SetPermissions("/", "app", "^app\\.", ".*", ""))
+}
+
+func TestDeleteVhost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/vhosts/orders%2Feu", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "user", "secret", "test")
+	assert.NoError(t, c.DeleteVhost("orders/eu"))
+}
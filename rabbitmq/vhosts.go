@@ -0,0 +1,45 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+)
+
+type Vhost struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	DefaultQueueType string `json:"default_queue_type"`
+	Tracing          bool   `json:"tracing"`
+	Messages         int    `json:"messages"`
+}
+
+// VhostSettings are the properties of a vhost to create
+type VhostSettings struct {
+	Description      string `json:"description,omitempty"`
+	DefaultQueueType string `json:"default_queue_type,omitempty"`
+}
+
+func (c *Client) ListVhosts() ([]Vhost, error) {
+	respBody, err := c.makeRequest("GET", path("vhosts"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vhosts []Vhost
+	if err := json.Unmarshal(respBody, &vhosts); err != nil {
+		return nil, err
+	}
+
+	return vhosts, nil
+}
+
+// CreateVhost creates a vhost, or updates the settings of an existing one
+func (c *Client) CreateVhost(name string, settings VhostSettings) error {
+	_, err := c.makeRequest("PUT", path("vhosts", name), settings)
+	return err
+}
+
+// DeleteVhost deletes a vhost with all its queues, exchanges and messages
+func (c *Client) DeleteVhost(name string) error {
+	_, err := c.makeRequest("DELETE", path("vhosts", name), nil)
+	return err
+}